@@ -0,0 +1,138 @@
+package grampsxml
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// ChangedObject identifies a primary object and the time it was last
+// changed, as returned by [ChangedSince].
+type ChangedObject struct {
+	// Type is the type of the object, named as in the target attribute of a
+	// bookmark: "person", "family", "event", "place", "source", "citation",
+	// "media", "repository", "note" or "tag", or with 1.8.0 support,
+	// "dnatest" or "dnamatch".
+	Type string
+
+	Handle string
+	ID     string // empty for tags
+	Change time.Time
+}
+
+// ChangedSince returns the primary objects in db that were changed after t,
+// ordered from the earliest change to the latest. Objects without a valid
+// change time are left out. Change times are recorded to the second, so an
+// object changed in the same second as t is not included.
+func ChangedSince(db *Database, t time.Time) []ChangedObject {
+	var changed []ChangedObject
+	walkObjects(db, func(o primaryObject) {
+		ct := parseChange(*o.change)
+		if ct.IsZero() || !ct.After(t) {
+			return
+		}
+		c := ChangedObject{Type: o.objType, Handle: o.handle, Change: ct}
+		if o.id != nil {
+			c.ID = strval(*o.id)
+		}
+		changed = append(changed, c)
+	})
+	slices.SortStableFunc(changed, func(a, b ChangedObject) int {
+		return cmp.Compare(a.Change.Unix(), b.Change.Unix())
+	})
+	return changed
+}
+
+// Time returns the date the export was created.
+func (c *Created) Time() (time.Time, error) {
+	t, err := time.Parse(time.DateOnly, c.Date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("grampsxml: invalid creation date %q", c.Date)
+	}
+	return t, nil
+}
+
+// SetTime sets the date the export was created.
+func (c *Created) SetTime(t time.Time) { c.Date = t.Format(time.DateOnly) }
+
+// ChangeTime returns the time the person was last changed, or the zero time
+// if it has no valid change time.
+func (p *Person) ChangeTime() time.Time { return parseChange(p.Change) }
+
+// SetChangeTime sets the time the person was last changed.
+func (p *Person) SetChangeTime(t time.Time) { p.Change = changeStamp(t) }
+
+// ChangeTime returns the time the family was last changed, or the zero time
+// if it has no valid change time.
+func (f *Family) ChangeTime() time.Time { return parseChange(f.Change) }
+
+// SetChangeTime sets the time the family was last changed.
+func (f *Family) SetChangeTime(t time.Time) { f.Change = changeStamp(t) }
+
+// ChangeTime returns the time the event was last changed, or the zero time
+// if it has no valid change time.
+func (e *Event) ChangeTime() time.Time { return parseChange(e.Change) }
+
+// SetChangeTime sets the time the event was last changed.
+func (e *Event) SetChangeTime(t time.Time) { e.Change = changeStamp(t) }
+
+// ChangeTime returns the time the place was last changed, or the zero time
+// if it has no valid change time.
+func (p *Placeobj) ChangeTime() time.Time { return parseChange(p.Change) }
+
+// SetChangeTime sets the time the place was last changed.
+func (p *Placeobj) SetChangeTime(t time.Time) { p.Change = changeStamp(t) }
+
+// ChangeTime returns the time the source was last changed, or the zero time
+// if it has no valid change time.
+func (s *Source) ChangeTime() time.Time { return parseChange(s.Change) }
+
+// SetChangeTime sets the time the source was last changed.
+func (s *Source) SetChangeTime(t time.Time) { s.Change = changeStamp(t) }
+
+// ChangeTime returns the time the citation was last changed, or the zero time
+// if it has no valid change time.
+func (c *Citation) ChangeTime() time.Time { return parseChange(c.Change) }
+
+// SetChangeTime sets the time the citation was last changed.
+func (c *Citation) SetChangeTime(t time.Time) { c.Change = changeStamp(t) }
+
+// ChangeTime returns the time the media object was last changed, or the zero time
+// if it has no valid change time.
+func (o *Object) ChangeTime() time.Time { return parseChange(o.Change) }
+
+// SetChangeTime sets the time the media object was last changed.
+func (o *Object) SetChangeTime(t time.Time) { o.Change = changeStamp(t) }
+
+// ChangeTime returns the time the repository was last changed, or the zero time
+// if it has no valid change time.
+func (r *Repository) ChangeTime() time.Time { return parseChange(r.Change) }
+
+// SetChangeTime sets the time the repository was last changed.
+func (r *Repository) SetChangeTime(t time.Time) { r.Change = changeStamp(t) }
+
+// ChangeTime returns the time the note was last changed, or the zero time
+// if it has no valid change time.
+func (n *Note) ChangeTime() time.Time { return parseChange(n.Change) }
+
+// SetChangeTime sets the time the note was last changed.
+func (n *Note) SetChangeTime(t time.Time) { n.Change = changeStamp(t) }
+
+// ChangeTime returns the time the tag was last changed, or the zero time
+// if it has no valid change time.
+func (g *Tag) ChangeTime() time.Time { return parseChange(g.Change) }
+
+// SetChangeTime sets the time the tag was last changed.
+func (g *Tag) SetChangeTime(t time.Time) { g.Change = changeStamp(t) }
+
+// parseChange parses the Unix timestamp held in a change attribute,
+// returning the zero time if s is empty or invalid.
+func parseChange(s string) time.Time {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(n, 0)
+}
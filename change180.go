@@ -0,0 +1,19 @@
+//go:build gramps_schema180
+
+package grampsxml
+
+import "time"
+
+// ChangeTime returns the time the DNA test was last changed, or the zero time
+// if it has no valid change time.
+func (d *DNATest) ChangeTime() time.Time { return parseChange(d.Change) }
+
+// SetChangeTime sets the time the DNA test was last changed.
+func (d *DNATest) SetChangeTime(t time.Time) { d.Change = changeStamp(t) }
+
+// ChangeTime returns the time the DNA match was last changed, or the zero time
+// if it has no valid change time.
+func (m *DNAMatch) ChangeTime() time.Time { return parseChange(m.Change) }
+
+// SetChangeTime sets the time the DNA match was last changed.
+func (m *DNAMatch) SetChangeTime(t time.Time) { m.Change = changeStamp(t) }
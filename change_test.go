@@ -0,0 +1,76 @@
+package grampsxml
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChangedSince(t *testing.T) {
+	db := &Database{
+		Tags: &Tags{Tag: []Tag{{Handle: "_t1", Name: "ToDo", Change: "1700000300"}}},
+		People: &People{
+			Person: []Person{
+				{Handle: "_i1", ID: new("I0001"), Change: "1700000200"},
+				{Handle: "_i2", ID: new("I0002"), Change: "1600000000"},
+				{Handle: "_i3", ID: new("I0003")},
+			},
+		},
+		Notes: &Notes{Note: []Note{{Handle: "_n1", ID: new("N0001"), Change: "1700000100"}}},
+	}
+
+	got := ChangedSince(db, time.Unix(1700000000, 0))
+	want := []ChangedObject{
+		{Type: "note", Handle: "_n1", ID: "N0001", Change: time.Unix(1700000100, 0)},
+		{Type: "person", Handle: "_i1", ID: "I0001", Change: time.Unix(1700000200, 0)},
+		{Type: "tag", Handle: "_t1", Change: time.Unix(1700000300, 0)},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := ChangedSince(db, time.Unix(1700000200, 0)); len(got) != 1 || got[0].Handle != "_t1" {
+		t.Errorf("expected only the tag to be changed after the person, got %v", got)
+	}
+}
+
+func TestChangeTime(t *testing.T) {
+	var p Person
+	if !p.ChangeTime().IsZero() {
+		t.Errorf("expected zero time for missing change")
+	}
+	when := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	p.SetChangeTime(when)
+	if p.Change != "1709296200" {
+		t.Errorf("got change %q", p.Change)
+	}
+	if got := p.ChangeTime(); !got.Equal(when) {
+		t.Errorf("got change time %v, want %v", got, when)
+	}
+	p.Change = "yesterday"
+	if !p.ChangeTime().IsZero() {
+		t.Errorf("expected zero time for invalid change")
+	}
+}
+
+func TestCreatedTime(t *testing.T) {
+	c := Created{Date: "2024-03-01", Version: "5.2.0"}
+	got, err := c.Time()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	c.SetTime(time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC))
+	if c.Date != "2025-12-31" {
+		t.Errorf("got date %q", c.Date)
+	}
+
+	c.Date = "1 March 2024"
+	if _, err := c.Time(); err == nil {
+		t.Errorf("expected error for invalid date")
+	}
+}
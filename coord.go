@@ -0,0 +1,236 @@
+package grampsxml
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CoordFormat names one of the formats Gramps offers for displaying
+// latitudes and longitudes.
+type CoordFormat string
+
+// Coordinate formats, using the names given to them by Gramps. The examples
+// show the latitude 50.8726 and longitude -4.3.
+const (
+	CoordD4     CoordFormat = "D.D4"    // 50.8726, -4.3000
+	CoordD8     CoordFormat = "D.D8"    // 50.87260000, -4.30000000
+	CoordDEG    CoordFormat = "DEG"     // 50°52'21.36"N, 4°18'00.00"W
+	CoordDEGSep CoordFormat = "DEG-:"   // 50:52:21.36:N, 4:18:00.00:W
+	CoordISOD   CoordFormat = "ISO-D"   // +50.8726, -004.3000
+	CoordISODM  CoordFormat = "ISO-DM"  // +5052.3560, -00418.0000
+	CoordISODMS CoordFormat = "ISO-DMS" // +505221.36, -0041800.00
+	CoordGEDCOM CoordFormat = "GEDCOM"  // N50.8726, W4.3
+)
+
+// ParseLatitude parses a latitude in any of the forms accepted by Gramps and
+// returns it in decimal degrees, positive to the north. Accepted forms
+// include signed decimal degrees such as "50.8726" or "-4.3", with either a
+// point or a comma as the decimal separator, and degrees with optional
+// minutes and seconds separated by the symbols °, ' and " or by colons or
+// spaces, such as "50°52'21.92\"" and "50:52:21.92". The hemisphere may be
+// given by a leading or trailing N or S in place of the sign, as in
+// "N 50°52'21.92\"" or "50:52:21.92N". The ISO 6709 forms written by
+// [CoordISODM] and [CoordISODMS], such as "+5052.3560" and "+505221.36", are
+// recognised by their sign and the number of digits before the decimal
+// point.
+func ParseLatitude(s string) (float64, error) {
+	v, err := parseCoordValue(s, 'N', 'S', 90, 2)
+	if err != nil {
+		return 0, fmt.Errorf("grampsxml: invalid latitude %q: %w", s, err)
+	}
+	return v, nil
+}
+
+// ParseLongitude parses a longitude in the forms accepted by
+// [ParseLatitude], with E and W in place of N and S. It returns the
+// longitude in decimal degrees, positive to the east.
+func ParseLongitude(s string) (float64, error) {
+	v, err := parseCoordValue(s, 'E', 'W', 180, 3)
+	if err != nil {
+		return 0, fmt.Errorf("grampsxml: invalid longitude %q: %w", s, err)
+	}
+	return v, nil
+}
+
+// Degrees returns the latitude and longitude of c in decimal degrees.
+func (c *Coord) Degrees() (lat, long float64, err error) {
+	if lat, err = ParseLatitude(c.Lat); err != nil {
+		return 0, 0, err
+	}
+	if long, err = ParseLongitude(c.Long); err != nil {
+		return 0, 0, err
+	}
+	return lat, long, nil
+}
+
+// NewCoord returns a Coord holding the given latitude and longitude, in
+// decimal degrees, written in format f.
+func NewCoord(lat, long float64, f CoordFormat) Coord {
+	return Coord{Lat: FormatLatitude(lat, f), Long: FormatLongitude(long, f)}
+}
+
+// FormatLatitude formats a latitude in decimal degrees using format f. The
+// result of formatting an unknown format is the same as for [CoordD4].
+func FormatLatitude(v float64, f CoordFormat) string {
+	return formatCoordValue(v, f, 'N', 'S', 2)
+}
+
+// FormatLongitude formats a longitude in decimal degrees using format f. The
+// result of formatting an unknown format is the same as for [CoordD4].
+func FormatLongitude(v float64, f CoordFormat) string {
+	return formatCoordValue(v, f, 'E', 'W', 3)
+}
+
+// coordSeparators are the characters that may separate the degrees,
+// minutes and seconds of a coordinate.
+const coordSeparators = "°º'′\"″:"
+
+// parseCoordValue parses a latitude or longitude. Width is the number of
+// digits used for whole degrees in the ISO 6709 forms.
+func parseCoordValue(s string, pos, neg rune, limit float64, width int) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty value")
+	}
+	s = strings.ReplaceAll(s, ",", ".")
+
+	// A hemisphere letter may appear at either end.
+	sign := 0.0
+	hemisphere := func(r rune) error {
+		switch unicode.ToUpper(r) {
+		case pos:
+			sign = 1
+		case neg:
+			sign = -1
+		case 'N', 'S', 'E', 'W':
+			return fmt.Errorf("hemisphere %c is not %c or %c", r, pos, neg)
+		}
+		return nil
+	}
+	if first := []rune(s)[0]; unicode.IsLetter(first) {
+		if err := hemisphere(first); err != nil {
+			return 0, err
+		}
+		if sign != 0 {
+			s = strings.TrimSpace(string([]rune(s)[1:]))
+		}
+	}
+	if rs := []rune(s); sign == 0 && len(rs) > 0 && unicode.IsLetter(rs[len(rs)-1]) {
+		if err := hemisphere(rs[len(rs)-1]); err != nil {
+			return 0, err
+		}
+		if sign != 0 {
+			s = strings.TrimSpace(string(rs[:len(rs)-1]))
+		}
+	}
+
+	signed := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		if sign != 0 {
+			return 0, fmt.Errorf("both sign and hemisphere given")
+		}
+		sign = 1
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = s[1:]
+		signed = true
+	}
+	if sign == 0 {
+		sign = 1
+	}
+
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(coordSeparators, r)
+	})
+	if len(fields) == 0 || len(fields) > 3 {
+		return 0, fmt.Errorf("expected degrees with optional minutes and seconds")
+	}
+	if signed && len(fields) == 1 {
+		// ISO 6709 runs degrees, minutes and seconds together, so the
+		// number of digits before the decimal point gives the form.
+		f := fields[0]
+		switch n, _, _ := strings.Cut(f, "."); len(n) {
+		case width + 2:
+			fields = []string{f[:width], f[width:]}
+		case width + 4:
+			fields = []string{f[:width], f[width : width+2], f[width+2:]}
+		}
+	}
+	var v float64
+	for i, f := range fields {
+		n, err := strconv.ParseFloat(f, 64)
+		if err != nil || strings.Trim(f, "0123456789.") != "" {
+			return 0, fmt.Errorf("invalid number %q", f)
+		}
+		if i < len(fields)-1 && n != math.Trunc(n) {
+			return 0, fmt.Errorf("fractional value %q must be the last component", f)
+		}
+		if i > 0 && n >= 60 {
+			return 0, fmt.Errorf("minutes and seconds must be less than 60")
+		}
+		v += n / math.Pow(60, float64(i))
+	}
+	if v > limit {
+		return 0, fmt.Errorf("out of range")
+	}
+	return sign * v, nil
+}
+
+func formatCoordValue(v float64, f CoordFormat, pos, neg rune, width int) string {
+	hemi := pos
+	if v < 0 {
+		hemi = neg
+	}
+	abs := math.Abs(v)
+
+	switch f {
+	case CoordD8:
+		return strconv.FormatFloat(v, 'f', 8, 64)
+	case CoordDEG:
+		d, m, s := coordDMS(abs)
+		return fmt.Sprintf("%d°%02d'%05.2f\"%c", d, m, s, hemi)
+	case CoordDEGSep:
+		d, m, s := coordDMS(abs)
+		return fmt.Sprintf("%d:%02d:%05.2f:%c", d, m, s, hemi)
+	case CoordISOD:
+		return fmt.Sprintf("%c%0*.4f", coordSign(v), width+5, abs)
+	case CoordISODM:
+		d, m := coordDM(abs)
+		return fmt.Sprintf("%c%0*d%07.4f", coordSign(v), width, d, m)
+	case CoordISODMS:
+		d, m, s := coordDMS(abs)
+		return fmt.Sprintf("%c%0*d%02d%05.2f", coordSign(v), width, d, m, s)
+	case CoordGEDCOM:
+		return string(hemi) + strconv.FormatFloat(abs, 'f', -1, 64)
+	default:
+		return strconv.FormatFloat(v, 'f', 4, 64)
+	}
+}
+
+func coordSign(v float64) rune {
+	if v < 0 {
+		return '-'
+	}
+	return '+'
+}
+
+// coordDM splits a value in degrees into whole degrees and minutes, rounded
+// to four decimal places.
+func coordDM(v float64) (int, float64) {
+	total := math.Round(v*60*1e4) / 1e4
+	d := math.Floor(total / 60)
+	return int(d), total - d*60
+}
+
+// coordDMS splits a value in degrees into whole degrees, whole minutes and
+// seconds, rounded to two decimal places.
+func coordDMS(v float64) (int, int, float64) {
+	total := math.Round(v*3600*100) / 100
+	d := math.Floor(total / 3600)
+	m := math.Floor((total - d*3600) / 60)
+	return int(d), int(m), total - d*3600 - m*60
+}
@@ -0,0 +1,109 @@
+package grampsxml
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseLatitude(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  float64
+	}{
+		{"50.8726", 50.8726},
+		{"50,8726", 50.8726},
+		{"-4.3", -4.3},
+		{"+4.3", 4.3},
+		{"N 50°52'21.92\"", 50.872756},
+		{"50°52'21.92\"N", 50.872756},
+		{"50°52'21,92\"S", -50.872756},
+		{"50:52:21.92N", 50.872756},
+		{"50:52:21.92:N", 50.872756},
+		{"s 50 52.5", -50.875},
+		{"N50.8726", 50.8726},
+		{"90", 90},
+		{"+50.8726", 50.8726},
+		{"+0030.0000", 0.5},
+		{"-5052.3560", -50.872600},
+		{"+000036.00", 0.01},
+		{"+505221.92", 50.872756},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := ParseLatitude(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(got-tc.want) > 1e-6 {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseCoordErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		parse func(string) (float64, error)
+		input string
+	}{
+		{"empty", ParseLatitude, ""},
+		{"text", ParseLatitude, "Paris"},
+		{"out of range", ParseLatitude, "91"},
+		{"wrong hemisphere", ParseLatitude, "50.2E"},
+		{"sign and hemisphere", ParseLatitude, "-50.2S"},
+		{"minutes too large", ParseLatitude, "50:60:00"},
+		{"fractional degrees with minutes", ParseLatitude, "50.5:10"},
+		{"too many components", ParseLatitude, "50:10:10:10"},
+		{"longitude out of range", ParseLongitude, "W 180.5"},
+		{"longitude hemisphere", ParseLongitude, "4.3N"},
+		{"ISO minutes too large", ParseLatitude, "+5060.0000"},
+		{"ISO seconds too large", ParseLongitude, "-0041860.00"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.parse(tc.input); err == nil {
+				t.Errorf("got no error for %q", tc.input)
+			}
+		})
+	}
+}
+
+func TestFormatCoord(t *testing.T) {
+	testCases := []struct {
+		format   CoordFormat
+		wantLat  string
+		wantLong string
+	}{
+		{CoordD4, "50.8726", "-4.3000"},
+		{CoordD8, "50.87260000", "-4.30000000"},
+		{CoordDEG, "50°52'21.36\"N", "4°18'00.00\"W"},
+		{CoordDEGSep, "50:52:21.36:N", "4:18:00.00:W"},
+		{CoordISOD, "+50.8726", "-004.3000"},
+		{CoordISODM, "+5052.3560", "-00418.0000"},
+		{CoordISODMS, "+505221.36", "-0041800.00"},
+		{CoordGEDCOM, "N50.8726", "W4.3"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.format), func(t *testing.T) {
+			c := NewCoord(50.8726, -4.3, tc.format)
+			if c.Lat != tc.wantLat {
+				t.Errorf("got latitude %q, wanted %q", c.Lat, tc.wantLat)
+			}
+			if c.Long != tc.wantLong {
+				t.Errorf("got longitude %q, wanted %q", c.Long, tc.wantLong)
+			}
+
+			lat, long, err := c.Degrees()
+			if err != nil {
+				t.Fatalf("unexpected error parsing formatted coordinate: %v", err)
+			}
+			if math.Abs(lat-50.8726) > 1e-5 || math.Abs(long+4.3) > 1e-5 {
+				t.Errorf("parsed %v, %v, wanted 50.8726, -4.3", lat, long)
+			}
+		})
+	}
+}
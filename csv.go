@@ -0,0 +1,991 @@
+package grampsxml
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Column headings written by WriteCSV. They match the English headings of
+// Gramps' CSV export.
+var (
+	csvPlaceHeader    = []string{"Place", "Title", "Name", "Type", "Latitude", "Longitude", "Code", "Enclosed_by", "Date"}
+	csvPersonHeader   = []string{"Person", "Surname", "Given", "Call", "Suffix", "Prefix", "Title", "Gender", "Birth date", "Birth place", "Birth source", "Baptism date", "Baptism place", "Baptism source", "Death date", "Death place", "Death source", "Burial date", "Burial place", "Burial source", "Note"}
+	csvMarriageHeader = []string{"Marriage", "Husband", "Wife", "Date", "Place", "Source", "Note"}
+	csvFamilyHeader   = []string{"Family", "Child"}
+)
+
+// csvPersonEvents lists the event types given their own columns in the
+// people section, along with the column prefix used for each.
+var csvPersonEvents = []struct {
+	column    string
+	eventType string
+}{
+	{"birth", "Birth"},
+	{"baptism", "Baptism"},
+	{"death", "Death"},
+	{"burial", "Burial"},
+}
+
+// csvColumns maps normalized column headings, as accepted by Gramps' CSV
+// import, to the key used for the column internally.
+var csvColumns = map[string]string{
+	"person":          "person",
+	"grampsid":        "person",
+	"id":              "person",
+	"surname":         "surname",
+	"lastname":        "surname",
+	"given":           "given",
+	"givenname":       "given",
+	"givennames":      "given",
+	"firstname":       "given",
+	"call":            "call",
+	"callname":        "call",
+	"suffix":          "suffix",
+	"prefix":          "prefix",
+	"title":           "title",
+	"gender":          "gender",
+	"sex":             "gender",
+	"birthdate":       "birthdate",
+	"birthplace":      "birthplace",
+	"birthsource":     "birthsource",
+	"baptismdate":     "baptismdate",
+	"baptismplace":    "baptismplace",
+	"baptismsource":   "baptismsource",
+	"christeningdate": "baptismdate",
+	"deathdate":       "deathdate",
+	"deathplace":      "deathplace",
+	"deathsource":     "deathsource",
+	"burialdate":      "burialdate",
+	"burialplace":     "burialplace",
+	"burialsource":    "burialsource",
+	"note":            "note",
+	"notes":           "note",
+	"marriage":        "marriage",
+	"husband":         "husband",
+	"father":          "husband",
+	"parent1":         "husband",
+	"wife":            "wife",
+	"mother":          "wife",
+	"parent2":         "wife",
+	"date":            "date",
+	"place":           "place",
+	"source":          "source",
+	"family":          "family",
+	"child":           "child",
+	"name":            "name",
+	"type":            "type",
+	"latitude":        "latitude",
+	"lat":             "latitude",
+	"longitude":       "longitude",
+	"long":            "longitude",
+	"code":            "code",
+	"enclosedby":      "enclosedby",
+}
+
+func csvColumnKey(heading string) (string, bool) {
+	h := strings.ToLower(strings.TrimSpace(heading))
+	h = strings.NewReplacer(" ", "", "_", "", "-", "").Replace(h)
+	key, ok := csvColumns[h]
+	return key, ok
+}
+
+// WriteCSV writes db to w in the spreadsheet layout used by Gramps' CSV
+// export. The output has four sections separated by blank lines: places,
+// people with their birth, baptism, death and burial details, marriages and
+// the children of each family. Objects are referred to by their Gramps IDs
+// enclosed in square brackets.
+func WriteCSV(w io.Writer, db *Database) error {
+	ix := NewIndex(db)
+	cw := csv.NewWriter(w)
+
+	// write stops at the first error so that a failing writer does not
+	// cause every remaining row to be formatted.
+	write := func(record ...string) error {
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("grampsxml: write csv: %w", err)
+		}
+		return nil
+	}
+
+	if err := write(csvPlaceHeader...); err != nil {
+		return err
+	}
+	if db.Places != nil {
+		for i := range db.Places.Place {
+			p := &db.Places.Place[i]
+			var name, lat, long string
+			if len(p.Pname) > 0 {
+				name = p.Pname[0].Value
+			}
+			if p.Coord != nil {
+				lat, long = p.Coord.Lat, p.Coord.Long
+			}
+			row := []string{csvPlaceID(p), strval(p.Ptitle), name, p.Type, lat, long, strval(p.Code), "", ""}
+			if len(p.Placeref) == 0 {
+				if err := write(row...); err != nil {
+					return err
+				}
+				continue
+			}
+			for _, pr := range p.Placeref {
+				row[7], row[8] = "", pr.Date().String()
+				if enc := ix.Place(pr.Hlink); enc != nil {
+					row[7] = csvPlaceID(enc)
+				}
+				if err := write(row...); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := write(); err != nil {
+		return err
+	}
+
+	if err := write(csvPersonHeader...); err != nil {
+		return err
+	}
+	if db.People != nil {
+		for i := range db.People.Person {
+			p := &db.People.Person[i]
+			var surname, given, call, suffix, prefix, title string
+			if len(p.Name) > 0 {
+				n := &p.Name[0]
+				given, call, suffix, title = strval(n.First), strval(n.Call), strval(n.Suffix), strval(n.Title)
+				if s := primarySurname(n); s != nil {
+					surname, prefix = s.Surname, strval(s.Prefix)
+				}
+			}
+			row := []string{csvRef(p.ID, p.Handle), surname, given, call, suffix, prefix, title, csvGender(p.Gender)}
+			for _, pe := range csvPersonEvents {
+				ev := personEvent(ix, p, pe.eventType)
+				row = append(row, csvEventColumns(ix, ev)...)
+			}
+			row = append(row, csvNote(ix, p.Noteref))
+			if err := write(row...); err != nil {
+				return err
+			}
+		}
+	}
+	if err := write(); err != nil {
+		return err
+	}
+
+	if err := write(csvMarriageHeader...); err != nil {
+		return err
+	}
+	if db.Families != nil {
+		for i := range db.Families.Family {
+			f := &db.Families.Family[i]
+			row := []string{csvRef(f.ID, f.Handle), "", ""}
+			if f.Father != nil {
+				row[1] = csvPersonRef(ix, f.Father.Hlink)
+			}
+			if f.Mother != nil {
+				row[2] = csvPersonRef(ix, f.Mother.Hlink)
+			}
+			row = append(row, csvEventColumns(ix, familyEvent(ix, f, "Marriage"))...)
+			row = append(row, csvNote(ix, f.Noteref))
+			if err := write(row...); err != nil {
+				return err
+			}
+		}
+	}
+	if err := write(); err != nil {
+		return err
+	}
+
+	if err := write(csvFamilyHeader...); err != nil {
+		return err
+	}
+	if db.Families != nil {
+		for i := range db.Families.Family {
+			f := &db.Families.Family[i]
+			for _, cr := range f.Childref {
+				if err := write(csvRef(f.ID, f.Handle), csvPersonRef(ix, cr.Hlink)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("grampsxml: write csv: %w", err)
+	}
+	return nil
+}
+
+func csvRef(id *string, handle string) string {
+	if id != nil && *id != "" {
+		return "[" + *id + "]"
+	}
+	return handle
+}
+
+func csvPlaceID(p *Placeobj) string {
+	return csvRef(p.ID, p.Handle)
+}
+
+func csvPersonRef(ix *Index, handle string) string {
+	if p := ix.Person(handle); p != nil {
+		return csvRef(p.ID, p.Handle)
+	}
+	return ""
+}
+
+func csvGender(g string) string {
+	switch g {
+	case "M":
+		return "male"
+	case "F":
+		return "female"
+	default:
+		return "unknown"
+	}
+}
+
+// csvEventColumns returns the date, place and source columns for ev.
+func csvEventColumns(ix *Index, ev *Event) []string {
+	if ev == nil {
+		return []string{"", "", ""}
+	}
+	var place, source string
+	if ev.Place != nil {
+		if p := ix.Place(ev.Place.Hlink); p != nil {
+			place = csvPlaceID(p)
+		}
+	}
+	for _, cr := range ev.Citationref {
+		c := ix.Citation(cr.Hlink)
+		if c == nil || c.Sourceref == nil {
+			continue
+		}
+		if s := ix.Source(c.Sourceref.Hlink); s != nil {
+			source = strval(s.Stitle)
+			break
+		}
+	}
+	return []string{ev.Date().String(), place, source}
+}
+
+func csvNote(ix *Index, refs []Noteref) string {
+	for _, nr := range refs {
+		if n := ix.Note(nr.Hlink); n != nil {
+			return n.Text
+		}
+	}
+	return ""
+}
+
+// personEvent returns the first event of the given type in which p has the
+// primary role.
+func personEvent(ix *Index, p *Person, eventType string) *Event {
+	for _, er := range p.Eventref {
+		if !isRole(er.Role, "Primary") {
+			continue
+		}
+		if ev := ix.Event(er.Hlink); ev != nil && strval(ev.Type) == eventType {
+			return ev
+		}
+	}
+	return nil
+}
+
+// familyEvent returns the first event of the given type in which f has the
+// family role.
+func familyEvent(ix *Index, f *Family, eventType string) *Event {
+	for _, er := range f.Eventref {
+		if !isRole(er.Role, "Family") {
+			continue
+		}
+		if ev := ix.Event(er.Hlink); ev != nil && strval(ev.Type) == eventType {
+			return ev
+		}
+	}
+	return nil
+}
+
+// isRole reports whether role is want. A missing role is treated as the
+// default role for the reference, which is Primary for people and Family
+// for families.
+func isRole(role *string, want string) bool {
+	return role == nil || *role == "" || *role == want
+}
+
+// primarySurname returns the primary surname of n, which is the first one
+// not explicitly marked as secondary.
+func primarySurname(n *Name) *Surname {
+	for i := range n.Surname {
+		if n.Surname[i].Prim == nil || *n.Surname[i].Prim {
+			return &n.Surname[i]
+		}
+	}
+	if len(n.Surname) > 0 {
+		return &n.Surname[0]
+	}
+	return nil
+}
+
+func strval(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// ReadCSV reads data in Gramps' CSV layout from r and merges it into db.
+//
+// As in Gramps, the input consists of sections each starting with a row of
+// column headings: a places section, a people section, a marriages section
+// and a families section listing children. Headings may appear in any order
+// and are matched case insensitively, ignoring spaces and underscores.
+//
+// Objects are identified by the value in the first column. A Gramps ID in
+// square brackets, such as [I0001], refers to the object with that ID in db,
+// which is updated in place or created if it does not exist. Any other value
+// is a key local to the input that can be used to refer to the object from
+// later rows. Non-empty cells overwrite existing values; empty cells leave
+// them unchanged. Places referred to by name are matched against the titles
+// and names of existing places, and sources against existing source titles.
+// Objects created or modified are stamped with the current time.
+func ReadCSV(r io.Reader, db *Database) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	imp := newCSVImporter(db, time.Now())
+
+	var section string
+	var columns []string
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("grampsxml: read csv: %w", err)
+		}
+		if csvBlank(record) {
+			continue
+		}
+		if s, cols, ok := csvSectionHeader(record); ok {
+			section, columns = s, cols
+			continue
+		}
+		if section == "" {
+			line, _ := cr.FieldPos(0)
+			return fmt.Errorf("grampsxml: read csv: line %d: data before column headings", line)
+		}
+
+		row := make(map[string]string, len(columns))
+		for i, col := range columns {
+			if col != "" && i < len(record) {
+				row[col] = strings.TrimSpace(record[i])
+			}
+		}
+
+		switch section {
+		case "place":
+			imp.placeRow(row)
+		case "person":
+			imp.personRow(row)
+		case "marriage":
+			imp.marriageRow(row)
+		case "family":
+			imp.familyRow(row)
+		}
+	}
+	return nil
+}
+
+func csvBlank(record []string) bool {
+	for _, f := range record {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// csvSectionHeader reports whether record is a row of column headings and, if
+// so, which section it starts and the key of each column.
+func csvSectionHeader(record []string) (string, []string, bool) {
+	cols := make([]string, len(record))
+	has := make(map[string]bool)
+	for i, f := range record {
+		if strings.TrimSpace(f) == "" {
+			continue
+		}
+		key, ok := csvColumnKey(f)
+		if !ok {
+			return "", nil, false
+		}
+		cols[i] = key
+		has[key] = true
+	}
+
+	switch {
+	case has["marriage"] || has["husband"] || has["wife"]:
+		return "marriage", cols, true
+	case has["family"] && has["child"]:
+		return "family", cols, true
+	case has["person"] || has["surname"] || has["given"]:
+		return "person", cols, true
+	case cols[0] == "place":
+		return "place", cols, true
+	}
+	return "", nil, false
+}
+
+// csvImporter tracks the positions of objects in the database being merged
+// into. Positions are used rather than pointers since the slices grow as
+// objects are created.
+type csvImporter struct {
+	db     *Database
+	change string
+
+	people    map[string]int // by handle
+	families  map[string]int
+	events    map[string]int
+	places    map[string]int
+	sources   map[string]int
+	citations map[string]int
+	notes     map[string]int
+
+	personIDs map[string]string // Gramps ID to handle
+	familyIDs map[string]string
+	placeIDs  map[string]string
+
+	personKeys map[string]string // key local to the input to handle
+	familyKeys map[string]string
+	placeKeys  map[string]string
+
+	ids map[string]*idSequence // by ID prefix
+}
+
+func newCSVImporter(db *Database, now time.Time) *csvImporter {
+	imp := &csvImporter{
+		db:         db,
+		change:     changeStamp(now),
+		people:     make(map[string]int),
+		families:   make(map[string]int),
+		events:     make(map[string]int),
+		places:     make(map[string]int),
+		sources:    make(map[string]int),
+		citations:  make(map[string]int),
+		notes:      make(map[string]int),
+		personIDs:  make(map[string]string),
+		familyIDs:  make(map[string]string),
+		placeIDs:   make(map[string]string),
+		personKeys: make(map[string]string),
+		familyKeys: make(map[string]string),
+		placeKeys:  make(map[string]string),
+		ids:        make(map[string]*idSequence),
+	}
+	for _, prefix := range []string{"I", "F", "E", "P", "S", "C", "N"} {
+		imp.ids[prefix] = newIDSequence(prefix + "%04d")
+	}
+
+	if db.People == nil {
+		db.People = &People{}
+	}
+	if db.Families == nil {
+		db.Families = &Families{}
+	}
+	if db.Events == nil {
+		db.Events = &Events{}
+	}
+	if db.Places == nil {
+		db.Places = &Places{}
+	}
+	if db.Sources == nil {
+		db.Sources = &Sources{}
+	}
+	if db.Citations == nil {
+		db.Citations = &Citations{}
+	}
+	if db.Notes == nil {
+		db.Notes = &Notes{}
+	}
+
+	for i, o := range db.People.Person {
+		imp.people[o.Handle] = i
+		imp.reserveID("I", o.ID, o.Handle, imp.personIDs)
+	}
+	for i, o := range db.Families.Family {
+		imp.families[o.Handle] = i
+		imp.reserveID("F", o.ID, o.Handle, imp.familyIDs)
+	}
+	for i, o := range db.Events.Event {
+		imp.events[o.Handle] = i
+		imp.reserveID("E", o.ID, o.Handle, nil)
+	}
+	for i, o := range db.Places.Place {
+		imp.places[o.Handle] = i
+		imp.reserveID("P", o.ID, o.Handle, imp.placeIDs)
+	}
+	for i, o := range db.Sources.Source {
+		imp.sources[o.Handle] = i
+		imp.reserveID("S", o.ID, o.Handle, nil)
+	}
+	for i, o := range db.Citations.Citation {
+		imp.citations[o.Handle] = i
+		imp.reserveID("C", o.ID, o.Handle, nil)
+	}
+	for i, o := range db.Notes.Note {
+		imp.notes[o.Handle] = i
+		imp.reserveID("N", o.ID, o.Handle, nil)
+	}
+	return imp
+}
+
+func (imp *csvImporter) reserveID(prefix string, id *string, handle string, byID map[string]string) {
+	if id == nil {
+		return
+	}
+	imp.ids[prefix].reserve(*id)
+	if byID != nil {
+		byID[*id] = handle
+	}
+}
+
+func (imp *csvImporter) person(handle string) *Person {
+	return &imp.db.People.Person[imp.people[handle]]
+}
+
+func (imp *csvImporter) family(handle string) *Family {
+	return &imp.db.Families.Family[imp.families[handle]]
+}
+
+func (imp *csvImporter) event(handle string) *Event {
+	return &imp.db.Events.Event[imp.events[handle]]
+}
+
+func (imp *csvImporter) place(handle string) *Placeobj {
+	return &imp.db.Places.Place[imp.places[handle]]
+}
+
+// csvBracketedID returns the Gramps ID held in a cell of the form [ID].
+func csvBracketedID(s string) (string, bool) {
+	if len(s) > 2 && s[0] == '[' && s[len(s)-1] == ']' {
+		return s[1 : len(s)-1], true
+	}
+	return "", false
+}
+
+// resolve returns the handle of the object referred to by ref, a bracketed
+// Gramps ID or a key local to the input, creating the object with create if
+// it is not yet known. An empty ref always creates a new object.
+func (imp *csvImporter) resolve(ref string, byID, byKey map[string]string, create func(id string) string) string {
+	if id, ok := csvBracketedID(ref); ok {
+		if h, ok := byID[id]; ok {
+			return h
+		}
+		h := create(id)
+		byID[id] = h
+		return h
+	}
+	if h, ok := byKey[ref]; ok && ref != "" {
+		return h
+	}
+	h := create("")
+	if ref != "" {
+		byKey[ref] = h
+	}
+	return h
+}
+
+func (imp *csvImporter) newID(prefix, id string) *string {
+	if id == "" {
+		id = imp.ids[prefix].allocate()
+	} else {
+		imp.ids[prefix].reserve(id)
+	}
+	return new(id)
+}
+
+func (imp *csvImporter) createPerson(id string) string {
+	p := Person{ID: imp.newID("I", id), Handle: newHandle(), Change: imp.change, Gender: "U"}
+	if id != "" {
+		imp.personIDs[id] = p.Handle
+	}
+	imp.people[p.Handle] = len(imp.db.People.Person)
+	imp.db.People.Person = append(imp.db.People.Person, p)
+	return p.Handle
+}
+
+func (imp *csvImporter) createFamily(id string) string {
+	f := Family{ID: imp.newID("F", id), Handle: newHandle(), Change: imp.change}
+	if id != "" {
+		imp.familyIDs[id] = f.Handle
+	}
+	imp.families[f.Handle] = len(imp.db.Families.Family)
+	imp.db.Families.Family = append(imp.db.Families.Family, f)
+	return f.Handle
+}
+
+func (imp *csvImporter) createPlace(id string) string {
+	p := Placeobj{ID: imp.newID("P", id), Handle: newHandle(), Change: imp.change, Type: "Unknown"}
+	if id != "" {
+		imp.placeIDs[id] = p.Handle
+	}
+	imp.places[p.Handle] = len(imp.db.Places.Place)
+	imp.db.Places.Place = append(imp.db.Places.Place, p)
+	return p.Handle
+}
+
+func (imp *csvImporter) createEvent(eventType string) string {
+	e := Event{ID: imp.newID("E", ""), Handle: newHandle(), Change: imp.change, Type: new(eventType)}
+	imp.events[e.Handle] = len(imp.db.Events.Event)
+	imp.db.Events.Event = append(imp.db.Events.Event, e)
+	return e.Handle
+}
+
+func (imp *csvImporter) resolvePerson(ref string) string {
+	return imp.resolve(ref, imp.personIDs, imp.personKeys, imp.createPerson)
+}
+
+func (imp *csvImporter) resolveFamily(ref string) string {
+	return imp.resolve(ref, imp.familyIDs, imp.familyKeys, imp.createFamily)
+}
+
+// resolvePlaceRef returns the handle of the place named in a person or
+// marriage row. Besides IDs and keys, places may be given by title or name.
+func (imp *csvImporter) resolvePlaceRef(ref string) string {
+	if _, ok := csvBracketedID(ref); ok {
+		return imp.resolvePlace(ref)
+	}
+	if h, ok := imp.placeKeys[ref]; ok {
+		return h
+	}
+	for _, p := range imp.db.Places.Place {
+		if strval(p.Ptitle) == ref || (len(p.Pname) > 0 && p.Pname[0].Value == ref) {
+			return p.Handle
+		}
+	}
+	h := imp.resolvePlace(ref)
+	p := imp.place(h)
+	p.Ptitle = new(ref)
+	p.Pname = []Pname{{Value: ref}}
+	return h
+}
+
+func (imp *csvImporter) resolvePlace(ref string) string {
+	return imp.resolve(ref, imp.placeIDs, imp.placeKeys, imp.createPlace)
+}
+
+func (imp *csvImporter) placeRow(row map[string]string) {
+	h := imp.resolvePlace(row["place"])
+	var enclosedBy string
+	if ref := row["enclosedby"]; ref != "" {
+		enclosedBy = imp.resolvePlace(ref)
+	}
+
+	p := imp.place(h)
+	p.Change = imp.change
+	if v := row["title"]; v != "" {
+		p.Ptitle = new(v)
+	}
+	if v := row["name"]; v != "" {
+		if len(p.Pname) == 0 {
+			p.Pname = []Pname{{Value: v}}
+		} else {
+			p.Pname[0].Value = v
+		}
+	}
+	if v := row["type"]; v != "" {
+		p.Type = v
+	}
+	if v := row["code"]; v != "" {
+		p.Code = new(v)
+	}
+	if lat, long := row["latitude"], row["longitude"]; lat != "" || long != "" {
+		if p.Coord == nil {
+			p.Coord = &Coord{}
+		}
+		if lat != "" {
+			p.Coord.Lat = lat
+		}
+		if long != "" {
+			p.Coord.Long = long
+		}
+	}
+	if enclosedBy != "" && enclosedBy != h {
+		date := ParseDate(row["date"])
+		for i := range p.Placeref {
+			pr := &p.Placeref[i]
+			if pr.Hlink != enclosedBy {
+				continue
+			}
+			if pr.Date().IsZero() {
+				pr.SetDate(date)
+				return
+			}
+			if date.IsZero() || pr.Date().String() == date.String() {
+				return
+			}
+		}
+		pr := Placeref{Hlink: enclosedBy}
+		pr.SetDate(date)
+		p.Placeref = append(p.Placeref, pr)
+	}
+}
+
+func (imp *csvImporter) personRow(row map[string]string) {
+	h := imp.resolvePerson(row["person"])
+	p := imp.person(h)
+	p.Change = imp.change
+
+	if row["surname"] != "" || row["given"] != "" || row["call"] != "" || row["suffix"] != "" || row["prefix"] != "" || row["title"] != "" {
+		if len(p.Name) == 0 {
+			p.Name = []Name{{Type: new("Birth Name")}}
+		}
+		n := &p.Name[0]
+		if row["surname"] != "" || row["prefix"] != "" {
+			s := primarySurname(n)
+			if s == nil {
+				n.Surname = append(n.Surname, Surname{})
+				s = &n.Surname[0]
+			}
+			if v := row["surname"]; v != "" {
+				s.Surname = v
+			}
+			if v := row["prefix"]; v != "" {
+				s.Prefix = new(v)
+			}
+		}
+		if v := row["given"]; v != "" {
+			n.First = new(v)
+		}
+		if v := row["call"]; v != "" {
+			n.Call = new(v)
+		}
+		if v := row["suffix"]; v != "" {
+			n.Suffix = new(v)
+		}
+		if v := row["title"]; v != "" {
+			n.Title = new(v)
+		}
+	}
+
+	switch strings.ToLower(row["gender"]) {
+	case "male", "m":
+		p.Gender = "M"
+	case "female", "f":
+		p.Gender = "F"
+	case "unknown", "u":
+		p.Gender = "U"
+	}
+
+	for _, pe := range csvPersonEvents {
+		date, place, source := row[pe.column+"date"], row[pe.column+"place"], row[pe.column+"source"]
+		if date == "" && place == "" && source == "" {
+			continue
+		}
+		eh := imp.personEvent(h, pe.eventType)
+		imp.updateEvent(eh, date, place, source)
+	}
+
+	if note := row["note"]; note != "" {
+		refs := imp.addNote(imp.person(h).Noteref, note, "Person Note")
+		imp.person(h).Noteref = refs
+	}
+}
+
+// personEvent returns the handle of the person's primary event of the given
+// type, creating the event if needed.
+func (imp *csvImporter) personEvent(person, eventType string) string {
+	for _, er := range imp.person(person).Eventref {
+		if i, ok := imp.events[er.Hlink]; ok && isRole(er.Role, "Primary") && strval(imp.db.Events.Event[i].Type) == eventType {
+			return er.Hlink
+		}
+	}
+	eh := imp.createEvent(eventType)
+	p := imp.person(person)
+	p.Eventref = append(p.Eventref, Eventref{Hlink: eh, Role: new("Primary")})
+	return eh
+}
+
+// familyEvent returns the handle of the family's event of the given type,
+// creating the event if needed.
+func (imp *csvImporter) familyEvent(family, eventType string) string {
+	for _, er := range imp.family(family).Eventref {
+		if i, ok := imp.events[er.Hlink]; ok && isRole(er.Role, "Family") && strval(imp.db.Events.Event[i].Type) == eventType {
+			return er.Hlink
+		}
+	}
+	eh := imp.createEvent(eventType)
+	f := imp.family(family)
+	f.Eventref = append(f.Eventref, Eventref{Hlink: eh, Role: new("Family")})
+	return eh
+}
+
+func (imp *csvImporter) updateEvent(eh, date, place, source string) {
+	var ph string
+	if place != "" {
+		ph = imp.resolvePlaceRef(place)
+	}
+	var refs []Citationref
+	if source != "" {
+		refs = imp.addCitation(imp.event(eh).Citationref, source)
+	}
+
+	ev := imp.event(eh)
+	ev.Change = imp.change
+	if date != "" {
+		ev.SetDate(ParseDate(date))
+	}
+	if ph != "" {
+		ev.Place = &Place{Hlink: ph}
+	}
+	if source != "" {
+		ev.Citationref = refs
+	}
+}
+
+// addCitation returns refs with a citation of the source titled title
+// appended, unless refs already cite that source. The source is created if
+// no source has that title.
+func (imp *csvImporter) addCitation(refs []Citationref, title string) []Citationref {
+	var sh string
+	for _, s := range imp.db.Sources.Source {
+		if strval(s.Stitle) == title {
+			sh = s.Handle
+			break
+		}
+	}
+	if sh == "" {
+		s := Source{ID: imp.newID("S", ""), Handle: newHandle(), Change: imp.change, Stitle: new(title)}
+		imp.sources[s.Handle] = len(imp.db.Sources.Source)
+		imp.db.Sources.Source = append(imp.db.Sources.Source, s)
+		sh = s.Handle
+	}
+
+	for _, cr := range refs {
+		if i, ok := imp.citations[cr.Hlink]; ok {
+			if c := imp.db.Citations.Citation[i]; c.Sourceref != nil && c.Sourceref.Hlink == sh {
+				return refs
+			}
+		}
+	}
+
+	c := Citation{ID: imp.newID("C", ""), Handle: newHandle(), Change: imp.change, Confidence: "2", Sourceref: &Sourceref{Hlink: sh}}
+	imp.citations[c.Handle] = len(imp.db.Citations.Citation)
+	imp.db.Citations.Citation = append(imp.db.Citations.Citation, c)
+	return append(refs, Citationref{Hlink: c.Handle})
+}
+
+// addNote returns refs with a new note holding text appended, unless one of
+// the notes in refs already has that text.
+func (imp *csvImporter) addNote(refs []Noteref, text, noteType string) []Noteref {
+	for _, nr := range refs {
+		if i, ok := imp.notes[nr.Hlink]; ok && imp.db.Notes.Note[i].Text == text {
+			return refs
+		}
+	}
+	n := Note{ID: imp.newID("N", ""), Handle: newHandle(), Change: imp.change, Type: noteType, Text: text}
+	imp.notes[n.Handle] = len(imp.db.Notes.Note)
+	imp.db.Notes.Note = append(imp.db.Notes.Note, n)
+	return append(refs, Noteref{Hlink: n.Handle})
+}
+
+func (imp *csvImporter) marriageRow(row map[string]string) {
+	fh := imp.resolveFamily(row["marriage"])
+	var father, mother string
+	if ref := row["husband"]; ref != "" {
+		father = imp.resolvePerson(ref)
+	}
+	if ref := row["wife"]; ref != "" {
+		mother = imp.resolvePerson(ref)
+	}
+
+	f := imp.family(fh)
+	f.Change = imp.change
+	if f.Rel == nil {
+		f.Rel = &Rel{Type: "Married"}
+	}
+	if father != "" {
+		if f.Father != nil && f.Father.Hlink != father {
+			imp.removeParentin(f.Father.Hlink, fh)
+		}
+		f.Father = &Father{Hlink: father}
+		imp.addParentin(father, fh)
+	}
+	if mother != "" {
+		if f.Mother != nil && f.Mother.Hlink != mother {
+			imp.removeParentin(f.Mother.Hlink, fh)
+		}
+		f.Mother = &Mother{Hlink: mother}
+		imp.addParentin(mother, fh)
+	}
+
+	if date, place, source := row["date"], row["place"], row["source"]; date != "" || place != "" || source != "" {
+		eh := imp.familyEvent(fh, "Marriage")
+		imp.updateEvent(eh, date, place, source)
+	}
+
+	if note := row["note"]; note != "" {
+		refs := imp.addNote(imp.family(fh).Noteref, note, "Family Note")
+		imp.family(fh).Noteref = refs
+	}
+}
+
+func (imp *csvImporter) addParentin(person, family string) {
+	p := imp.person(person)
+	for _, pi := range p.Parentin {
+		if pi.Hlink == family {
+			return
+		}
+	}
+	p.Parentin = append(p.Parentin, Parentin{Hlink: family})
+	p.Change = imp.change
+}
+
+// removeParentin removes family from the families in which person is a
+// parent, if the person exists.
+func (imp *csvImporter) removeParentin(person, family string) {
+	if _, ok := imp.people[person]; !ok {
+		return
+	}
+	p := imp.person(person)
+	n := len(p.Parentin)
+	p.Parentin = slices.DeleteFunc(p.Parentin, func(pi Parentin) bool { return pi.Hlink == family })
+	if len(p.Parentin) != n {
+		p.Change = imp.change
+	}
+}
+
+func (imp *csvImporter) familyRow(row map[string]string) {
+	fh := imp.resolveFamily(row["family"])
+	if row["child"] == "" {
+		return
+	}
+	ch := imp.resolvePerson(row["child"])
+
+	f := imp.family(fh)
+	found := false
+	for _, cr := range f.Childref {
+		if cr.Hlink == ch {
+			found = true
+			break
+		}
+	}
+	if !found {
+		f.Childref = append(f.Childref, Childref{Hlink: ch})
+		f.Change = imp.change
+	}
+
+	c := imp.person(ch)
+	for _, co := range c.Childof {
+		if co.Hlink == fh {
+			return
+		}
+	}
+	c.Childof = append(c.Childof, Childof{Hlink: fh})
+	c.Change = imp.change
+}
@@ -0,0 +1,195 @@
+package grampsxml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const csvSample = `Place,Title,Name,Type,Latitude,Longitude,Code,Enclosed_by,Date
+[P0000],"Greenfield, Yorkshire",Greenfield,Town,53.5,-2.0,,[P0001],
+[P0001],Yorkshire,Yorkshire,County,,,,,
+
+Person,Surname,Given,Call,Suffix,Prefix,Title,Gender,Birth date,Birth place,Birth source,Baptism date,Baptism place,Baptism source,Death date,Death place,Death source,Burial date,Burial place,Burial source,Note
+[I0000],Garner,Lewis,,,,,male,1855-06-21,[P0000],Parish register,,,,about 1911,,,,,,Miller at Greenfield
+[I0001],Zieliński,Anna,,,,,female,between 1858 and 1860,,,,,,,,,,,,
+[I0002],Garner,Eliza,,,,,female,1880,[P0000],,,,,,,,,,,
+
+Marriage,Husband,Wife,Date,Place,Source,Note
+[F0000],[I0000],[I0001],1879-04-02,[P0000],,
+
+Family,Child
+[F0000],[I0002]
+`
+
+func TestCSVRoundTrip(t *testing.T) {
+	var db Database
+	if err := ReadCSV(strings.NewReader(csvSample), &db); err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, &db); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+
+	if diff := cmp.Diff(csvSample, buf.String()); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+
+	ix := NewIndex(&db)
+	child := &db.People.Person[2]
+	if len(child.Childof) != 1 || ix.Family(child.Childof[0].Hlink) == nil {
+		t.Errorf("child not linked to family: %+v", child.Childof)
+	}
+	father := &db.People.Person[0]
+	if len(father.Parentin) != 1 || father.Parentin[0].Hlink != db.Families.Family[0].Handle {
+		t.Errorf("father not linked to family: %+v", father.Parentin)
+	}
+}
+
+func TestCSVMergeIntoDatabase(t *testing.T) {
+	db := Database{
+		People: &People{
+			Person: []Person{
+				{
+					Handle:   "_p1",
+					ID:       new("I0001"),
+					Gender:   "M",
+					Name:     []Name{{Type: new("Birth Name"), First: new("John"), Surname: []Surname{{Surname: "Smith"}}}},
+					Eventref: []Eventref{{Hlink: "_e1", Role: new("Primary")}},
+				},
+			},
+		},
+		Events: &Events{
+			Event: []Event{
+				{Handle: "_e1", ID: new("E0001"), Type: new("Birth"), Dateval: &Dateval{Val: "1800"}},
+			},
+		},
+	}
+
+	input := `ID,Given,Birth Date,birth_place
+[I0001],Johnny,1801-02-03,Leeds
+[I0009],Mary,,Leeds
+`
+	if err := ReadCSV(strings.NewReader(input), &db); err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+
+	if got := len(db.People.Person); got != 2 {
+		t.Fatalf("got %d people, want 2", got)
+	}
+	if got := len(db.Events.Event); got != 2 {
+		t.Fatalf("got %d events, want 2", got)
+	}
+	if got := len(db.Places.Place); got != 1 {
+		t.Fatalf("got %d places, want 1", got)
+	}
+
+	p := db.People.Person[0]
+	if got := *p.Name[0].First; got != "Johnny" {
+		t.Errorf("got given name %q, want Johnny", got)
+	}
+	if got := p.Name[0].Surname[0].Surname; got != "Smith" {
+		t.Errorf("got surname %q, want Smith", got)
+	}
+	ev := db.Events.Event[0]
+	if diff := cmp.Diff(&Dateval{Val: "1801-02-03"}, ev.Dateval); diff != "" {
+		t.Errorf("birth date mismatch (-want +got):\n%s", diff)
+	}
+	if ev.Place == nil || ev.Place.Hlink != db.Places.Place[0].Handle {
+		t.Errorf("birth place not set: %+v", ev.Place)
+	}
+	if got := *db.People.Person[1].ID; got != "I0009" {
+		t.Errorf("got new person ID %q, want I0009", got)
+	}
+	if got := *db.Events.Event[1].ID; got != "E0000" {
+		t.Errorf("got new event ID %q, want E0000", got)
+	}
+	if db.Events.Event[1].Place.Hlink != db.Places.Place[0].Handle {
+		t.Errorf("place was not reused for second person")
+	}
+}
+
+func TestCSVMarriageReplacesParent(t *testing.T) {
+	var db Database
+	if err := ReadCSV(strings.NewReader(csvSample), &db); err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	input := "Marriage,Husband,Wife\n[F0000],[I0002],\n"
+	if err := ReadCSV(strings.NewReader(input), &db); err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+
+	fh := db.Families.Family[0].Handle
+	if got := db.Families.Family[0].Father.Hlink; got != db.People.Person[2].Handle {
+		t.Errorf("got father %s, want %s", got, db.People.Person[2].Handle)
+	}
+	if got := db.People.Person[0].Parentin; len(got) != 0 {
+		t.Errorf("replaced father still linked to family: %+v", got)
+	}
+	if diff := cmp.Diff([]Parentin{{Hlink: fh}}, db.People.Person[2].Parentin); diff != "" {
+		t.Errorf("new father mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]Parentin{{Hlink: fh}}, db.People.Person[1].Parentin); diff != "" {
+		t.Errorf("mother mismatch (-want +got):\n%s", diff)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errWriteFailed }
+
+var errWriteFailed = errors.New("write failed")
+
+func TestWriteCSVWriterError(t *testing.T) {
+	// Enough rows to fill the CSV writer's buffer before the end.
+	db := Database{Places: &Places{}}
+	for i := range 1000 {
+		db.Places.Place = append(db.Places.Place, Placeobj{Handle: fmt.Sprintf("_p%d", i), Type: "Town", Pname: []Pname{{Value: "Greenfield"}}})
+	}
+	if err := WriteCSV(failingWriter{}, &db); !errors.Is(err, errWriteFailed) {
+		t.Errorf("got error %v, wanted %v", err, errWriteFailed)
+	}
+}
+
+func TestReadCSVDataBeforeHeader(t *testing.T) {
+	var db Database
+	err := ReadCSV(strings.NewReader("[I0001],Smith\n"), &db)
+	if err == nil {
+		t.Fatalf("expected error for data without headings")
+	}
+}
+
+func TestCSVPlaceDates(t *testing.T) {
+	input := `Place,Title,Name,Type,Latitude,Longitude,Code,Enclosed_by,Date
+[P0000],,Lviv,City,,,,[P0001],from 1772 to 1918
+[P0000],,Lviv,City,,,,[P0002],from 1919 to 1939
+[P0001],,Austria,Country,,,,,
+[P0002],,Poland,Country,,,,,
+`
+	var db Database
+	if err := ReadCSV(strings.NewReader(input), &db); err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+
+	want := []Placeref{
+		{Hlink: db.Places.Place[1].Handle, Datespan: &Datespan{Start: "1772", Stop: "1918"}},
+		{Hlink: db.Places.Place[2].Handle, Datespan: &Datespan{Start: "1919", Stop: "1939"}},
+	}
+	if diff := cmp.Diff(want, db.Places.Place[0].Placeref); diff != "" {
+		t.Errorf("placeref mismatch (-want +got):\n%s", diff)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, &db); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	if got := strings.SplitAfter(buf.String(), "\n\n")[0]; got != input+"\n" {
+		t.Errorf("got places section:\n%s\nwanted:\n%s", got, input)
+	}
+}
@@ -0,0 +1,360 @@
+package grampsxml
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Date is a view over the four mutually exclusive date elements that Gramps
+// attaches to events, names, citations and other objects. At most one of the
+// fields is expected to be non-nil.
+type Date struct {
+	Daterange *Daterange
+	Datespan  *Datespan
+	Dateval   *Dateval
+	Datestr   *Datestr
+}
+
+// IsZero reports whether d carries no date.
+func (d Date) IsZero() bool {
+	return d.Daterange == nil && d.Datespan == nil && d.Dateval == nil && d.Datestr == nil
+}
+
+// String returns the date in the textual form used by Gramps' ISO date
+// displayer, for example "about 1850-03", "between 1850 and 1860" or
+// "estimated from 1820 to 1830 (Julian)". Text-only dates are returned verbatim.
+func (d Date) String() string {
+	var quality, cformat *string
+	var body string
+	switch {
+	case d.Dateval != nil:
+		quality, cformat = d.Dateval.Quality, d.Dateval.Cformat
+		body = d.Dateval.Val
+		if d.Dateval.Type != nil && *d.Dateval.Type != "" {
+			body = *d.Dateval.Type + " " + body
+		}
+	case d.Daterange != nil:
+		quality, cformat = d.Daterange.Quality, d.Daterange.Cformat
+		body = "between " + d.Daterange.Start + " and " + d.Daterange.Stop
+	case d.Datespan != nil:
+		quality, cformat = d.Datespan.Quality, d.Datespan.Cformat
+		body = "from " + d.Datespan.Start + " to " + d.Datespan.Stop
+	case d.Datestr != nil:
+		return d.Datestr.Val
+	default:
+		return ""
+	}
+	if quality != nil && *quality != "" {
+		body = *quality + " " + body
+	}
+	if cformat != nil && *cformat != "" {
+		body += " (" + *cformat + ")"
+	}
+	return body
+}
+
+var (
+	dateValueRe    = regexp.MustCompile(`^(\d{1,4}|\?{4})(-(\d{1,2}|\?\?)(-(\d{1,2}|\?\?))?)?$`)
+	dateCalendarRe = regexp.MustCompile(`^(.*?)\s*\(([^()]+)\)$`)
+)
+
+var dateModifiers = map[string]string{
+	"before": "before",
+	"bef":    "before",
+	"bef.":   "before",
+	"after":  "after",
+	"aft":    "after",
+	"aft.":   "after",
+	"about":  "about",
+	"abt":    "about",
+	"abt.":   "about",
+	"from":   "from",
+	"to":     "to",
+}
+
+var dateQualities = map[string]string{
+	"estimated":  "estimated",
+	"est":        "estimated",
+	"est.":       "estimated",
+	"calculated": "calculated",
+	"calc":       "calculated",
+	"calc.":      "calculated",
+}
+
+// ParseDate parses the textual date forms produced by [Date.String], as well
+// as common abbreviations such as "abt" and "bef". Text that cannot be
+// interpreted is kept as a [Datestr]. An empty string yields the zero Date.
+func ParseDate(s string) Date {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Date{}
+	}
+	text := s
+
+	var cformat *string
+	if m := dateCalendarRe.FindStringSubmatch(s); m != nil {
+		cformat = new(m[2])
+		s = m[1]
+	}
+
+	fields := strings.Fields(s)
+	var quality *string
+	if len(fields) > 0 {
+		if q, ok := dateQualities[strings.ToLower(fields[0])]; ok {
+			quality = new(q)
+			fields = fields[1:]
+		}
+	}
+
+	switch {
+	case len(fields) == 4 && strings.EqualFold(fields[0], "between") && strings.EqualFold(fields[2], "and"):
+		if isDateValue(fields[1]) && isDateValue(fields[3]) {
+			return Date{Daterange: &Daterange{Start: normDateValue(fields[1]), Stop: normDateValue(fields[3]), Quality: quality, Cformat: cformat}}
+		}
+	case len(fields) == 4 && strings.EqualFold(fields[0], "from") && strings.EqualFold(fields[2], "to"):
+		if isDateValue(fields[1]) && isDateValue(fields[3]) {
+			return Date{Datespan: &Datespan{Start: normDateValue(fields[1]), Stop: normDateValue(fields[3]), Quality: quality, Cformat: cformat}}
+		}
+	case len(fields) == 2:
+		if mod, ok := dateModifiers[strings.ToLower(fields[0])]; ok && isDateValue(fields[1]) {
+			return Date{Dateval: &Dateval{Val: normDateValue(fields[1]), Type: new(mod), Quality: quality, Cformat: cformat}}
+		}
+	case len(fields) == 1:
+		if isDateValue(fields[0]) {
+			return Date{Dateval: &Dateval{Val: normDateValue(fields[0]), Quality: quality, Cformat: cformat}}
+		}
+	}
+
+	return Date{Datestr: &Datestr{Val: text}}
+}
+
+func isDateValue(s string) bool {
+	return dateValueRe.MatchString(s)
+}
+
+// normDateValue trims zero month and day components, which Gramps uses to
+// mark unknown parts, so that "1850-00-00" becomes "1850".
+func normDateValue(s string) string {
+	parts := strings.Split(s, "-")
+	for len(parts) > 1 {
+		last := parts[len(parts)-1]
+		if strings.Trim(last, "0") != "" {
+			break
+		}
+		parts = parts[:len(parts)-1]
+	}
+	return strings.Join(parts, "-")
+}
+
+// Date returns the date of the event.
+func (e *Event) Date() Date {
+	return Date{Daterange: e.Daterange, Datespan: e.Datespan, Dateval: e.Dateval, Datestr: e.Datestr}
+}
+
+// SetDate replaces the date of the event.
+func (e *Event) SetDate(d Date) {
+	e.Daterange, e.Datespan, e.Dateval, e.Datestr = d.Daterange, d.Datespan, d.Dateval, d.Datestr
+}
+
+// Date returns the date of the name.
+func (n *Name) Date() Date {
+	return Date{Daterange: n.Daterange, Datespan: n.Datespan, Dateval: n.Dateval, Datestr: n.Datestr}
+}
+
+// SetDate replaces the date of the name.
+func (n *Name) SetDate(d Date) {
+	n.Daterange, n.Datespan, n.Dateval, n.Datestr = d.Daterange, d.Datespan, d.Dateval, d.Datestr
+}
+
+// Date returns the date of the address.
+func (a *Address) Date() Date {
+	return Date{Daterange: a.Daterange, Datespan: a.Datespan, Dateval: a.Dateval, Datestr: a.Datestr}
+}
+
+// SetDate replaces the date of the address.
+func (a *Address) SetDate(d Date) {
+	a.Daterange, a.Datespan, a.Dateval, a.Datestr = d.Daterange, d.Datespan, d.Dateval, d.Datestr
+}
+
+// Date returns the date for which the place name is valid.
+func (p *Pname) Date() Date {
+	return Date{Daterange: p.Daterange, Datespan: p.Datespan, Dateval: p.Dateval, Datestr: p.Datestr}
+}
+
+// SetDate replaces the date for which the place name is valid.
+func (p *Pname) SetDate(d Date) {
+	p.Daterange, p.Datespan, p.Dateval, p.Datestr = d.Daterange, d.Datespan, d.Dateval, d.Datestr
+}
+
+// Date returns the date on which the enclosing place contained the place.
+func (p *Placeref) Date() Date {
+	return Date{Daterange: p.Daterange, Datespan: p.Datespan, Dateval: p.Dateval, Datestr: p.Datestr}
+}
+
+// SetDate replaces the date on which the enclosing place contained the
+// place.
+func (p *Placeref) SetDate(d Date) {
+	p.Daterange, p.Datespan, p.Dateval, p.Datestr = d.Daterange, d.Datespan, d.Dateval, d.Datestr
+}
+
+// Date returns the date of the media object.
+func (o *Object) Date() Date {
+	return Date{Daterange: o.Daterange, Datespan: o.Datespan, Dateval: o.Dateval, Datestr: o.Datestr}
+}
+
+// SetDate replaces the date of the media object.
+func (o *Object) SetDate(d Date) {
+	o.Daterange, o.Datespan, o.Dateval, o.Datestr = d.Daterange, d.Datespan, d.Dateval, d.Datestr
+}
+
+// Date returns the date of the citation.
+func (c *Citation) Date() Date {
+	return Date{Daterange: c.Daterange, Datespan: c.Datespan, Dateval: c.Dateval, Datestr: c.Datestr}
+}
+
+// SetDate replaces the date of the citation.
+func (c *Citation) SetDate(d Date) {
+	c.Daterange, c.Datespan, c.Dateval, c.Datestr = d.Daterange, d.Datespan, d.Dateval, d.Datestr
+}
+
+// Date returns the date of the LDS ordinance.
+func (l *LdsOrd) Date() Date {
+	return Date{Daterange: l.Daterange, Datespan: l.Datespan, Dateval: l.Dateval, Datestr: l.Datestr}
+}
+
+// SetDate replaces the date of the LDS ordinance.
+func (l *LdsOrd) SetDate(d Date) {
+	l.Daterange, l.Datespan, l.Dateval, l.Datestr = d.Daterange, d.Datespan, d.Dateval, d.Datestr
+}
+
+// parseDateValue splits a date value such as "1850-03-12" into its year,
+// month and day. Missing or unknown components are returned as zero.
+func parseDateValue(s string) (year, month, day int) {
+	parts := strings.SplitN(s, "-", 3)
+	n := [3]int{}
+	for i, p := range parts {
+		for _, c := range p {
+			if c < '0' || c > '9' {
+				n[i] = 0
+				break
+			}
+			n[i] = n[i]*10 + int(c-'0')
+		}
+	}
+	return n[0], n[1], n[2]
+}
+
+// formatDateValue is the inverse of parseDateValue.
+func formatDateValue(year, month, day int) string {
+	switch {
+	case day != 0:
+		return fmt.Sprintf("%04d-%02d-%02d", year, month, day)
+	case month != 0:
+		return fmt.Sprintf("%04d-%02d", year, month)
+	default:
+		return fmt.Sprintf("%04d", year)
+	}
+}
+
+// julianDay returns the Julian day number of a date in the given calendar,
+// which is the value Gramps uses to sort dates. Unknown months and days are
+// treated as the first. Calendars other than Julian are treated as
+// Gregorian.
+func julianDay(calendar string, year, month, day int) int {
+	month, day = max(month, 1), max(day, 1)
+	a := (14 - month) / 12
+	y := year + 4800 - a
+	m := month + 12*a - 3
+	if calendar == "Julian" {
+		return day + (153*m+2)/5 + 365*y + y/4 - 32083
+	}
+	return day + (153*m+2)/5 + 365*y + y/4 - y/100 + y/400 - 32045
+}
+
+// dateAboutYears is the number of years either side of an "about" date that
+// it is taken to cover, matching Gramps' default date-about-range setting.
+const dateAboutYears = 50
+
+// bounds returns the Julian day numbers of the first and last days that d
+// may refer to. Dates that are open at one end, such as "before 1850",
+// extend to math.MinInt or math.MaxInt. The result is not ok for the zero
+// Date, text-only dates and dates with an unknown year.
+func (d Date) bounds() (lo, hi int, ok bool) {
+	switch {
+	case d.Dateval != nil:
+		cal := strval(d.Dateval.Cformat)
+		lo, hi, ok = dateValueBounds(cal, d.Dateval.Val)
+		switch strval(d.Dateval.Type) {
+		case "before", "to":
+			lo = math.MinInt
+		case "after", "from":
+			hi = math.MaxInt
+		case "about":
+			days := dateAboutYears*365 + dateAboutYears/4
+			lo, hi = lo-days, hi+days
+		}
+		return lo, hi, ok
+	case d.Daterange != nil:
+		return dateRangeBounds(strval(d.Daterange.Cformat), d.Daterange.Start, d.Daterange.Stop)
+	case d.Datespan != nil:
+		return dateRangeBounds(strval(d.Datespan.Cformat), d.Datespan.Start, d.Datespan.Stop)
+	}
+	return 0, 0, false
+}
+
+// startDay returns the Julian day number of the first day of the value of
+// d, or of the start of a range or span, ignoring any modifier. Unlike the
+// lower bound given by bounds, it orders "before 1850" and "about 1850"
+// with 1850. The result is not ok in the same cases as for bounds.
+func (d Date) startDay() (int, bool) {
+	var cal, val string
+	switch {
+	case d.Dateval != nil:
+		cal, val = strval(d.Dateval.Cformat), d.Dateval.Val
+	case d.Daterange != nil:
+		cal, val = strval(d.Daterange.Cformat), d.Daterange.Start
+	case d.Datespan != nil:
+		cal, val = strval(d.Datespan.Cformat), d.Datespan.Start
+	default:
+		return 0, false
+	}
+	lo, _, ok := dateValueBounds(cal, val)
+	return lo, ok
+}
+
+func dateRangeBounds(calendar, start, stop string) (lo, hi int, ok bool) {
+	lo, _, ok1 := dateValueBounds(calendar, start)
+	_, hi, ok2 := dateValueBounds(calendar, stop)
+	return lo, hi, ok1 && ok2
+}
+
+// dateValueBounds returns the first and last days covered by a date value,
+// which is a whole year or month if the day or month is not given.
+func dateValueBounds(calendar, val string) (lo, hi int, ok bool) {
+	y, m, d := parseDateValue(val)
+	if y == 0 {
+		return 0, 0, false
+	}
+	lo = julianDay(calendar, y, m, d)
+	switch {
+	case d != 0:
+		hi = lo
+	case m == 12:
+		hi = julianDay(calendar, y+1, 1, 1) - 1
+	case m != 0:
+		hi = julianDay(calendar, y, m+1, 1) - 1
+	default:
+		hi = julianDay(calendar, y+1, 1, 1) - 1
+	}
+	return lo, hi, true
+}
+
+// overlaps reports whether d and e may refer to the same day. Dates whose
+// bounds are unknown do not overlap any date.
+func (d Date) overlaps(e Date) bool {
+	lo1, hi1, ok1 := d.bounds()
+	lo2, hi2, ok2 := e.bounds()
+	return ok1 && ok2 && lo1 <= hi2 && lo2 <= hi1
+}
@@ -0,0 +1,77 @@
+package grampsxml
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDate(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  Date
+		text  string // expected String result, if different from input
+	}{
+		{input: "", want: Date{}},
+		{input: "1850", want: Date{Dateval: &Dateval{Val: "1850"}}},
+		{input: "1850-03-12", want: Date{Dateval: &Dateval{Val: "1850-03-12"}}},
+		{input: "1850-00-00", want: Date{Dateval: &Dateval{Val: "1850"}}, text: "1850"},
+		{input: "about 1850-03", want: Date{Dateval: &Dateval{Val: "1850-03", Type: new("about")}}},
+		{input: "abt 1850", want: Date{Dateval: &Dateval{Val: "1850", Type: new("about")}}, text: "about 1850"},
+		{input: "before 1900", want: Date{Dateval: &Dateval{Val: "1900", Type: new("before")}}},
+		{input: "estimated after 1900", want: Date{Dateval: &Dateval{Val: "1900", Type: new("after"), Quality: new("estimated")}}},
+		{input: "between 1850 and 1860", want: Date{Daterange: &Daterange{Start: "1850", Stop: "1860"}}},
+		{input: "calculated from 1820-01 to 1830 (Julian)", want: Date{Datespan: &Datespan{Start: "1820-01", Stop: "1830", Quality: new("calculated"), Cformat: new("Julian")}}},
+		{input: "Christmas 1850", want: Date{Datestr: &Datestr{Val: "Christmas 1850"}}},
+		{input: "between 1850 and sometime", want: Date{Datestr: &Datestr{Val: "between 1850 and sometime"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got := ParseDate(tc.input)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ParseDate mismatch (-want +got):\n%s", diff)
+			}
+			text := tc.text
+			if text == "" {
+				text = tc.input
+			}
+			if got.String() != text {
+				t.Errorf("String() = %q, want %q", got.String(), text)
+			}
+		})
+	}
+}
+
+func TestDateOverlaps(t *testing.T) {
+	testCases := []struct {
+		a, b string
+		want bool
+	}{
+		{"1850", "1850-06-01", true},
+		{"1850", "1851", false},
+		{"1850-02", "1850-03-01", false},
+		{"1850-12", "1850-12-31", true},
+		{"before 1850", "1700", true},
+		{"before 1850", "1851", false},
+		{"after 1850", "1900", true},
+		{"about 1850", "1890", true},
+		{"about 1850", "1910", false},
+		{"between 1850 and 1860", "1855", true},
+		{"from 1850 to 1860", "1861", false},
+		{"Christmas 1850", "1850", false},
+		{"", "1850", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.a+"/"+tc.b, func(t *testing.T) {
+			a, b := ParseDate(tc.a), ParseDate(tc.b)
+			if got := a.overlaps(b); got != tc.want {
+				t.Errorf("got %v, wanted %v", got, tc.want)
+			}
+			if got := b.overlaps(a); got != tc.want {
+				t.Errorf("reversed: got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package grampsxml
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DeleteOptions controls [Delete].
+type DeleteOptions struct {
+	// Refuse makes Delete fail with a [*ReferencedError] if any object
+	// that is not being deleted refers to an object being deleted, instead
+	// of removing the references. Bookmarks and the home and default people
+	// never prevent deletion.
+	Refuse bool
+
+	// Dependents also deletes the objects that cannot exist without a
+	// deleted object, such as the citations of a deleted source.
+	Dependents bool
+
+	// Orphans lists the types of object, named as in [ChangedObject], that
+	// are also deleted if a deleted object referred to them and nothing else
+	// does. For example, []string{"event", "citation", "note"} deletes the
+	// events, citations and notes used only by a deleted person, and the
+	// citations and notes used only by those events.
+	Orphans []string
+}
+
+// ObjectRef identifies a primary object.
+type ObjectRef struct {
+	// Type is the type of the object, named as in [ChangedObject].
+	Type   string `json:"type"`
+	Handle string `json:"handle"`
+	ID     string `json:"id,omitempty"`
+}
+
+// String returns the type of the object and its ID, or its handle if it
+// has no ID, such as "Person I0012".
+func (r ObjectRef) String() string {
+	return cmp.Or(objectTypeLabels[r.Type], r.Type) + " " + cmp.Or(r.ID, r.Handle)
+}
+
+// ReferencedError is the error returned by [Delete] when it is asked to
+// refuse to delete objects that are still referred to.
+type ReferencedError struct {
+	// Object is the object that was to be deleted.
+	Object ObjectRef
+
+	// Referrers lists the objects that refer to Object or to one of the
+	// objects that would have been deleted with it.
+	Referrers []ObjectRef
+}
+
+func (e *ReferencedError) Error() string {
+	names := make([]string, len(e.Referrers))
+	for i, r := range e.Referrers {
+		names[i] = r.String()
+	}
+	return fmt.Sprintf("grampsxml: %s is referred to by %s", e.Object, strings.Join(names, ", "))
+}
+
+// Delete removes the object of the given type and handle from db, along
+// with every reference to it, and returns the objects deleted, in the order
+// they appeared in db. Lists of references lose the elements referring to
+// deleted objects, so that deleting a person removes it from the children of
+// its families, and optional references such as the place of an event are
+// cleared. The change time of every object whose references were removed is
+// set to the current time.
+//
+// The options select whether references prevent deletion and which related
+// objects are deleted too. Nothing is changed if an error is returned.
+func Delete(db *Database, objType, handle string, opts DeleteOptions) ([]ObjectRef, error) {
+	objs := make(map[string]primaryObject)
+	walkObjects(db, func(o primaryObject) { objs[o.objType+"\x00"+o.handle] = o })
+	key := objType + "\x00" + handle
+	if _, ok := objs[key]; !ok {
+		return nil, fmt.Errorf("grampsxml: %s %s not found", objType, handle)
+	}
+
+	deleted := map[string]bool{key: true}
+	for opts.Dependents {
+		n := len(deleted)
+		walkReferences(db, func(r reference) {
+			if r.owner != "" && deleted[r.target+"\x00"+*r.hlink] && dependsOn(r.ownerType, r.target) {
+				deleted[r.ownerType+"\x00"+r.owner] = true
+			}
+		})
+		if len(deleted) == n {
+			break
+		}
+	}
+
+	if opts.Refuse {
+		var referrers []ObjectRef
+		seen := make(map[string]bool)
+		walkReferences(db, func(r reference) {
+			owner := r.ownerType + "\x00" + r.owner
+			if r.owner == "" || deleted[owner] || seen[owner] || !deleted[r.target+"\x00"+*r.hlink] {
+				return
+			}
+			seen[owner] = true
+			referrers = append(referrers, objectRef(objs[owner]))
+		})
+		if len(referrers) > 0 {
+			return nil, &ReferencedError{Object: objectRef(objs[key]), Referrers: referrers}
+		}
+	}
+
+	if len(opts.Orphans) > 0 {
+		orphanTypes := make(map[string]bool)
+		for _, t := range opts.Orphans {
+			orphanTypes[t] = true
+		}
+		for {
+			used := make(map[string]bool)
+			candidates := make(map[string]bool)
+			walkReferences(db, func(r reference) {
+				target := r.target + "\x00" + *r.hlink
+				if r.owner == "" || !deleted[r.ownerType+"\x00"+r.owner] {
+					used[target] = true
+					return
+				}
+				if _, ok := objs[target]; ok && orphanTypes[r.target] && !deleted[target] {
+					candidates[target] = true
+				}
+			})
+			n := len(deleted)
+			for c := range candidates {
+				if !used[c] {
+					deleted[c] = true
+				}
+			}
+			if len(deleted) == n {
+				break
+			}
+		}
+	}
+
+	changed := make(map[string]bool)
+	walkReferences(db, func(r reference) {
+		if !deleted[r.target+"\x00"+*r.hlink] {
+			return
+		}
+		*r.hlink = ""
+		if r.owner != "" {
+			changed[r.ownerType+"\x00"+r.owner] = true
+		}
+	})
+	pruneRootReferences(db)
+
+	var refs []ObjectRef
+	stamp := changeStamp(time.Now())
+	walkObjects(db, func(o primaryObject) {
+		k := o.objType + "\x00" + o.handle
+		switch {
+		case deleted[k]:
+			refs = append(refs, objectRef(o))
+		case changed[k]:
+			pruneReferences(reflect.ValueOf(o.value).Elem())
+			*o.change = stamp
+		}
+	})
+	removeObjects(db, func(objType, handle string) bool { return deleted[objType+"\x00"+handle] })
+	return refs, nil
+}
+
+func objectRef(o primaryObject) ObjectRef {
+	r := ObjectRef{Type: o.objType, Handle: o.handle}
+	if o.id != nil {
+		r.ID = strval(*o.id)
+	}
+	return r
+}
+
+// pruneRootReferences removes the bookmarks, and clears the home and
+// default people, that refer to empty handles.
+func pruneRootReferences(db *Database) {
+	if db.People != nil {
+		if db.People.Default != nil && *db.People.Default == "" {
+			db.People.Default = nil
+		}
+		if db.People.Home != nil && *db.People.Home == "" {
+			db.People.Home = nil
+		}
+	}
+	if db.Bookmarks != nil {
+		pruneReferences(reflect.ValueOf(db.Bookmarks).Elem())
+	}
+}
+
+// pruneReferences removes the references with empty handles held by v, a
+// struct. Elements of lists that refer to nothing are removed, and pointers
+// to references that refer to nothing are set to nil.
+func pruneReferences(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		if emptyReference(v.Elem()) {
+			v.SetZero()
+			return
+		}
+		pruneReferences(v.Elem())
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				pruneReferences(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		n := 0
+		for i := range v.Len() {
+			e := v.Index(i)
+			if emptyReference(e) {
+				continue
+			}
+			pruneReferences(e)
+			v.Index(n).Set(e)
+			n++
+		}
+		if n < v.Len() {
+			v.Set(v.Slice(0, n))
+		}
+	}
+}
+
+// emptyReference reports whether v is a reference to an object with an
+// empty handle.
+func emptyReference(v reflect.Value) bool {
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+	f := v.FieldByName("Hlink")
+	return f.IsValid() && f.Kind() == reflect.String && f.String() == ""
+}
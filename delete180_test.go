@@ -0,0 +1,36 @@
+//go:build gramps_schema180
+
+package grampsxml
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDeleteDNATest(t *testing.T) {
+	db := deleteSample()
+	db.DNATests = &DNATests{DNATest: []DNATest{
+		{Handle: "_d1", Person: &PersonLink{Hlink: "_i1"}},
+		{Handle: "_d2", Person: &PersonLink{Hlink: "_i2"}},
+	}}
+	db.DNAMatches = &DNAMatches{DNAMatch: []DNAMatch{
+		{Handle: "_m1", SubjectTest: &SubjectTest{Hlink: "_d1"}, MatchTest: &MatchTest{Hlink: "_d2"}},
+	}}
+
+	got, err := Delete(db, targetDNATest, "_d1", DeleteOptions{Dependents: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []ObjectRef{{Type: "dnatest", Handle: "_d1"}, {Type: "dnamatch", Handle: "_m1"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("deleted mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := Delete(db, targetPerson, "_i2", DeleteOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := db.DNATests.DNATest[0]; d.Person != nil || d.Change == "" {
+		t.Errorf("dna test not updated: %+v", d)
+	}
+}
@@ -0,0 +1,229 @@
+package grampsxml
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func deleteSample() *Database {
+	return &Database{
+		Events: &Events{Event: []Event{
+			{Handle: "_e1", ID: new("E0001"), Place: &Place{Hlink: "_p1"}, Citationref: []Citationref{{Hlink: "_c2"}}},
+			{Handle: "_e2", ID: new("E0002"), Place: &Place{Hlink: "_p1"}},
+		}},
+		People: &People{
+			Home: new("_i1"),
+			Person: []Person{
+				{
+					Handle: "_i1", ID: new("I0001"),
+					Eventref:    []Eventref{{Hlink: "_e1"}},
+					Childof:     []Childof{{Hlink: "_f1"}},
+					Noteref:     []Noteref{{Hlink: "_n1"}},
+					Citationref: []Citationref{{Hlink: "_c1"}},
+				},
+				{Handle: "_i2", ID: new("I0002"), Parentin: []Parentin{{Hlink: "_f1"}}, Noteref: []Noteref{{Hlink: "_n2"}}},
+			},
+		},
+		Families: &Families{Family: []Family{
+			{Handle: "_f1", ID: new("F0001"), Father: &Father{Hlink: "_i2"}, Childref: []Childref{{Hlink: "_i1"}}},
+		}},
+		Citations: &Citations{Citation: []Citation{
+			{Handle: "_c1", ID: new("C0001"), Sourceref: &Sourceref{Hlink: "_s1"}},
+			{Handle: "_c2", ID: new("C0002"), Sourceref: &Sourceref{Hlink: "_s1"}},
+		}},
+		Sources: &Sources{Source: []Source{{Handle: "_s1", ID: new("S0001")}}},
+		Places:  &Places{Place: []Placeobj{{Handle: "_p1", ID: new("P0001")}}},
+		Notes: &Notes{Note: []Note{
+			{Handle: "_n1", ID: new("N0001")},
+			{Handle: "_n2", ID: new("N0002")},
+		}},
+		Bookmarks: &Bookmarks{Bookmark: []Bookmark{{Target: "person", Hlink: "_i1"}, {Target: "person", Hlink: "_i2"}}},
+	}
+}
+
+func objectHandles(db *Database) []string {
+	var hs []string
+	walkObjects(db, func(o primaryObject) { hs = append(hs, o.handle) })
+	return hs
+}
+
+func TestDelete(t *testing.T) {
+	testCases := []struct {
+		name        string
+		objType     string
+		handle      string
+		opts        DeleteOptions
+		wantDeleted []ObjectRef
+		wantLeft    []string
+		check       func(t *testing.T, db *Database)
+	}{
+		{
+			name:    "person",
+			objType: targetPerson, handle: "_i1",
+			wantDeleted: []ObjectRef{{Type: "person", Handle: "_i1", ID: "I0001"}},
+			wantLeft:    []string{"_e1", "_e2", "_i2", "_f1", "_c1", "_c2", "_s1", "_p1", "_n1", "_n2"},
+			check: func(t *testing.T, db *Database) {
+				f := db.Families.Family[0]
+				if len(f.Childref) != 0 || f.Change == "" {
+					t.Errorf("family not updated: %+v", f)
+				}
+				if db.People.Home != nil {
+					t.Errorf("home person not cleared")
+				}
+				if diff := cmp.Diff([]Bookmark{{Target: "person", Hlink: "_i2"}}, db.Bookmarks.Bookmark); diff != "" {
+					t.Errorf("bookmarks mismatch (-want +got):\n%s", diff)
+				}
+				if db.People.Person[0].Change != "" {
+					t.Errorf("change time of unrelated person was set")
+				}
+			},
+		},
+		{
+			name:    "person with orphans",
+			objType: targetPerson, handle: "_i1",
+			opts: DeleteOptions{Orphans: []string{"event", "citation", "note"}},
+			wantDeleted: []ObjectRef{
+				{Type: "event", Handle: "_e1", ID: "E0001"},
+				{Type: "person", Handle: "_i1", ID: "I0001"},
+				{Type: "citation", Handle: "_c1", ID: "C0001"},
+				{Type: "citation", Handle: "_c2", ID: "C0002"},
+				{Type: "note", Handle: "_n1", ID: "N0001"},
+			},
+			wantLeft: []string{"_e2", "_i2", "_f1", "_s1", "_p1", "_n2"},
+		},
+		{
+			name:    "source with dependents",
+			objType: targetSource, handle: "_s1",
+			opts: DeleteOptions{Dependents: true},
+			wantDeleted: []ObjectRef{
+				{Type: "citation", Handle: "_c1", ID: "C0001"},
+				{Type: "citation", Handle: "_c2", ID: "C0002"},
+				{Type: "source", Handle: "_s1", ID: "S0001"},
+			},
+			wantLeft: []string{"_e1", "_e2", "_i1", "_i2", "_f1", "_p1", "_n1", "_n2"},
+			check: func(t *testing.T, db *Database) {
+				if len(db.People.Person[0].Citationref) != 0 || len(db.Events.Event[0].Citationref) != 0 {
+					t.Errorf("citation references not removed")
+				}
+			},
+		},
+		{
+			name:    "source",
+			objType: targetSource, handle: "_s1",
+			wantDeleted: []ObjectRef{{Type: "source", Handle: "_s1", ID: "S0001"}},
+			wantLeft:    []string{"_e1", "_e2", "_i1", "_i2", "_f1", "_c1", "_c2", "_p1", "_n1", "_n2"},
+			check: func(t *testing.T, db *Database) {
+				for _, c := range db.Citations.Citation {
+					if c.Sourceref != nil {
+						t.Errorf("source of %s not cleared", c.Handle)
+					}
+				}
+			},
+		},
+		{
+			name:    "place",
+			objType: targetPlace, handle: "_p1",
+			wantDeleted: []ObjectRef{{Type: "place", Handle: "_p1", ID: "P0001"}},
+			wantLeft:    []string{"_e1", "_e2", "_i1", "_i2", "_f1", "_c1", "_c2", "_s1", "_n1", "_n2"},
+			check: func(t *testing.T, db *Database) {
+				for _, ev := range db.Events.Event {
+					if ev.Place != nil || ev.Change == "" {
+						t.Errorf("event %s not updated", ev.Handle)
+					}
+				}
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := deleteSample()
+			got, err := Delete(db, tc.objType, tc.handle, tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantDeleted, got); diff != "" {
+				t.Errorf("deleted mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantLeft, objectHandles(db)); diff != "" {
+				t.Errorf("remaining mismatch (-want +got):\n%s", diff)
+			}
+			if tc.check != nil {
+				tc.check(t, db)
+			}
+		})
+	}
+}
+
+func TestDeleteRefuse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		objType string
+		handle  string
+		opts    DeleteOptions
+		want    []ObjectRef
+	}{
+		{
+			name:    "note",
+			objType: targetNote, handle: "_n1",
+			want: []ObjectRef{{Type: "person", Handle: "_i1", ID: "I0001"}},
+		},
+		{
+			name:    "source",
+			objType: targetSource, handle: "_s1",
+			want: []ObjectRef{
+				{Type: "citation", Handle: "_c1", ID: "C0001"},
+				{Type: "citation", Handle: "_c2", ID: "C0002"},
+			},
+		},
+		{
+			name:    "source with dependents",
+			objType: targetSource, handle: "_s1",
+			opts: DeleteOptions{Dependents: true},
+			want: []ObjectRef{
+				{Type: "person", Handle: "_i1", ID: "I0001"},
+				{Type: "event", Handle: "_e1", ID: "E0001"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := deleteSample()
+			tc.opts.Refuse = true
+			_, err := Delete(db, tc.objType, tc.handle, tc.opts)
+			var re *ReferencedError
+			if !errors.As(err, &re) {
+				t.Fatalf("got error %v, want ReferencedError", err)
+			}
+			if diff := cmp.Diff(tc.want, re.Referrers); diff != "" {
+				t.Errorf("referrers mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(deleteSample(), db); diff != "" {
+				t.Errorf("database was modified (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDeleteRefuseUnreferenced(t *testing.T) {
+	db := deleteSample()
+	got, err := Delete(db, targetNote, "_n2", DeleteOptions{Refuse: true})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if want := "grampsxml: Note N0002 is referred to by Person I0002"; err.Error() != want {
+		t.Errorf("got error %q, want %q", err, want)
+	}
+	if got != nil {
+		t.Errorf("got deleted objects %v", got)
+	}
+
+	db.People.Person[1].Noteref = nil
+	if _, err := Delete(db, targetNote, "_n2", DeleteOptions{Refuse: true}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := Delete(db, targetNote, "_n2", DeleteOptions{}); err == nil {
+		t.Errorf("expected error deleting a missing note")
+	}
+}
@@ -0,0 +1,327 @@
+package grampsxml
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Statuses of an object in a [DatabaseDiff].
+const (
+	DiffAdded    = "added"
+	DiffRemoved  = "removed"
+	DiffModified = "modified"
+)
+
+// DatabaseDiff lists the differences between the primary objects of two
+// databases, as found by [Diff].
+type DatabaseDiff struct {
+	Objects []ObjectDiff `json:"objects"`
+}
+
+// ObjectDiff describes a primary object that was added, removed or modified.
+type ObjectDiff struct {
+	// Type is the type of the object, named as in [ChangedObject].
+	Type   string `json:"type"`
+	Handle string `json:"handle"`
+	ID     string `json:"id,omitempty"`
+
+	// Status is one of DiffAdded, DiffRemoved or DiffModified.
+	Status string `json:"status"`
+
+	// Changes lists the fields of a modified object that differ.
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// FieldChange is a difference in a single field of an object. Path names
+// the field using the Go field names of the object's type, with indexes for
+// the elements of lists, such as "Name[0].First". An index is that of the
+// element in the second database, or in the first if the element was
+// removed. Old and New hold the string, boolean or numeric values of the
+// field, or nil if it is absent.
+type FieldChange struct {
+	Path string `json:"path"`
+	Old  any    `json:"old"`
+	New  any    `json:"new"`
+}
+
+// Diff compares the primary objects of a and b, matching them by handle, so
+// that the order of objects and elements in an export is ignored. Objects
+// are reported in the order they appear in a, grouped by type, followed by
+// the objects that were added in b. The elements of lists are matched
+// before their fields are compared, so that reordering a list is not a
+// change: references are matched by the handle they refer to and other
+// elements by being equal. Elements left unmatched are compared in the
+// order they appear.
+func Diff(a, b *Database) *DatabaseDiff {
+	type entry struct {
+		obj  primaryObject
+		seen bool
+	}
+	var types []string
+	byType := make(map[string][]primaryObject)
+	walkObjects(a, func(o primaryObject) {
+		if _, ok := byType[o.objType]; !ok {
+			types = append(types, o.objType)
+		}
+		byType[o.objType] = append(byType[o.objType], o)
+	})
+	bByType := make(map[string][]primaryObject)
+	inB := make(map[string]*entry)
+	walkObjects(b, func(o primaryObject) {
+		if _, ok := byType[o.objType]; !ok {
+			types = append(types, o.objType)
+			byType[o.objType] = nil
+		}
+		inB[o.objType+"\x00"+o.handle] = &entry{obj: o}
+		bByType[o.objType] = append(bByType[o.objType], o)
+	})
+
+	d := &DatabaseDiff{}
+	for _, t := range types {
+		for _, oa := range byType[t] {
+			e, ok := inB[t+"\x00"+oa.handle]
+			if !ok {
+				d.Objects = append(d.Objects, newObjectDiff(oa, DiffRemoved))
+				continue
+			}
+			e.seen = true
+			var changes []FieldChange
+			diffValues("", reflect.ValueOf(oa.value), reflect.ValueOf(e.obj.value), &changes)
+			if len(changes) > 0 {
+				od := newObjectDiff(e.obj, DiffModified)
+				od.Changes = changes
+				d.Objects = append(d.Objects, od)
+			}
+		}
+		for _, ob := range bByType[t] {
+			if !inB[t+"\x00"+ob.handle].seen {
+				d.Objects = append(d.Objects, newObjectDiff(ob, DiffAdded))
+			}
+		}
+	}
+	return d
+}
+
+func newObjectDiff(o primaryObject, status string) ObjectDiff {
+	od := ObjectDiff{Type: o.objType, Handle: o.handle, Status: status}
+	if o.id != nil {
+		od.ID = strval(*o.id)
+	}
+	return od
+}
+
+// diffValues appends the differences between a and b to changes. Either
+// value may be invalid, meaning that it is absent.
+func diffValues(path string, a, b reflect.Value, changes *[]FieldChange) {
+	a, b = derefValue(a), derefValue(b)
+	if !a.IsValid() && !b.IsValid() {
+		return
+	}
+	t := b.Type()
+	if a.IsValid() {
+		t = a.Type()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name := f.Name
+			if path != "" {
+				name = path + "." + name
+			}
+			diffValues(name, fieldValue(a, i), fieldValue(b, i), changes)
+		}
+	case reflect.Slice:
+		for _, p := range matchElements(a, b) {
+			i := p[1]
+			if i < 0 {
+				i = p[0]
+			}
+			diffValues(path+"["+strconv.Itoa(i)+"]", indexValue(a, p[0]), indexValue(b, p[1]), changes)
+		}
+	default:
+		va, vb := leafValue(a), leafValue(b)
+		if va != vb {
+			*changes = append(*changes, FieldChange{Path: path, Old: va, New: vb})
+		}
+	}
+}
+
+// derefValue follows pointers and interfaces, returning an invalid value
+// for nil.
+func derefValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// matchElements pairs the indexes of the elements of the lists a and b, using
+// -1 for an element that has no partner. Elements whose type has an Hlink
+// field are matched if they refer to the same object and others if they are
+// equal; the elements that remain are paired in order. The pairs are in the
+// order of the elements of a, followed by the unpaired elements of b.
+func matchElements(a, b reflect.Value) [][2]int {
+	na, nb := sliceLen(a), sliceLen(b)
+	if na == 0 || nb == 0 {
+		pairs := make([][2]int, 0, na+nb)
+		for i := range na {
+			pairs = append(pairs, [2]int{i, -1})
+		}
+		for j := range nb {
+			pairs = append(pairs, [2]int{-1, j})
+		}
+		return pairs
+	}
+
+	same := func(x, y reflect.Value) bool {
+		return reflect.DeepEqual(x.Interface(), y.Interface())
+	}
+	if et := a.Type().Elem(); et.Kind() == reflect.Struct {
+		if f, ok := et.FieldByName("Hlink"); ok && f.Type.Kind() == reflect.String {
+			same = func(x, y reflect.Value) bool {
+				return x.FieldByIndex(f.Index).String() == y.FieldByIndex(f.Index).String()
+			}
+		}
+	}
+
+	partner := make([]int, na)
+	used := make([]bool, nb)
+	for i := range na {
+		partner[i] = -1
+		for j := range nb {
+			if !used[j] && same(a.Index(i), b.Index(j)) {
+				partner[i], used[j] = j, true
+				break
+			}
+		}
+	}
+	j := 0
+	for i := range na {
+		if partner[i] >= 0 {
+			continue
+		}
+		for j < nb && used[j] {
+			j++
+		}
+		if j < nb {
+			partner[i], used[j] = j, true
+		}
+	}
+
+	pairs := make([][2]int, 0, max(na, nb))
+	for i := range na {
+		pairs = append(pairs, [2]int{i, partner[i]})
+	}
+	for j := range nb {
+		if !used[j] {
+			pairs = append(pairs, [2]int{-1, j})
+		}
+	}
+	return pairs
+}
+
+func fieldValue(v reflect.Value, i int) reflect.Value {
+	if !v.IsValid() {
+		return reflect.Value{}
+	}
+	return v.Field(i)
+}
+
+func sliceLen(v reflect.Value) int {
+	if !v.IsValid() {
+		return 0
+	}
+	return v.Len()
+}
+
+func indexValue(v reflect.Value, i int) reflect.Value {
+	if !v.IsValid() || i < 0 || i >= v.Len() {
+		return reflect.Value{}
+	}
+	return v.Index(i)
+}
+
+func leafValue(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// objectTypeLabels are the names of object types used in text output.
+var objectTypeLabels = map[string]string{
+	targetPerson:     "Person",
+	targetFamily:     "Family",
+	targetEvent:      "Event",
+	targetPlace:      "Place",
+	targetSource:     "Source",
+	targetCitation:   "Citation",
+	targetMedia:      "Media",
+	targetRepository: "Repository",
+	targetNote:       "Note",
+	targetTag:        "Tag",
+	"dnatest":        "DNATest",
+	"dnamatch":       "DNAMatch",
+}
+
+// String returns the changes to the object as text, with one line for each
+// changed field, such as
+//
+//	Person I0012: Name[0].First "Jon" -> "John"
+//
+// Added and removed objects are described on a single line.
+func (od ObjectDiff) String() string {
+	name := objectTypeLabels[od.Type]
+	if name == "" {
+		name = od.Type
+	}
+	name += " " + cmp.Or(od.ID, od.Handle)
+	if od.Status != DiffModified {
+		return name + ": " + od.Status
+	}
+	var b strings.Builder
+	for i, c := range od.Changes {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%s: %s %s -> %s", name, c.Path, formatDiffValue(c.Old), formatDiffValue(c.New))
+	}
+	return b.String()
+}
+
+// WriteTo writes the differences as text, in the form given by
+// [ObjectDiff.String].
+func (d *DatabaseDiff) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	for _, od := range d.Objects {
+		m, err := io.WriteString(w, od.String()+"\n")
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func formatDiffValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "(none)"
+	case string:
+		return strconv.Quote(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
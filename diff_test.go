@@ -0,0 +1,155 @@
+package grampsxml
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiff(t *testing.T) {
+	a := &Database{
+		Events: &Events{
+			Event: []Event{
+				{Handle: "_e1", ID: new("E0001"), Type: new("Birth")},
+				{Handle: "_e2", ID: new("E0002"), Type: new("Death")},
+			},
+		},
+		People: &People{
+			Person: []Person{
+				{Handle: "_i1", ID: new("I0012"), Gender: "M", Name: []Name{{First: new("Jon")}}, Eventref: []Eventref{{Hlink: "_e1"}}},
+				{Handle: "_i2", ID: new("I0013"), Gender: "F"},
+			},
+		},
+	}
+	b := &Database{
+		People: &People{
+			Person: []Person{
+				{Handle: "_i2", ID: new("I0013"), Gender: "F"},
+				{
+					Handle:   "_i1",
+					ID:       new("I0012"),
+					Gender:   "M",
+					Name:     []Name{{First: new("John")}, {First: new("Johnny"), Alt: new(true)}},
+					Eventref: []Eventref{{Hlink: "_e1"}},
+				},
+			},
+		},
+		Events: &Events{
+			Event: []Event{
+				{Handle: "_e1", ID: new("E0001"), Type: new("Birth"), Dateval: &Dateval{Val: "1850"}},
+			},
+		},
+		Notes: &Notes{Note: []Note{{Handle: "_n1", ID: new("N0001"), Type: "General", Text: "hello"}}},
+	}
+
+	got := Diff(a, b)
+	want := &DatabaseDiff{
+		Objects: []ObjectDiff{
+			{
+				Type: "event", Handle: "_e1", ID: "E0001", Status: DiffModified,
+				Changes: []FieldChange{{Path: "Dateval.Val", New: "1850"}},
+			},
+			{Type: "event", Handle: "_e2", ID: "E0002", Status: DiffRemoved},
+			{
+				Type: "person", Handle: "_i1", ID: "I0012", Status: DiffModified,
+				Changes: []FieldChange{
+					{Path: "Name[0].First", Old: "Jon", New: "John"},
+					{Path: "Name[1].Alt", New: true},
+					{Path: "Name[1].First", New: "Johnny"},
+				},
+			},
+			{Type: "note", Handle: "_n1", ID: "N0001", Status: DiffAdded},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	var buf bytes.Buffer
+	if _, err := got.WriteTo(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantText := `Event E0001: Dateval.Val (none) -> "1850"
+Event E0002: removed
+Person I0012: Name[0].First "Jon" -> "John"
+Person I0012: Name[1].Alt (none) -> true
+Person I0012: Name[1].First (none) -> "Johnny"
+Note N0001: added
+`
+	if diff := cmp.Diff(wantText, buf.String()); diff != "" {
+		t.Errorf("text mismatch (-want +got):\n%s", diff)
+	}
+
+	data, err := json.Marshal(got.Objects[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantJSON := `{"type":"event","handle":"_e1","id":"E0001","status":"modified","changes":[{"path":"Dateval.Val","old":null,"new":"1850"}]}`
+	if string(data) != wantJSON {
+		t.Errorf("got JSON %s, want %s", data, wantJSON)
+	}
+}
+
+func TestDiffEqual(t *testing.T) {
+	a := &Database{People: &People{Person: []Person{{Handle: "_i1", Gender: "M", Name: []Name{{First: new("Jon")}}}}}}
+	b := &Database{People: &People{Person: []Person{{Handle: "_i1", Gender: "M", Name: []Name{{First: new("Jon")}}}}}}
+	if got := Diff(a, b); len(got.Objects) != 0 {
+		t.Errorf("expected no differences, got %v", got.Objects)
+	}
+}
+
+func TestDiffReordered(t *testing.T) {
+	a := &Database{
+		People: &People{
+			Person: []Person{{
+				Handle:    "_i1",
+				Name:      []Name{{First: new("Jon")}, {First: new("Johnny"), Alt: new(true)}},
+				Eventref:  []Eventref{{Hlink: "_e1"}, {Hlink: "_e2"}, {Hlink: "_e3", Role: new("Witness")}},
+				Attribute: []Attribute{{Type: "Occupation", Value: "Miller"}, {Type: "Nickname", Value: "Jack"}},
+				Noteref:   []Noteref{{Hlink: "_n1"}, {Hlink: "_n2"}},
+			}},
+		},
+		Families: &Families{
+			Family: []Family{{Handle: "_f1", Childref: []Childref{{Hlink: "_i2"}, {Hlink: "_i3", Mrel: new("Stepchild")}}}},
+		},
+	}
+	b := &Database{
+		People: &People{
+			Person: []Person{{
+				Handle:    "_i1",
+				Name:      []Name{{First: new("Johnny"), Alt: new(true)}, {First: new("Jon")}},
+				Eventref:  []Eventref{{Hlink: "_e3", Role: new("Witness")}, {Hlink: "_e1"}, {Hlink: "_e2"}},
+				Attribute: []Attribute{{Type: "Nickname", Value: "Jack"}, {Type: "Occupation", Value: "Miller"}},
+				Noteref:   []Noteref{{Hlink: "_n2"}, {Hlink: "_n1"}},
+			}},
+		},
+		Families: &Families{
+			Family: []Family{{Handle: "_f1", Childref: []Childref{{Hlink: "_i3", Mrel: new("Stepchild")}, {Hlink: "_i2"}}}},
+		},
+	}
+	if got := Diff(a, b); len(got.Objects) != 0 {
+		t.Errorf("expected no differences, got %v", got.Objects)
+	}
+
+	// A reordered reference whose other fields changed is compared with the
+	// reference to the same object, and new and removed elements are
+	// reported at their own positions.
+	b.People.Person[0].Eventref[0].Role = new("Primary")
+	b.People.Person[0].Noteref = []Noteref{{Hlink: "_n3"}, {Hlink: "_n1"}}
+	b.People.Person[0].Attribute = append(b.People.Person[0].Attribute, Attribute{Type: "Religion", Value: "Quaker"})
+	got := Diff(a, b)
+	want := []FieldChange{
+		{Path: "Eventref[0].Role", Old: "Witness", New: "Primary"},
+		{Path: "Attribute[2].Type", New: "Religion"},
+		{Path: "Attribute[2].Value", New: "Quaker"},
+		{Path: "Noteref[0].Hlink", Old: "_n2", New: "_n3"},
+	}
+	if len(got.Objects) != 1 {
+		t.Fatalf("got %d objects, wanted 1: %v", len(got.Objects), got.Objects)
+	}
+	if diff := cmp.Diff(want, got.Objects[0].Changes); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,18 @@
+// Package grampsxml provides Go types for the XML data exported by Gramps,
+// an application for managing genealogical data, along with functions for
+// reading, querying, editing and converting it.
+//
+// # Gramps JSON
+//
+// [WriteGrampsJSON] and [ReadGrampsJSON] convert a database to and from the
+// JSON form used by Gramps 5.2 and Gramps Web. Most data survives a round
+// trip through Gramps JSON unchanged, with these exceptions:
+//
+//   - Gramps JSON has no field for the cause of an event, so it is written
+//     as the last attribute of the event, with type Cause. When reading, a
+//     public Cause attribute without citations in that position becomes the
+//     event's cause, even if it was an attribute to begin with.
+//   - The citation_list of an event reference and the note_list of an
+//     attribute have no counterpart in the XML types. They are written as
+//     empty lists and ignored when reading.
+package grampsxml
@@ -0,0 +1,295 @@
+package grampsxml
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// GraphOptions selects the people and families included in a relationship
+// graph.
+type GraphOptions struct {
+	// Root is the handle of the person the graph is centred on. If empty,
+	// every person and family in the database is included and the remaining
+	// options are ignored.
+	Root string
+
+	// Ancestors includes the parents of Root, their parents and so on.
+	Ancestors bool
+
+	// Descendants includes the children of Root, their children and so on,
+	// along with the other parent of each family.
+	Descendants bool
+
+	// Generations limits the number of generations followed from Root in
+	// each direction, so that 1 includes only parents and children. Zero
+	// means no limit.
+	Generations int
+}
+
+// graphSelection holds the handles of the people and families included in a
+// graph.
+type graphSelection struct {
+	people   map[string]bool
+	families map[string]bool
+}
+
+func (o *GraphOptions) selection(ix *Index, db *Database) (*graphSelection, error) {
+	sel := &graphSelection{people: make(map[string]bool), families: make(map[string]bool)}
+	if o.Root == "" {
+		people := db.People.list()
+		for i := range people {
+			sel.people[people[i].Handle] = true
+		}
+		families := db.Families.list()
+		for i := range families {
+			sel.families[families[i].Handle] = true
+		}
+		return sel, nil
+	}
+
+	root := ix.Person(o.Root)
+	if root == nil {
+		return nil, fmt.Errorf("grampsxml: root person %q not found", o.Root)
+	}
+	sel.people[root.Handle] = true
+	within := func(gen int) bool { return o.Generations == 0 || gen < o.Generations }
+
+	type step struct {
+		p   *Person
+		gen int
+	}
+	if o.Ancestors {
+		seen := map[string]bool{root.Handle: true}
+		queue := []step{{root, 0}}
+		for len(queue) > 0 {
+			s := queue[0]
+			queue = queue[1:]
+			if !within(s.gen) {
+				continue
+			}
+			for _, c := range s.p.Childof {
+				f := ix.Family(c.Hlink)
+				if f == nil {
+					continue
+				}
+				sel.families[f.Handle] = true
+				for _, h := range familyParents(f) {
+					if p := ix.Person(h); p != nil && !seen[h] {
+						seen[h] = true
+						sel.people[h] = true
+						queue = append(queue, step{p, s.gen + 1})
+					}
+				}
+			}
+		}
+	}
+	if o.Descendants {
+		seen := map[string]bool{root.Handle: true}
+		queue := []step{{root, 0}}
+		for len(queue) > 0 {
+			s := queue[0]
+			queue = queue[1:]
+			if !within(s.gen) {
+				continue
+			}
+			for _, pi := range s.p.Parentin {
+				f := ix.Family(pi.Hlink)
+				if f == nil {
+					continue
+				}
+				sel.families[f.Handle] = true
+				for _, h := range familyParents(f) {
+					if ix.Person(h) != nil {
+						sel.people[h] = true
+					}
+				}
+				for _, cr := range f.Childref {
+					if p := ix.Person(cr.Hlink); p != nil && !seen[cr.Hlink] {
+						seen[cr.Hlink] = true
+						sel.people[cr.Hlink] = true
+						queue = append(queue, step{p, s.gen + 1})
+					}
+				}
+			}
+		}
+	}
+	return sel, nil
+}
+
+// familyParents returns the handles of the father and mother of f, omitting
+// any that are absent.
+func familyParents(f *Family) []string {
+	var hs []string
+	if f.Father != nil && f.Father.Hlink != "" {
+		hs = append(hs, f.Father.Hlink)
+	}
+	if f.Mother != nil && f.Mother.Hlink != "" {
+		hs = append(hs, f.Mother.Hlink)
+	}
+	return hs
+}
+
+// primaryName returns the name of p that is not marked as an alternate, or
+// nil if p has no names.
+func primaryName(p *Person) *Name {
+	for i := range p.Name {
+		if p.Name[i].Alt == nil || !*p.Name[i].Alt {
+			return &p.Name[i]
+		}
+	}
+	if len(p.Name) > 0 {
+		return &p.Name[0]
+	}
+	return nil
+}
+
+// fullName formats n with the given names first, followed by each surname
+// with its prefix and connector, and the suffix.
+func fullName(n *Name) string {
+	if n == nil {
+		return ""
+	}
+	var parts []string
+	add := func(s string) {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	add(strval(n.First))
+	for _, s := range n.Surname {
+		add(strval(s.Prefix))
+		add(s.Surname)
+		add(strval(s.Connector))
+	}
+	add(strval(n.Suffix))
+	return strings.Join(parts, " ")
+}
+
+// tagColor returns the colour of the highest priority tag referred to by
+// refs that has a colour, converted to the #rrggbb form, or "" if there is
+// none. As in Gramps, lower priority values take precedence.
+func tagColor(ix *Index, refs []Tagref) string {
+	var color string
+	best := -1
+	for _, tr := range refs {
+		t := ix.Tag(tr.Hlink)
+		if t == nil || t.Color == "" {
+			continue
+		}
+		pri, _ := strconv.Atoi(t.Priority)
+		if best < 0 || pri < best {
+			best, color = pri, rgbColor(t.Color)
+		}
+	}
+	return color
+}
+
+// rgbColor converts a Gramps colour, which uses four hex digits for each
+// component, to the common two digit form.
+func rgbColor(c string) string {
+	if len(c) == 13 && c[0] == '#' {
+		return "#" + c[1:3] + c[5:7] + c[9:11]
+	}
+	return c
+}
+
+// childRelStyle returns the Graphviz edge style used for a child with the
+// given relationships to its father and mother: solid for birth, dashed for
+// adoption and dotted for any other relationship. When the two differ the
+// style of the less direct relationship is used.
+func childRelStyle(frel, mrel *string) string {
+	rank := func(rel *string) int {
+		switch strval(rel) {
+		case "", "Birth":
+			return 0
+		case "Adopted":
+			return 1
+		default:
+			return 2
+		}
+	}
+	return [...]string{"solid", "dashed", "dotted"}[max(rank(frel), rank(mrel))]
+}
+
+// dotQuote returns s as a quoted Graphviz string, with newlines written as
+// the \n escape that Graphviz uses for centred line breaks.
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// WriteDOT writes a Graphviz DOT description of the relationships between
+// the people and families in db that are selected by opts.
+//
+// People are drawn as boxes labelled with their primary name and their
+// birth and death dates, filled with the colour of their highest priority
+// coloured tag. Families are drawn as ellipses labelled with the date of
+// the marriage, with edges from each parent to the family and from the
+// family to each child. Child edges are styled according to the child's
+// relationship to its parents as described by childRelStyle: solid for
+// birth, dashed for adoption and dotted otherwise.
+func WriteDOT(w io.Writer, db *Database, opts GraphOptions) error {
+	ix := NewIndex(db)
+	sel, err := opts.selection(ix, db)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph family {")
+	fmt.Fprintln(bw, "\tnode [shape=box];")
+
+	people := db.People.list()
+	for i := range people {
+		p := &people[i]
+		if !sel.people[p.Handle] {
+			continue
+		}
+		lines := []string{fullName(primaryName(p))}
+		if ev := personEvent(ix, p, "Birth"); ev != nil && !ev.Date().IsZero() {
+			lines = append(lines, "b. "+ev.Date().String())
+		}
+		if ev := personEvent(ix, p, "Death"); ev != nil && !ev.Date().IsZero() {
+			lines = append(lines, "d. "+ev.Date().String())
+		}
+		attrs := "label=" + dotQuote(strings.Join(lines, "\n"))
+		if c := tagColor(ix, p.Tagref); c != "" {
+			attrs += ", style=filled, fillcolor=" + dotQuote(c)
+		}
+		fmt.Fprintf(bw, "\t%s [%s];\n", dotQuote(p.Handle), attrs)
+	}
+
+	families := db.Families.list()
+	for i := range families {
+		f := &families[i]
+		if !sel.families[f.Handle] {
+			continue
+		}
+		var label string
+		if ev := familyEvent(ix, f, "Marriage"); ev != nil && !ev.Date().IsZero() {
+			label = "m. " + ev.Date().String()
+		}
+		attrs := "shape=ellipse, label=" + dotQuote(label)
+		if c := tagColor(ix, f.Tagref); c != "" {
+			attrs += ", style=filled, fillcolor=" + dotQuote(c)
+		}
+		fmt.Fprintf(bw, "\t%s [%s];\n", dotQuote(f.Handle), attrs)
+
+		for _, h := range familyParents(f) {
+			if sel.people[h] {
+				fmt.Fprintf(bw, "\t%s -> %s [arrowhead=none];\n", dotQuote(h), dotQuote(f.Handle))
+			}
+		}
+		for _, cr := range f.Childref {
+			if sel.people[cr.Hlink] {
+				fmt.Fprintf(bw, "\t%s -> %s [style=%s];\n", dotQuote(f.Handle), dotQuote(cr.Hlink), childRelStyle(cr.Frel, cr.Mrel))
+			}
+		}
+	}
+
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
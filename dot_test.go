@@ -0,0 +1,190 @@
+package grampsxml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// graphSample has three generations: Lewis and Anna are the parents of
+// Eliza, who with Thomas has a son Arthur and an adopted daughter Mary.
+var graphSample = Database{
+	Tags: &Tags{
+		Tag: []Tag{
+			{Handle: "_t1", Name: "Researched", Color: "#00000000ffff", Priority: "1"},
+			{Handle: "_t2", Name: "ToDo", Color: "#ffff00000000", Priority: "0"},
+		},
+	},
+	Events: &Events{
+		Event: []Event{
+			{Handle: "_e1", Type: new("Birth"), Dateval: &Dateval{Val: "1855-06-21"}},
+			{Handle: "_e2", Type: new("Death"), Dateval: &Dateval{Val: "1911", Type: new("about")}},
+			{Handle: "_e3", Type: new("Marriage"), Dateval: &Dateval{Val: "1879-04-02"}},
+			{Handle: "_e4", Type: new("Birth"), Dateval: &Dateval{Val: "1880"}},
+		},
+	},
+	People: &People{
+		Person: []Person{
+			{
+				Handle:   "_i1",
+				Gender:   "M",
+				Name:     []Name{{First: new("Lewis"), Surname: []Surname{{Surname: "Garner"}}}},
+				Eventref: []Eventref{{Hlink: "_e1"}, {Hlink: "_e2"}},
+				Parentin: []Parentin{{Hlink: "_f1"}},
+			},
+			{
+				Handle:   "_i2",
+				Gender:   "F",
+				Name:     []Name{{First: new("Anna"), Surname: []Surname{{Surname: "Zieliński"}}}},
+				Parentin: []Parentin{{Hlink: "_f1"}},
+			},
+			{
+				Handle:   "_i3",
+				Gender:   "F",
+				Name:     []Name{{First: new("Eliza \"Lizzie\""), Surname: []Surname{{Surname: "Garner"}}}},
+				Eventref: []Eventref{{Hlink: "_e4", Role: new("Primary")}},
+				Childof:  []Childof{{Hlink: "_f1"}},
+				Parentin: []Parentin{{Hlink: "_f2"}},
+				Tagref:   []Tagref{{Hlink: "_t1"}, {Hlink: "_t2"}},
+			},
+			{
+				Handle:   "_i4",
+				Gender:   "M",
+				Name:     []Name{{First: new("Thomas"), Surname: []Surname{{Prefix: new("de"), Surname: "Vere"}}}},
+				Parentin: []Parentin{{Hlink: "_f2"}},
+			},
+			{
+				Handle:  "_i5",
+				Gender:  "M",
+				Name:    []Name{{First: new("Arthur"), Surname: []Surname{{Prefix: new("de"), Surname: "Vere"}}}},
+				Childof: []Childof{{Hlink: "_f2"}},
+			},
+			{
+				Handle:  "_i6",
+				Gender:  "F",
+				Name:    []Name{{First: new("Mary"), Surname: []Surname{{Surname: "Hall"}}}},
+				Childof: []Childof{{Hlink: "_f2"}},
+			},
+		},
+	},
+	Families: &Families{
+		Family: []Family{
+			{
+				Handle:   "_f1",
+				Father:   &Father{Hlink: "_i1"},
+				Mother:   &Mother{Hlink: "_i2"},
+				Eventref: []Eventref{{Hlink: "_e3", Role: new("Family")}},
+				Childref: []Childref{{Hlink: "_i3"}},
+			},
+			{
+				Handle:   "_f2",
+				Father:   &Father{Hlink: "_i4"},
+				Mother:   &Mother{Hlink: "_i3"},
+				Childref: []Childref{{Hlink: "_i5", Frel: new("Birth"), Mrel: new("Birth")}, {Hlink: "_i6", Frel: new("Stepchild"), Mrel: new("Adopted")}},
+			},
+		},
+	},
+}
+
+func TestWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDOT(&buf, &graphSample, GraphOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `digraph family {
+	node [shape=box];
+	"_i1" [label="Lewis Garner\nb. 1855-06-21\nd. about 1911"];
+	"_i2" [label="Anna Zieliński"];
+	"_i3" [label="Eliza \"Lizzie\" Garner\nb. 1880", style=filled, fillcolor="#ff0000"];
+	"_i4" [label="Thomas de Vere"];
+	"_i5" [label="Arthur de Vere"];
+	"_i6" [label="Mary Hall"];
+	"_f1" [shape=ellipse, label="m. 1879-04-02"];
+	"_i1" -> "_f1" [arrowhead=none];
+	"_i2" -> "_f1" [arrowhead=none];
+	"_f1" -> "_i3" [style=solid];
+	"_f2" [shape=ellipse, label=""];
+	"_i4" -> "_f2" [arrowhead=none];
+	"_i3" -> "_f2" [arrowhead=none];
+	"_f2" -> "_i5" [style=solid];
+	"_f2" -> "_i6" [style=dotted];
+}
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGraphSelection(t *testing.T) {
+	testCases := []struct {
+		name         string
+		opts         GraphOptions
+		wantPeople   []string
+		wantFamilies []string
+	}{
+		{
+			name:       "root only",
+			opts:       GraphOptions{Root: "_i3"},
+			wantPeople: []string{"_i3"},
+		},
+		{
+			name:         "ancestors",
+			opts:         GraphOptions{Root: "_i5", Ancestors: true},
+			wantPeople:   []string{"_i1", "_i2", "_i3", "_i4", "_i5"},
+			wantFamilies: []string{"_f1", "_f2"},
+		},
+		{
+			name:         "one generation of ancestors",
+			opts:         GraphOptions{Root: "_i5", Ancestors: true, Generations: 1},
+			wantPeople:   []string{"_i3", "_i4", "_i5"},
+			wantFamilies: []string{"_f2"},
+		},
+		{
+			name:         "descendants",
+			opts:         GraphOptions{Root: "_i1", Descendants: true},
+			wantPeople:   []string{"_i1", "_i2", "_i3", "_i4", "_i5", "_i6"},
+			wantFamilies: []string{"_f1", "_f2"},
+		},
+		{
+			name:         "one generation either way",
+			opts:         GraphOptions{Root: "_i3", Ancestors: true, Descendants: true, Generations: 1},
+			wantPeople:   []string{"_i1", "_i2", "_i3", "_i4", "_i5", "_i6"},
+			wantFamilies: []string{"_f1", "_f2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sel, err := tc.opts.selection(NewIndex(&graphSample), &graphSample)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var people, families []string
+			for _, p := range graphSample.People.Person {
+				if sel.people[p.Handle] {
+					people = append(people, p.Handle)
+				}
+			}
+			for _, f := range graphSample.Families.Family {
+				if sel.families[f.Handle] {
+					families = append(families, f.Handle)
+				}
+			}
+			if diff := cmp.Diff(tc.wantPeople, people); diff != "" {
+				t.Errorf("people mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantFamilies, families); diff != "" {
+				t.Errorf("families mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteDOTUnknownRoot(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDOT(&buf, &graphSample, GraphOptions{Root: "_missing"}); err == nil {
+		t.Errorf("got no error for unknown root person")
+	}
+}
@@ -0,0 +1,409 @@
+package grampsxml
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// EditorOptions controls an [Editor].
+type EditorOptions struct {
+	// IDFormats holds the printf style patterns used to allocate Gramps IDs,
+	// as in [ImportOptions].
+	IDFormats map[string]string
+
+	// Now returns the time used to set the change time of objects. Nil means
+	// time.Now.
+	Now func() time.Time
+}
+
+// Editor adds objects to a database and links them together, keeping both
+// sides of the links between people and families consistent. It generates
+// handles and Gramps IDs for the objects it adds, and sets the change time
+// of every object it adds or changes.
+//
+// An Editor keeps track of where each object is held in the database, so
+// objects must not be added to or removed from the database other than
+// through the editor while it is in use.
+type Editor struct {
+	db      *Database
+	now     func() time.Time
+	formats map[string]string
+	ids     map[string]*idSequence // by object type
+	pos     map[string]int         // by object type and handle
+	count   map[string]int         // by object type
+}
+
+// NewEditor returns an editor for db. It returns an error if any of the
+// patterns in opts is invalid.
+func NewEditor(db *Database, opts EditorOptions) (*Editor, error) {
+	if err := checkIDFormats(opts.IDFormats); err != nil {
+		return nil, err
+	}
+	e := &Editor{
+		db:      db,
+		now:     opts.Now,
+		formats: opts.IDFormats,
+		ids:     make(map[string]*idSequence),
+		pos:     make(map[string]int),
+		count:   make(map[string]int),
+	}
+	if e.now == nil {
+		e.now = time.Now
+	}
+	walkObjects(db, func(o primaryObject) {
+		e.pos[o.objType+"\x00"+o.handle] = e.count[o.objType]
+		e.count[o.objType]++
+		if o.id != nil && *o.id != nil {
+			if s := e.sequence(o.objType); s != nil {
+				s.reserve(**o.id)
+			}
+		}
+	})
+	return e, nil
+}
+
+func (e *Editor) sequence(objType string) *idSequence {
+	if s, ok := e.ids[objType]; ok {
+		return s
+	}
+	format := e.formats[objType]
+	if format == "" {
+		format = defaultIDFormats[objType]
+	}
+	var s *idSequence
+	if format != "" {
+		s = newIDSequence(format)
+	}
+	e.ids[objType] = s
+	return s
+}
+
+// NewHandle returns a new handle in the form Gramps generates that is not
+// used by any object in the database.
+func (e *Editor) NewHandle() string {
+	for {
+		h := newHandle()
+		if !e.used(h) {
+			return h
+		}
+	}
+}
+
+func (e *Editor) used(handle string) bool {
+	for objType := range e.count {
+		if _, ok := e.pos[objType+"\x00"+handle]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// NextID returns the next free Gramps ID for objects of the given type,
+// named as in [ChangedObject], and marks it as used. It returns an empty
+// string for types that have no IDs, such as tags.
+func (e *Editor) NextID(objType string) string {
+	s := e.sequence(objType)
+	if s == nil {
+		return ""
+	}
+	return s.allocate()
+}
+
+func (e *Editor) stamp() string {
+	return changeStamp(e.now())
+}
+
+// add fills in the handle, ID and change time of a new object and adds it
+// to the database.
+func (e *Editor) add(objType string, v any, handle *string, id **string, change *string) (string, error) {
+	if *handle == "" {
+		*handle = e.NewHandle()
+	} else if _, ok := e.pos[objType+"\x00"+*handle]; ok {
+		return "", fmt.Errorf("grampsxml: %s handle %s already in use", objType, *handle)
+	}
+	if id != nil {
+		if *id == nil {
+			if next := e.NextID(objType); next != "" {
+				*id = new(next)
+			}
+		} else if s := e.sequence(objType); s != nil {
+			s.reserve(**id)
+		}
+	}
+	*change = e.stamp()
+	e.pos[objType+"\x00"+*handle] = e.count[objType]
+	e.count[objType]++
+	addObject(e.db, v)
+	return *handle, nil
+}
+
+// AddPerson adds p to the database, giving it a new handle if it has none,
+// the next free ID if it has none, and the current change time. It returns
+// the handle of the person. The references held by p are added as they are,
+// so links to families should be made with [Editor.AddChild] and
+// [Editor.AddSpouse] instead.
+func (e *Editor) AddPerson(p Person) (string, error) {
+	return e.add(targetPerson, &p, &p.Handle, &p.ID, &p.Change)
+}
+
+// AddFamily adds f to the database in the same way as [Editor.AddPerson].
+func (e *Editor) AddFamily(f Family) (string, error) {
+	return e.add(targetFamily, &f, &f.Handle, &f.ID, &f.Change)
+}
+
+// AddEvent adds ev to the database in the same way as [Editor.AddPerson].
+func (e *Editor) AddEvent(ev Event) (string, error) {
+	return e.add(targetEvent, &ev, &ev.Handle, &ev.ID, &ev.Change)
+}
+
+// AddPlace adds p to the database in the same way as [Editor.AddPerson].
+func (e *Editor) AddPlace(p Placeobj) (string, error) {
+	return e.add(targetPlace, &p, &p.Handle, &p.ID, &p.Change)
+}
+
+// AddSource adds s to the database in the same way as [Editor.AddPerson].
+func (e *Editor) AddSource(s Source) (string, error) {
+	return e.add(targetSource, &s, &s.Handle, &s.ID, &s.Change)
+}
+
+// AddCitation adds c to the database in the same way as [Editor.AddPerson].
+func (e *Editor) AddCitation(c Citation) (string, error) {
+	return e.add(targetCitation, &c, &c.Handle, &c.ID, &c.Change)
+}
+
+// AddObject adds o to the database in the same way as [Editor.AddPerson].
+func (e *Editor) AddObject(o Object) (string, error) {
+	return e.add(targetMedia, &o, &o.Handle, &o.ID, &o.Change)
+}
+
+// AddRepository adds r to the database in the same way as
+// [Editor.AddPerson].
+func (e *Editor) AddRepository(r Repository) (string, error) {
+	return e.add(targetRepository, &r, &r.Handle, &r.ID, &r.Change)
+}
+
+// AddNote adds n to the database in the same way as [Editor.AddPerson].
+func (e *Editor) AddNote(n Note) (string, error) {
+	return e.add(targetNote, &n, &n.Handle, &n.ID, &n.Change)
+}
+
+// AddTag adds g to the database in the same way as [Editor.AddPerson].
+// Tags have no IDs.
+func (e *Editor) AddTag(g Tag) (string, error) {
+	return e.add(targetTag, &g, &g.Handle, nil, &g.Change)
+}
+
+func (e *Editor) person(handle string) (*Person, error) {
+	i, ok := e.pos[targetPerson+"\x00"+handle]
+	if !ok {
+		return nil, fmt.Errorf("grampsxml: person %s not found", handle)
+	}
+	return &e.db.People.Person[i], nil
+}
+
+func (e *Editor) family(handle string) (*Family, error) {
+	i, ok := e.pos[targetFamily+"\x00"+handle]
+	if !ok {
+		return nil, fmt.Errorf("grampsxml: family %s not found", handle)
+	}
+	return &e.db.Families.Family[i], nil
+}
+
+// AddChild adds the person with the handle child to the children of family,
+// and family to the families the person is a child of.
+func (e *Editor) AddChild(family, child string) error {
+	f, err := e.family(family)
+	if err != nil {
+		return err
+	}
+	p, err := e.person(child)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(familyParents(f), child) {
+		return fmt.Errorf("grampsxml: person %s is a parent in family %s", child, family)
+	}
+	stamp := e.stamp()
+	if !slices.ContainsFunc(f.Childref, func(r Childref) bool { return r.Hlink == child }) {
+		f.Childref = append(f.Childref, Childref{Hlink: child})
+		f.Change = stamp
+	}
+	if !slices.ContainsFunc(p.Childof, func(r Childof) bool { return r.Hlink == family }) {
+		p.Childof = append(p.Childof, Childof{Hlink: family})
+		p.Change = stamp
+	}
+	return nil
+}
+
+// RemoveChild removes the person with the handle child from the children of
+// family, and family from the families the person is a child of.
+func (e *Editor) RemoveChild(family, child string) error {
+	f, err := e.family(family)
+	if err != nil {
+		return err
+	}
+	p, err := e.person(child)
+	if err != nil {
+		return err
+	}
+	stamp := e.stamp()
+	if i := slices.IndexFunc(f.Childref, func(r Childref) bool { return r.Hlink == child }); i >= 0 {
+		f.Childref = slices.Delete(f.Childref, i, i+1)
+		f.Change = stamp
+	}
+	if i := slices.IndexFunc(p.Childof, func(r Childof) bool { return r.Hlink == family }); i >= 0 {
+		p.Childof = slices.Delete(p.Childof, i, i+1)
+		p.Change = stamp
+	}
+	return nil
+}
+
+// AddSpouse makes the person with the handle spouse a parent in family, and
+// adds family to the families the person is a parent in. Men become the
+// father and women the mother of the family. People of other genders take
+// whichever place is free, trying the father first. It is an error if the
+// place is already taken by someone else.
+func (e *Editor) AddSpouse(family, spouse string) error {
+	f, err := e.family(family)
+	if err != nil {
+		return err
+	}
+	p, err := e.person(spouse)
+	if err != nil {
+		return err
+	}
+	if slices.ContainsFunc(f.Childref, func(r Childref) bool { return r.Hlink == spouse }) {
+		return fmt.Errorf("grampsxml: person %s is a child in family %s", spouse, family)
+	}
+	stamp := e.stamp()
+	if !slices.Contains(familyParents(f), spouse) {
+		asFather := f.Father == nil || f.Father.Hlink == ""
+		switch Gender(p.Gender) {
+		case GenderMale:
+			asFather = true
+		case GenderFemale:
+			asFather = false
+		}
+		switch {
+		case asFather && (f.Father == nil || f.Father.Hlink == ""):
+			f.Father = &Father{Hlink: spouse}
+		case !asFather && (f.Mother == nil || f.Mother.Hlink == ""):
+			f.Mother = &Mother{Hlink: spouse}
+		default:
+			return fmt.Errorf("grampsxml: family %s already has a parent in place of %s", family, spouse)
+		}
+		f.Change = stamp
+	}
+	if !slices.ContainsFunc(p.Parentin, func(r Parentin) bool { return r.Hlink == family }) {
+		p.Parentin = append(p.Parentin, Parentin{Hlink: family})
+		p.Change = stamp
+	}
+	return nil
+}
+
+// RemoveSpouse removes the person with the handle spouse from the parents
+// of family, and family from the families the person is a parent in.
+func (e *Editor) RemoveSpouse(family, spouse string) error {
+	f, err := e.family(family)
+	if err != nil {
+		return err
+	}
+	p, err := e.person(spouse)
+	if err != nil {
+		return err
+	}
+	stamp := e.stamp()
+	if f.Father != nil && f.Father.Hlink == spouse {
+		f.Father = nil
+		f.Change = stamp
+	}
+	if f.Mother != nil && f.Mother.Hlink == spouse {
+		f.Mother = nil
+		f.Change = stamp
+	}
+	if i := slices.IndexFunc(p.Parentin, func(r Parentin) bool { return r.Hlink == family }); i >= 0 {
+		p.Parentin = slices.Delete(p.Parentin, i, i+1)
+		p.Change = stamp
+	}
+	return nil
+}
+
+// AttachEvent adds a reference to event in the given role to the person or
+// family with the given handle, where objType is "person" or "family". An
+// empty role means RolePrimary for people and RoleFamily for families. The
+// reference is not added if the object already refers to the event in that
+// role.
+func (e *Editor) AttachEvent(objType, handle, event string, role EventRole) error {
+	if _, ok := e.pos[targetEvent+"\x00"+event]; !ok {
+		return fmt.Errorf("grampsxml: event %s not found", event)
+	}
+	var refs *[]Eventref
+	var change *string
+	switch objType {
+	case targetPerson:
+		p, err := e.person(handle)
+		if err != nil {
+			return err
+		}
+		refs, change = &p.Eventref, &p.Change
+		role = cmp.Or(role, RolePrimary)
+	case targetFamily:
+		f, err := e.family(handle)
+		if err != nil {
+			return err
+		}
+		refs, change = &f.Eventref, &f.Change
+		role = cmp.Or(role, RoleFamily)
+	default:
+		return fmt.Errorf("grampsxml: cannot attach an event to a %s", objType)
+	}
+	if slices.ContainsFunc(*refs, func(r Eventref) bool { return r.Hlink == event && strval(r.Role) == string(role) }) {
+		return nil
+	}
+	*refs = append(*refs, Eventref{Hlink: event, Role: new(string(role))})
+	*change = e.stamp()
+	return nil
+}
+
+// AttachCitation adds a reference to citation to the person, family, event,
+// place or media object with the given handle, where objType names the
+// type as in [ChangedObject]. The reference is not added if the object
+// already refers to the citation.
+func (e *Editor) AttachCitation(objType, handle, citation string) error {
+	if _, ok := e.pos[targetCitation+"\x00"+citation]; !ok {
+		return fmt.Errorf("grampsxml: citation %s not found", citation)
+	}
+	i, ok := e.pos[objType+"\x00"+handle]
+	if !ok {
+		return fmt.Errorf("grampsxml: %s %s not found", objType, handle)
+	}
+	var refs *[]Citationref
+	var change *string
+	switch objType {
+	case targetPerson:
+		p := &e.db.People.Person[i]
+		refs, change = &p.Citationref, &p.Change
+	case targetFamily:
+		f := &e.db.Families.Family[i]
+		refs, change = &f.Citationref, &f.Change
+	case targetEvent:
+		ev := &e.db.Events.Event[i]
+		refs, change = &ev.Citationref, &ev.Change
+	case targetPlace:
+		p := &e.db.Places.Place[i]
+		refs, change = &p.Citationref, &p.Change
+	case targetMedia:
+		o := &e.db.Objects.Object[i]
+		refs, change = &o.Citationref, &o.Change
+	default:
+		return fmt.Errorf("grampsxml: cannot attach a citation to a %s", objType)
+	}
+	if slices.ContainsFunc(*refs, func(r Citationref) bool { return r.Hlink == citation }) {
+		return nil
+	}
+	*refs = append(*refs, Citationref{Hlink: citation})
+	*change = e.stamp()
+	return nil
+}
@@ -0,0 +1,155 @@
+package grampsxml
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEditor(t *testing.T) {
+	db := &Database{
+		People: &People{Person: []Person{{Handle: "_i1", ID: new("I0000"), Gender: "M"}}},
+	}
+	now := time.Unix(1700000000, 0)
+	e, err := NewEditor(db, EditorOptions{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("NewEditor: %v", err)
+	}
+
+	mother, err := e.AddPerson(Person{Gender: "F"})
+	if err != nil {
+		t.Fatalf("AddPerson: %v", err)
+	}
+	child, _ := e.AddPerson(Person{Handle: "_child", Gender: "U"})
+	family, _ := e.AddFamily(Family{})
+	birth, _ := e.AddEvent(Event{Type: new("Birth")})
+	marriage, _ := e.AddEvent(Event{Type: new("Marriage")})
+	citation, _ := e.AddCitation(Citation{})
+	if _, err := e.AddPerson(Person{Handle: "_i1"}); err == nil {
+		t.Errorf("AddPerson with a handle in use: expected error")
+	}
+
+	if !strings.HasPrefix(mother, "_") {
+		t.Errorf("generated handle %q not in Gramps form", mother)
+	}
+	for _, err := range []error{
+		e.AddSpouse(family, "_i1"),
+		e.AddSpouse(family, mother),
+		e.AddChild(family, child),
+		e.AddChild(family, child),
+		e.AttachEvent(targetPerson, child, birth, ""),
+		e.AttachEvent(targetFamily, family, marriage, ""),
+		e.AttachEvent(targetPerson, "_i1", marriage, RoleWitness),
+		e.AttachCitation(targetEvent, birth, citation),
+		e.AttachCitation(targetPerson, child, citation),
+	} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	stamp := "1700000000"
+	want := &Database{
+		People: &People{Person: []Person{
+			{
+				Handle: "_i1", ID: new("I0000"), Change: stamp, Gender: "M",
+				Eventref: []Eventref{{Hlink: marriage, Role: new("Witness")}},
+				Parentin: []Parentin{{Hlink: family}},
+			},
+			{Handle: mother, ID: new("I0001"), Change: stamp, Gender: "F", Parentin: []Parentin{{Hlink: family}}},
+			{
+				Handle: "_child", ID: new("I0002"), Change: stamp, Gender: "U",
+				Eventref:    []Eventref{{Hlink: birth, Role: new("Primary")}},
+				Childof:     []Childof{{Hlink: family}},
+				Citationref: []Citationref{{Hlink: citation}},
+			},
+		}},
+		Families: &Families{Family: []Family{{
+			Handle: family, ID: new("F0000"), Change: stamp,
+			Father:   &Father{Hlink: "_i1"},
+			Mother:   &Mother{Hlink: mother},
+			Eventref: []Eventref{{Hlink: marriage, Role: new("Family")}},
+			Childref: []Childref{{Hlink: child}},
+		}}},
+		Events: &Events{Event: []Event{
+			{Handle: birth, ID: new("E0000"), Change: stamp, Type: new("Birth"), Citationref: []Citationref{{Hlink: citation}}},
+			{Handle: marriage, ID: new("E0001"), Change: stamp, Type: new("Marriage")},
+		}},
+		Citations: &Citations{Citation: []Citation{{Handle: citation, ID: new("C0000"), Change: stamp}}},
+	}
+	if diff := cmp.Diff(want, db); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := e.RemoveChild(family, child); err != nil {
+		t.Fatalf("RemoveChild: %v", err)
+	}
+	if err := e.RemoveSpouse(family, mother); err != nil {
+		t.Fatalf("RemoveSpouse: %v", err)
+	}
+	f := &db.Families.Family[0]
+	if len(f.Childref) != 0 || f.Mother != nil || len(db.People.Person[2].Childof) != 0 || len(db.People.Person[1].Parentin) != 0 {
+		t.Errorf("links not removed: family %+v", f)
+	}
+}
+
+func TestEditorErrors(t *testing.T) {
+	db := &Database{
+		People: &People{Person: []Person{
+			{Handle: "_i1", Gender: "M", Parentin: []Parentin{{Hlink: "_f1"}}},
+			{Handle: "_i2", Gender: "M"},
+			{Handle: "_i3", Gender: "F", Childof: []Childof{{Hlink: "_f1"}}},
+		}},
+		Families: &Families{Family: []Family{
+			{Handle: "_f1", Father: &Father{Hlink: "_i1"}, Childref: []Childref{{Hlink: "_i3"}}},
+		}},
+		Notes: &Notes{Note: []Note{{Handle: "_n1"}}},
+	}
+	e, err := NewEditor(db, EditorOptions{})
+	if err != nil {
+		t.Fatalf("NewEditor: %v", err)
+	}
+	testCases := []struct {
+		name string
+		fn   func() error
+	}{
+		{name: "missing family", fn: func() error { return e.AddChild("_fx", "_i2") }},
+		{name: "missing person", fn: func() error { return e.AddSpouse("_f1", "_ix") }},
+		{name: "father taken", fn: func() error { return e.AddSpouse("_f1", "_i2") }},
+		{name: "child as spouse", fn: func() error { return e.AddSpouse("_f1", "_i3") }},
+		{name: "parent as child", fn: func() error { return e.AddChild("_f1", "_i1") }},
+		{name: "missing event", fn: func() error { return e.AttachEvent(targetPerson, "_i1", "_ex", "") }},
+		{name: "event on note", fn: func() error { return e.AttachEvent(targetNote, "_n1", "_ex", "") }},
+		{name: "missing citation", fn: func() error { return e.AttachCitation(targetPerson, "_i1", "_cx") }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.fn(); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func TestEditorIDFormats(t *testing.T) {
+	db := &Database{
+		People: &People{Person: []Person{{Handle: "_i1", ID: new("P-1")}}},
+	}
+	e, err := NewEditor(db, EditorOptions{IDFormats: map[string]string{targetPerson: "P-%d"}})
+	if err != nil {
+		t.Fatalf("NewEditor: %v", err)
+	}
+	got := []string{e.NextID(targetPerson), e.NextID(targetPerson), e.NextID(targetFamily), e.NextID(targetTag)}
+	want := []string{"P-0", "P-2", "F0000", ""}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewEditorInvalidFormat(t *testing.T) {
+	if _, err := NewEditor(&Database{}, EditorOptions{IDFormats: map[string]string{targetPerson: "I"}}); err == nil {
+		t.Errorf("expected error")
+	}
+}
@@ -0,0 +1,533 @@
+package grampsxml
+
+import "fmt"
+
+// Gender is the gender of a person, as held in [Person.Gender].
+type Gender string
+
+// Genders recognised by Gramps.
+const (
+	GenderFemale  Gender = "F"
+	GenderMale    Gender = "M"
+	GenderUnknown Gender = "U"
+	GenderOther   Gender = "X"
+)
+
+// ParseGender returns the gender whose XML form or English label, such as
+// "M" or "male", matches s, ignoring case. Gramps has no custom genders, so
+// any other text is an error.
+func ParseGender(s string) (Gender, error) {
+	v, ok := genderType.parse(s)
+	if !ok {
+		return "", fmt.Errorf("grampsxml: invalid gender %q", s)
+	}
+	return Gender(v), nil
+}
+
+// IsStandard reports whether g is one of the genders recognised by Gramps.
+func (g Gender) IsStandard() bool { return genderType.isStandard(string(g)) }
+
+// Label returns the English label Gramps displays for g.
+func (g Gender) Label() string { return genderType.label(string(g)) }
+
+// EventType is the type of an event, as held in [Event.Type]. Any text other
+// than the standard values is a custom event type defined by the user.
+type EventType string
+
+// Standard event types.
+const (
+	EventUnknown            EventType = "Unknown"
+	EventMarriage           EventType = "Marriage"
+	EventMarriageSettlement EventType = "Marriage Settlement"
+	EventMarriageLicense    EventType = "Marriage License"
+	EventMarriageContract   EventType = "Marriage Contract"
+	EventMarriageBanns      EventType = "Marriage Banns"
+	EventEngagement         EventType = "Engagement"
+	EventDivorce            EventType = "Divorce"
+	EventDivorceFiling      EventType = "Divorce Filing"
+	EventAnnulment          EventType = "Annulment"
+	EventAlternateMarriage  EventType = "Alternate Marriage"
+	EventAdopted            EventType = "Adopted"
+	EventBirth              EventType = "Birth"
+	EventDeath              EventType = "Death"
+	EventAdultChristening   EventType = "Adult Christening"
+	EventBaptism            EventType = "Baptism"
+	EventBarMitzvah         EventType = "Bar Mitzvah"
+	EventBasMitzvah         EventType = "Bas Mitzvah"
+	EventBlessing           EventType = "Blessing"
+	EventBurial             EventType = "Burial"
+	EventCauseOfDeath       EventType = "Cause Of Death"
+	EventCensus             EventType = "Census"
+	EventChristening        EventType = "Christening"
+	EventConfirmation       EventType = "Confirmation"
+	EventCremation          EventType = "Cremation"
+	EventDegree             EventType = "Degree"
+	EventEducation          EventType = "Education"
+	EventElected            EventType = "Elected"
+	EventEmigration         EventType = "Emigration"
+	EventFirstCommunion     EventType = "First Communion"
+	EventImmigration        EventType = "Immigration"
+	EventGraduation         EventType = "Graduation"
+	EventMedicalInformation EventType = "Medical Information"
+	EventMilitaryService    EventType = "Military Service"
+	EventNaturalization     EventType = "Naturalization"
+	EventNobilityTitle      EventType = "Nobility Title"
+	EventNumberOfMarriages  EventType = "Number of Marriages"
+	EventOccupation         EventType = "Occupation"
+	EventOrdination         EventType = "Ordination"
+	EventProbate            EventType = "Probate"
+	EventProperty           EventType = "Property"
+	EventReligion           EventType = "Religion"
+	EventResidence          EventType = "Residence"
+	EventRetirement         EventType = "Retirement"
+	EventWill               EventType = "Will"
+	EventStillbirth         EventType = "Stillbirth"
+)
+
+// ParseEventType returns the standard event type whose XML form or English
+// label matches s, ignoring case, and true. For any other text it returns s
+// as a custom event type and false.
+func ParseEventType(s string) (EventType, bool) {
+	v, ok := eventType.parse(s)
+	return EventType(v), ok
+}
+
+// IsStandard reports whether t is a standard event type rather than a custom
+// one.
+func (t EventType) IsStandard() bool { return eventType.isStandard(string(t)) }
+
+// Label returns the English label Gramps displays for t. A custom event type
+// is its own label.
+func (t EventType) Label() string { return eventType.label(string(t)) }
+
+// EventRole is the role a person or family has in an event, as held in
+// [Eventref.Role]. Any text other than the standard values is a custom event
+// role defined by the user.
+type EventRole string
+
+// Standard event roles.
+const (
+	RoleUnknown   EventRole = "Unknown"
+	RolePrimary   EventRole = "Primary"
+	RoleClergy    EventRole = "Clergy"
+	RoleCelebrant EventRole = "Celebrant"
+	RoleAide      EventRole = "Aide"
+	RoleBride     EventRole = "Bride"
+	RoleGroom     EventRole = "Groom"
+	RoleWitness   EventRole = "Witness"
+	RoleFamily    EventRole = "Family"
+	RoleInformant EventRole = "Informant"
+)
+
+// ParseEventRole returns the standard event role whose XML form or English
+// label matches s, ignoring case, and true. For any other text it returns s
+// as a custom event role and false.
+func ParseEventRole(s string) (EventRole, bool) {
+	v, ok := eventRoleType.parse(s)
+	return EventRole(v), ok
+}
+
+// IsStandard reports whether t is a standard event role rather than a custom
+// one.
+func (t EventRole) IsStandard() bool { return eventRoleType.isStandard(string(t)) }
+
+// Label returns the English label Gramps displays for t. A custom event role
+// is its own label.
+func (t EventRole) Label() string { return eventRoleType.label(string(t)) }
+
+// FamilyRelType is the relationship between the parents of a family, as held
+// in [Rel.Type]. Any text other than the standard values is a custom family
+// relationship type defined by the user.
+type FamilyRelType string
+
+// Standard family relationship types.
+const (
+	FamilyMarried    FamilyRelType = "Married"
+	FamilyUnmarried  FamilyRelType = "Unmarried"
+	FamilyCivilUnion FamilyRelType = "Civil Union"
+	FamilyUnknown    FamilyRelType = "Unknown"
+)
+
+// ParseFamilyRelType returns the standard family relationship type whose XML
+// form or English label matches s, ignoring case, and true. For any other
+// text it returns s as a custom family relationship type and false.
+func ParseFamilyRelType(s string) (FamilyRelType, bool) {
+	v, ok := familyRelType.parse(s)
+	return FamilyRelType(v), ok
+}
+
+// IsStandard reports whether t is a standard family relationship type rather
+// than a custom one.
+func (t FamilyRelType) IsStandard() bool { return familyRelType.isStandard(string(t)) }
+
+// Label returns the English label Gramps displays for t. A custom family
+// relationship type is its own label.
+func (t FamilyRelType) Label() string { return familyRelType.label(string(t)) }
+
+// ChildRel is the relationship of a child to one of the parents of a family,
+// as held in [Childref.Frel] and [Childref.Mrel]. Any text other than the
+// standard values is a custom child relationship defined by the user.
+type ChildRel string
+
+// Standard child relationships.
+const (
+	ChildNone      ChildRel = "None"
+	ChildBirth     ChildRel = "Birth"
+	ChildAdopted   ChildRel = "Adopted"
+	ChildStepchild ChildRel = "Stepchild"
+	ChildSponsored ChildRel = "Sponsored"
+	ChildFoster    ChildRel = "Foster"
+	ChildUnknown   ChildRel = "Unknown"
+)
+
+// ParseChildRel returns the standard child relationship whose XML form or
+// English label matches s, ignoring case, and true. For any other text it
+// returns s as a custom child relationship and false.
+func ParseChildRel(s string) (ChildRel, bool) {
+	v, ok := childRefType.parse(s)
+	return ChildRel(v), ok
+}
+
+// IsStandard reports whether t is a standard child relationship rather than
+// a custom one.
+func (t ChildRel) IsStandard() bool { return childRefType.isStandard(string(t)) }
+
+// Label returns the English label Gramps displays for t. A custom child
+// relationship is its own label.
+func (t ChildRel) Label() string { return childRefType.label(string(t)) }
+
+// NameType is the type of a person's name, as held in [Name.Type]. Any text
+// other than the standard values is a custom name type defined by the user.
+type NameType string
+
+// Standard name types.
+const (
+	NameUnknown     NameType = "Unknown"
+	NameAlsoKnownAs NameType = "Also Known As"
+	NameBirth       NameType = "Birth Name"
+	NameMarried     NameType = "Married Name"
+)
+
+// ParseNameType returns the standard name type whose XML form or English
+// label matches s, ignoring case, and true. For any other text it returns s
+// as a custom name type and false.
+func ParseNameType(s string) (NameType, bool) {
+	v, ok := nameType.parse(s)
+	return NameType(v), ok
+}
+
+// IsStandard reports whether t is a standard name type rather than a custom
+// one.
+func (t NameType) IsStandard() bool { return nameType.isStandard(string(t)) }
+
+// Label returns the English label Gramps displays for t. A custom name type
+// is its own label.
+func (t NameType) Label() string { return nameType.label(string(t)) }
+
+// NameOrigin is the origin of a surname, as held in [Surname.Derivation].
+// Any text other than the standard values is a custom name origin defined by
+// the user.
+type NameOrigin string
+
+// Standard name origins.
+const (
+	OriginUnknown     NameOrigin = "Unknown"
+	OriginInherited   NameOrigin = "Inherited"
+	OriginGiven       NameOrigin = "Given"
+	OriginTaken       NameOrigin = "Taken"
+	OriginPatronymic  NameOrigin = "Patronymic"
+	OriginMatronymic  NameOrigin = "Matronymic"
+	OriginFeudal      NameOrigin = "Feudal"
+	OriginPseudonym   NameOrigin = "Pseudonym"
+	OriginPatrilineal NameOrigin = "Patrilineal"
+	OriginMatrilineal NameOrigin = "Matrilineal"
+	OriginOccupation  NameOrigin = "Occupation"
+	OriginLocation    NameOrigin = "Location"
+)
+
+// ParseNameOrigin returns the standard name origin whose XML form or English
+// label matches s, ignoring case, and true. For any other text it returns s
+// as a custom name origin and false.
+func ParseNameOrigin(s string) (NameOrigin, bool) {
+	v, ok := nameOriginType.parse(s)
+	return NameOrigin(v), ok
+}
+
+// IsStandard reports whether t is a standard name origin rather than a
+// custom one.
+func (t NameOrigin) IsStandard() bool { return nameOriginType.isStandard(string(t)) }
+
+// Label returns the English label Gramps displays for t. A custom name
+// origin is its own label.
+func (t NameOrigin) Label() string { return nameOriginType.label(string(t)) }
+
+// PlaceType is the type of a place, as held in [Placeobj.Type]. Any text
+// other than the standard values is a custom place type defined by the user.
+type PlaceType string
+
+// Standard place types.
+const (
+	PlaceUnknown      PlaceType = "Unknown"
+	PlaceCountry      PlaceType = "Country"
+	PlaceState        PlaceType = "State"
+	PlaceCounty       PlaceType = "County"
+	PlaceCity         PlaceType = "City"
+	PlaceParish       PlaceType = "Parish"
+	PlaceLocality     PlaceType = "Locality"
+	PlaceStreet       PlaceType = "Street"
+	PlaceProvince     PlaceType = "Province"
+	PlaceRegion       PlaceType = "Region"
+	PlaceDepartment   PlaceType = "Department"
+	PlaceNeighborhood PlaceType = "Neighborhood"
+	PlaceDistrict     PlaceType = "District"
+	PlaceBorough      PlaceType = "Borough"
+	PlaceMunicipality PlaceType = "Municipality"
+	PlaceTown         PlaceType = "Town"
+	PlaceVillage      PlaceType = "Village"
+	PlaceHamlet       PlaceType = "Hamlet"
+	PlaceFarm         PlaceType = "Farm"
+	PlaceBuilding     PlaceType = "Building"
+	PlaceNumber       PlaceType = "Number"
+)
+
+// ParsePlaceType returns the standard place type whose XML form or English
+// label matches s, ignoring case, and true. For any other text it returns s
+// as a custom place type and false.
+func ParsePlaceType(s string) (PlaceType, bool) {
+	v, ok := placeType.parse(s)
+	return PlaceType(v), ok
+}
+
+// IsStandard reports whether t is a standard place type rather than a custom
+// one.
+func (t PlaceType) IsStandard() bool { return placeType.isStandard(string(t)) }
+
+// Label returns the English label Gramps displays for t. A custom place type
+// is its own label.
+func (t PlaceType) Label() string { return placeType.label(string(t)) }
+
+// NoteType is the type of a note, as held in [Note.Type]. Any text other
+// than the standard values is a custom note type defined by the user.
+type NoteType string
+
+// Standard note types.
+const (
+	NoteUnknown       NoteType = "Unknown"
+	NoteGeneral       NoteType = "General"
+	NoteResearch      NoteType = "Research"
+	NoteTranscript    NoteType = "Transcript"
+	NotePerson        NoteType = "Person Note"
+	NoteAttribute     NoteType = "Attribute Note"
+	NoteAddress       NoteType = "Address Note"
+	NoteAssociation   NoteType = "Association Note"
+	NoteLDS           NoteType = "LDS Note"
+	NoteFamily        NoteType = "Family Note"
+	NoteEvent         NoteType = "Event Note"
+	NoteEventRef      NoteType = "Event Reference Note"
+	NoteSource        NoteType = "Source Note"
+	NoteSourceRef     NoteType = "Source Reference Note"
+	NotePlace         NoteType = "Place Note"
+	NoteRepository    NoteType = "Repository Note"
+	NoteRepositoryRef NoteType = "Repository Reference Note"
+	NoteMedia         NoteType = "Media Note"
+	NoteMediaRef      NoteType = "Media Reference Note"
+	NoteChildRef      NoteType = "Child Reference Note"
+	NoteName          NoteType = "Name Note"
+	NoteSourceText    NoteType = "Source text"
+	NoteCitation      NoteType = "Citation"
+	NoteReport        NoteType = "Report"
+	NoteHTMLCode      NoteType = "Html code"
+	NoteToDo          NoteType = "To Do"
+	NoteLink          NoteType = "Link"
+)
+
+// ParseNoteType returns the standard note type whose XML form or English
+// label matches s, ignoring case, and true. For any other text it returns s
+// as a custom note type and false.
+func ParseNoteType(s string) (NoteType, bool) {
+	v, ok := noteType.parse(s)
+	return NoteType(v), ok
+}
+
+// IsStandard reports whether t is a standard note type rather than a custom
+// one.
+func (t NoteType) IsStandard() bool { return noteType.isStandard(string(t)) }
+
+// Label returns the English label Gramps displays for t. A custom note type
+// is its own label.
+func (t NoteType) Label() string { return noteType.label(string(t)) }
+
+// URLType is the type of a web address, as held in [Url.Type]. Any text
+// other than the standard values is a custom URL type defined by the user.
+type URLType string
+
+// Standard URL types.
+const (
+	URLUnknown   URLType = "Unknown"
+	URLEmail     URLType = "E-mail"
+	URLWebHome   URLType = "Web Home"
+	URLWebSearch URLType = "Web Search"
+	URLFTP       URLType = "FTP"
+)
+
+// ParseURLType returns the standard URL type whose XML form or English label
+// matches s, ignoring case, and true. For any other text it returns s as a
+// custom URL type and false.
+func ParseURLType(s string) (URLType, bool) {
+	v, ok := urlType.parse(s)
+	return URLType(v), ok
+}
+
+// IsStandard reports whether t is a standard URL type rather than a custom
+// one.
+func (t URLType) IsStandard() bool { return urlType.isStandard(string(t)) }
+
+// Label returns the English label Gramps displays for t. A custom URL type
+// is its own label.
+func (t URLType) Label() string { return urlType.label(string(t)) }
+
+// RepositoryType is the type of a repository, as held in [Repository.Type].
+// Any text other than the standard values is a custom repository type
+// defined by the user.
+type RepositoryType string
+
+// Standard repository types.
+const (
+	RepositoryUnknown    RepositoryType = "Unknown"
+	RepositoryLibrary    RepositoryType = "Library"
+	RepositoryCemetery   RepositoryType = "Cemetery"
+	RepositoryChurch     RepositoryType = "Church"
+	RepositoryArchive    RepositoryType = "Archive"
+	RepositoryAlbum      RepositoryType = "Album"
+	RepositoryWebSite    RepositoryType = "Web site"
+	RepositoryBookstore  RepositoryType = "Bookstore"
+	RepositoryCollection RepositoryType = "Collection"
+	RepositorySafe       RepositoryType = "Safe"
+)
+
+// ParseRepositoryType returns the standard repository type whose XML form or
+// English label matches s, ignoring case, and true. For any other text it
+// returns s as a custom repository type and false.
+func ParseRepositoryType(s string) (RepositoryType, bool) {
+	v, ok := repositoryType.parse(s)
+	return RepositoryType(v), ok
+}
+
+// IsStandard reports whether t is a standard repository type rather than a
+// custom one.
+func (t RepositoryType) IsStandard() bool { return repositoryType.isStandard(string(t)) }
+
+// Label returns the English label Gramps displays for t. A custom repository
+// type is its own label.
+func (t RepositoryType) Label() string { return repositoryType.label(string(t)) }
+
+// SourceMediaType is the type of medium a source is held on in a repository,
+// as held in [Reporef.Medium]. Any text other than the standard values is a
+// custom source media type defined by the user.
+type SourceMediaType string
+
+// Standard source media types.
+const (
+	MediaUnknown    SourceMediaType = "Unknown"
+	MediaAudio      SourceMediaType = "Audio"
+	MediaBook       SourceMediaType = "Book"
+	MediaCard       SourceMediaType = "Card"
+	MediaElectronic SourceMediaType = "Electronic"
+	MediaFiche      SourceMediaType = "Fiche"
+	MediaFilm       SourceMediaType = "Film"
+	MediaMagazine   SourceMediaType = "Magazine"
+	MediaManuscript SourceMediaType = "Manuscript"
+	MediaMap        SourceMediaType = "Map"
+	MediaNewspaper  SourceMediaType = "Newspaper"
+	MediaPhoto      SourceMediaType = "Photo"
+	MediaTombstone  SourceMediaType = "Tombstone"
+	MediaVideo      SourceMediaType = "Video"
+)
+
+// ParseSourceMediaType returns the standard source media type whose XML form
+// or English label matches s, ignoring case, and true. For any other text it
+// returns s as a custom source media type and false.
+func ParseSourceMediaType(s string) (SourceMediaType, bool) {
+	v, ok := sourceMediaType.parse(s)
+	return SourceMediaType(v), ok
+}
+
+// IsStandard reports whether t is a standard source media type rather than a
+// custom one.
+func (t SourceMediaType) IsStandard() bool { return sourceMediaType.isStandard(string(t)) }
+
+// Label returns the English label Gramps displays for t. A custom source
+// media type is its own label.
+func (t SourceMediaType) Label() string { return sourceMediaType.label(string(t)) }
+
+// AttributeType is the type of an attribute, as held in [Attribute.Type].
+// Any text other than the standard values is a custom attribute type defined
+// by the user.
+type AttributeType string
+
+// Standard attribute types.
+const (
+	AttributeUnknown          AttributeType = "Unknown"
+	AttributeCaste            AttributeType = "Caste"
+	AttributeDescription      AttributeType = "Description"
+	AttributeIDNumber         AttributeType = "Identification Number"
+	AttributeNationalOrigin   AttributeType = "National Origin"
+	AttributeNumberOfChildren AttributeType = "Number of Children"
+	AttributeSSN              AttributeType = "Social Security Number"
+	AttributeNickname         AttributeType = "Nickname"
+	AttributeCause            AttributeType = "Cause"
+	AttributeAgency           AttributeType = "Agency"
+	AttributeAge              AttributeType = "Age"
+	AttributeFatherAge        AttributeType = "Father's Age"
+	AttributeMotherAge        AttributeType = "Mother's Age"
+	AttributeWitness          AttributeType = "Witness"
+	AttributeTime             AttributeType = "Time"
+	AttributeOccupation       AttributeType = "Occupation"
+)
+
+// ParseAttributeType returns the standard attribute type whose XML form or
+// English label matches s, ignoring case, and true. For any other text it
+// returns s as a custom attribute type and false.
+func ParseAttributeType(s string) (AttributeType, bool) {
+	v, ok := attributeType.parse(s)
+	return AttributeType(v), ok
+}
+
+// IsStandard reports whether t is a standard attribute type rather than a
+// custom one.
+func (t AttributeType) IsStandard() bool { return attributeType.isStandard(string(t)) }
+
+// Label returns the English label Gramps displays for t. A custom attribute
+// type is its own label.
+func (t AttributeType) Label() string { return attributeType.label(string(t)) }
+
+// Confidence is the confidence in the accuracy of a citation, as held in
+// [Citation.Confidence].
+type Confidence string
+
+// Confidence levels recognised by Gramps.
+const (
+	ConfidenceVeryLow  Confidence = "0"
+	ConfidenceLow      Confidence = "1"
+	ConfidenceNormal   Confidence = "2"
+	ConfidenceHigh     Confidence = "3"
+	ConfidenceVeryHigh Confidence = "4"
+)
+
+// ParseConfidence returns the confidence level whose XML form or English
+// label, such as "3" or "High", matches s, ignoring case. Any other text is
+// an error.
+func ParseConfidence(s string) (Confidence, error) {
+	v, ok := confidenceType.parse(s)
+	if !ok {
+		return "", fmt.Errorf("grampsxml: invalid confidence %q", s)
+	}
+	return Confidence(v), nil
+}
+
+// IsStandard reports whether c is one of the confidence levels recognised by
+// Gramps.
+func (c Confidence) IsStandard() bool { return confidenceType.isStandard(string(c)) }
+
+// Label returns the English label Gramps displays for c, such as "Normal".
+func (c Confidence) Label() string { return confidenceType.label(string(c)) }
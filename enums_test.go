@@ -0,0 +1,121 @@
+package grampsxml
+
+import "testing"
+
+func TestParseEventType(t *testing.T) {
+	testCases := []struct {
+		in       string
+		want     EventType
+		standard bool
+	}{
+		{in: "Birth", want: EventBirth, standard: true},
+		{in: " cause of death ", want: EventCauseOfDeath, standard: true},
+		{in: "Unknown", want: EventUnknown, standard: true},
+		{in: "Brith", want: "Brith", standard: false},
+		{in: "Custom", want: "Custom", standard: false},
+	}
+	for _, tc := range testCases {
+		got, standard := ParseEventType(tc.in)
+		if got != tc.want || standard != tc.standard {
+			t.Errorf("ParseEventType(%q) = %q, %v, want %q, %v", tc.in, got, standard, tc.want, tc.standard)
+		}
+		if got.IsStandard() != tc.standard {
+			t.Errorf("%q.IsStandard() = %v, want %v", got, got.IsStandard(), tc.standard)
+		}
+	}
+}
+
+func TestParseByLabel(t *testing.T) {
+	if got, ok := ParseNoteType("person note"); got != NotePerson || !ok {
+		t.Errorf("ParseNoteType: got %q, %v", got, ok)
+	}
+	if got, ok := ParseURLType("e-mail"); got != URLEmail || !ok {
+		t.Errorf("ParseURLType: got %q, %v", got, ok)
+	}
+	if got, ok := ParseChildRel("stepchild"); got != ChildStepchild || !ok {
+		t.Errorf("ParseChildRel: got %q, %v", got, ok)
+	}
+	if got, ok := ParseNameOrigin("Patronymic"); got != OriginPatronymic || !ok {
+		t.Errorf("ParseNameOrigin: got %q, %v", got, ok)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	testCases := []struct {
+		got, want string
+	}{
+		{got: GenderFemale.Label(), want: "female"},
+		{got: Gender("Z").Label(), want: "Z"},
+		{got: ConfidenceVeryHigh.Label(), want: "Very High"},
+		{got: FamilyCivilUnion.Label(), want: "Civil Union"},
+		{got: AttributeFatherAge.Label(), want: "Father's Age"},
+		{got: PlaceType("Shire").Label(), want: "Shire"},
+	}
+	for _, tc := range testCases {
+		if tc.got != tc.want {
+			t.Errorf("got label %q, want %q", tc.got, tc.want)
+		}
+	}
+}
+
+func TestParseGender(t *testing.T) {
+	testCases := []struct {
+		in      string
+		want    Gender
+		wantErr bool
+	}{
+		{in: "M", want: GenderMale},
+		{in: "female", want: GenderFemale},
+		{in: "x", want: GenderOther},
+		{in: "Q", wantErr: true},
+	}
+	for _, tc := range testCases {
+		got, err := ParseGender(tc.in)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("ParseGender(%q) = %q, %v, want %q, error %v", tc.in, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestParseConfidence(t *testing.T) {
+	if got, err := ParseConfidence("high"); err != nil || got != ConfidenceHigh {
+		t.Errorf("ParseConfidence(high) = %q, %v", got, err)
+	}
+	if got, err := ParseConfidence("0"); err != nil || got != ConfidenceVeryLow {
+		t.Errorf("ParseConfidence(0) = %q, %v", got, err)
+	}
+	if _, err := ParseConfidence("5"); err == nil {
+		t.Errorf("ParseConfidence(5): expected error")
+	}
+}
+
+func TestStandardConstants(t *testing.T) {
+	standard := []interface{ IsStandard() bool }{
+		GenderUnknown, EventStillbirth, EventNumberOfMarriages, RoleInformant, FamilyUnknown,
+		ChildNone, NameMarried, OriginLocation, PlaceNumber, NoteHTMLCode, NoteSourceText,
+		URLFTP, RepositoryWebSite, MediaTombstone, AttributeSSN, ConfidenceNormal,
+	}
+	for _, v := range standard {
+		if !v.IsStandard() {
+			t.Errorf("%v is not standard", v)
+		}
+	}
+}
+
+func TestParseEventRole(t *testing.T) {
+	testCases := []struct {
+		in       string
+		want     EventRole
+		standard bool
+	}{
+		{in: "witness", want: RoleWitness, standard: true},
+		{in: "Celebrant", want: RoleCelebrant, standard: true},
+		{in: "Photographer", want: "Photographer", standard: false},
+	}
+	for _, tc := range testCases {
+		got, standard := ParseEventRole(tc.in)
+		if got != tc.want || standard != tc.standard {
+			t.Errorf("ParseEventRole(%q) = %q, %v, want %q, %v", tc.in, got, standard, tc.want, tc.standard)
+		}
+	}
+}
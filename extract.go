@@ -0,0 +1,150 @@
+package grampsxml
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Ancestors returns the handle of the person with the given handle followed
+// by those of their parents, their parents' parents and so on, nearest
+// first. Generations limits the number of generations followed, so that 1
+// includes only parents. Zero means no limit.
+func Ancestors(db *Database, handle string, generations int) ([]string, error) {
+	return relatedPeople(db, handle, generations, func(ix *Index, p *Person, visit func(string)) {
+		for _, c := range p.Childof {
+			if f := ix.Family(c.Hlink); f != nil {
+				for _, h := range familyParents(f) {
+					visit(h)
+				}
+			}
+		}
+	})
+}
+
+// Descendants returns the handle of the person with the given handle
+// followed by those of their children, their children's children and so
+// on, nearest first. Generations limits the number of generations followed
+// as for [Ancestors].
+func Descendants(db *Database, handle string, generations int) ([]string, error) {
+	return relatedPeople(db, handle, generations, func(ix *Index, p *Person, visit func(string)) {
+		for _, pi := range p.Parentin {
+			if f := ix.Family(pi.Hlink); f != nil {
+				for _, cr := range f.Childref {
+					visit(cr.Hlink)
+				}
+			}
+		}
+	})
+}
+
+// Relatives returns the handle of the person with the given handle followed
+// by those of everyone connected to them by at most the given number of
+// steps from a person to their parent, child or spouse, nearest first. A
+// sibling is two steps away, as is a parent-in-law. Zero steps means no
+// limit.
+func Relatives(db *Database, handle string, steps int) ([]string, error) {
+	return relatedPeople(db, handle, steps, func(ix *Index, p *Person, visit func(string)) {
+		for _, c := range p.Childof {
+			if f := ix.Family(c.Hlink); f != nil {
+				for _, h := range familyParents(f) {
+					visit(h)
+				}
+			}
+		}
+		for _, pi := range p.Parentin {
+			if f := ix.Family(pi.Hlink); f != nil {
+				for _, h := range familyParents(f) {
+					visit(h)
+				}
+				for _, cr := range f.Childref {
+					visit(cr.Hlink)
+				}
+			}
+		}
+	})
+}
+
+// relatedPeople returns the people reached from the person with the given
+// handle by following next at most limit times, or without limit if limit
+// is zero, in breadth first order.
+func relatedPeople(db *Database, handle string, limit int, next func(ix *Index, p *Person, visit func(string))) ([]string, error) {
+	ix := NewIndex(db)
+	root := ix.Person(handle)
+	if root == nil {
+		return nil, fmt.Errorf("grampsxml: person %s not found", handle)
+	}
+	type step struct {
+		p    *Person
+		dist int
+	}
+	seen := map[string]bool{handle: true}
+	people := []string{handle}
+	queue := []step{{root, 0}}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		if limit > 0 && s.dist >= limit {
+			continue
+		}
+		next(ix, s.p, func(h string) {
+			if p := ix.Person(h); p != nil && !seen[h] {
+				seen[h] = true
+				people = append(people, h)
+				queue = append(queue, step{p, s.dist + 1})
+			}
+		})
+	}
+	return people, nil
+}
+
+// Extract returns a new database holding copies of the people in db with
+// the given handles, along with the families, events, places, citations,
+// sources, repositories, notes, media objects and tags that they refer to,
+// directly or through one another, such as the places enclosing the places
+// of their events. References to people who are not included are removed,
+// so that families keep only the included parents and children. The header
+// of db is copied, as are the bookmarks and home and default people that
+// refer to included objects. Handles that are not those of people in db
+// are ignored, and db is not changed.
+func Extract(db *Database, people []string) *Database {
+	targets := make(map[string][]string)
+	walkReferences(db, func(r reference) {
+		if r.owner != "" {
+			owner := r.ownerType + "\x00" + r.owner
+			targets[owner] = append(targets[owner], r.target+"\x00"+*r.hlink)
+		}
+	})
+
+	exists := make(map[string]bool)
+	walkObjects(db, func(o primaryObject) { exists[o.objType+"\x00"+o.handle] = true })
+	keep := make(map[string]bool)
+	var queue []string
+	for _, h := range people {
+		if key := targetPerson + "\x00" + h; exists[key] && !keep[key] {
+			keep[key] = true
+			queue = append(queue, key)
+		}
+	}
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		for _, t := range targets[key] {
+			if exists[t] && !keep[t] && !strings.HasPrefix(t, targetPerson+"\x00") {
+				keep[t] = true
+				queue = append(queue, t)
+			}
+		}
+	}
+
+	out := clone(*db)
+	removeObjects(&out, func(objType, handle string) bool { return !keep[objType+"\x00"+handle] })
+	walkReferences(&out, func(r reference) {
+		if !keep[r.target+"\x00"+*r.hlink] {
+			*r.hlink = ""
+		}
+	})
+	walkObjects(&out, func(o primaryObject) { pruneReferences(reflect.ValueOf(o.value).Elem()) })
+	pruneRootReferences(&out)
+	return &out
+}
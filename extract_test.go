@@ -0,0 +1,127 @@
+package grampsxml
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func extractSample() *Database {
+	return &Database{
+		Header: Header{Created: Created{Date: "2024-01-01", Version: "5.2.0"}},
+		Tags:   &Tags{Tag: []Tag{{Handle: "_t1", Name: "ToDo"}, {Handle: "_t2", Name: "Other"}}},
+		Events: &Events{Event: []Event{
+			{Handle: "_e1", Place: &Place{Hlink: "_p1"}, Citationref: []Citationref{{Hlink: "_c1"}}},
+			{Handle: "_e2", Place: &Place{Hlink: "_p4"}},
+		}},
+		People: &People{
+			Home: new("_i6"),
+			Person: []Person{
+				{
+					Handle: "_i1", Parentin: []Parentin{{Hlink: "_f1"}},
+					Eventref:  []Eventref{{Hlink: "_e1"}},
+					Noteref:   []Noteref{{Hlink: "_n1"}},
+					Personref: []Personref{{Hlink: "_i6", Rel: "Godfather"}},
+				},
+				{Handle: "_i2", Parentin: []Parentin{{Hlink: "_f1"}}},
+				{Handle: "_i3", Childof: []Childof{{Hlink: "_f1"}}, Parentin: []Parentin{{Hlink: "_f2"}}},
+				{Handle: "_i4", Parentin: []Parentin{{Hlink: "_f2"}}},
+				{Handle: "_i5", Childof: []Childof{{Hlink: "_f2"}}, Parentin: []Parentin{{Hlink: "_f3"}}},
+				{Handle: "_i6", Childof: []Childof{{Hlink: "_f2"}}, Eventref: []Eventref{{Hlink: "_e2"}}},
+				{Handle: "_i7", Parentin: []Parentin{{Hlink: "_f3"}}},
+				{Handle: "_i8", Childof: []Childof{{Hlink: "_f3"}}},
+			},
+		},
+		Families: &Families{Family: []Family{
+			{Handle: "_f1", Father: &Father{Hlink: "_i1"}, Mother: &Mother{Hlink: "_i2"}, Childref: []Childref{{Hlink: "_i3"}}},
+			{Handle: "_f2", Father: &Father{Hlink: "_i3"}, Mother: &Mother{Hlink: "_i4"}, Childref: []Childref{{Hlink: "_i5"}, {Hlink: "_i6"}}},
+			{Handle: "_f3", Father: &Father{Hlink: "_i5"}, Mother: &Mother{Hlink: "_i7"}, Childref: []Childref{{Hlink: "_i8"}}},
+		}},
+		Citations:    &Citations{Citation: []Citation{{Handle: "_c1", Sourceref: &Sourceref{Hlink: "_s1"}}}},
+		Sources:      &Sources{Source: []Source{{Handle: "_s1", Reporef: []Reporef{{Hlink: "_r1"}}}, {Handle: "_s2"}}},
+		Repositories: &Repositories{Repository: []Repository{{Handle: "_r1"}}},
+		Places: &Places{Place: []Placeobj{
+			{Handle: "_p1", Placeref: []Placeref{{Hlink: "_p2"}}},
+			{Handle: "_p2", Placeref: []Placeref{{Hlink: "_p3"}}},
+			{Handle: "_p3"},
+			{Handle: "_p4", Placeref: []Placeref{{Hlink: "_p3"}}},
+		}},
+		Notes: &Notes{Note: []Note{{Handle: "_n1", Tagref: []Tagref{{Hlink: "_t1"}}}, {Handle: "_n2"}}},
+		Bookmarks: &Bookmarks{Bookmark: []Bookmark{
+			{Target: "person", Hlink: "_i1"},
+			{Target: "person", Hlink: "_i6"},
+			{Target: "source", Hlink: "_s2"},
+		}},
+	}
+}
+
+func TestRelatedPeople(t *testing.T) {
+	db := extractSample()
+	testCases := []struct {
+		name string
+		fn   func(*Database, string, int) ([]string, error)
+		root string
+		n    int
+		want []string
+	}{
+		{name: "ancestors", fn: Ancestors, root: "_i5", want: []string{"_i5", "_i3", "_i4", "_i1", "_i2"}},
+		{name: "parents", fn: Ancestors, root: "_i5", n: 1, want: []string{"_i5", "_i3", "_i4"}},
+		{name: "descendants", fn: Descendants, root: "_i1", want: []string{"_i1", "_i3", "_i5", "_i6", "_i8"}},
+		{name: "relatives", fn: Relatives, root: "_i3", n: 1, want: []string{"_i3", "_i1", "_i2", "_i4", "_i5", "_i6"}},
+		{name: "relatives two steps", fn: Relatives, root: "_i5", n: 2, want: []string{"_i5", "_i3", "_i4", "_i7", "_i8", "_i1", "_i2", "_i6"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.fn(db, tc.root, tc.n)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+	if _, err := Ancestors(db, "_ix", 0); err == nil {
+		t.Errorf("expected error for missing person")
+	}
+}
+
+func TestExtract(t *testing.T) {
+	db := extractSample()
+	people, err := Ancestors(db, "_i5", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := Extract(db, people)
+
+	if diff := cmp.Diff(extractSample(), db); diff != "" {
+		t.Errorf("source database was modified (-want +got):\n%s", diff)
+	}
+	wantHandles := []string{
+		"_t1", "_e1", "_i1", "_i2", "_i3", "_i4", "_i5", "_f1", "_f2", "_f3",
+		"_c1", "_s1", "_p1", "_p2", "_p3", "_r1", "_n1",
+	}
+	if diff := cmp.Diff(wantHandles, objectHandles(got)); diff != "" {
+		t.Errorf("objects mismatch (-want +got):\n%s", diff)
+	}
+
+	ix := NewIndex(got)
+	if diff := cmp.Diff([]Childref{{Hlink: "_i5"}}, ix.Family("_f2").Childref); diff != "" {
+		t.Errorf("family children mismatch (-want +got):\n%s", diff)
+	}
+	if f := ix.Family("_f3"); f.Mother != nil || len(f.Childref) != 0 || f.Father.Hlink != "_i5" {
+		t.Errorf("family _f3 not pruned: %+v", f)
+	}
+	if len(ix.Person("_i1").Personref) != 0 {
+		t.Errorf("association with excluded person not removed")
+	}
+	if got.People.Home != nil {
+		t.Errorf("home person not cleared")
+	}
+	if diff := cmp.Diff([]Bookmark{{Target: "person", Hlink: "_i1"}}, got.Bookmarks.Bookmark); diff != "" {
+		t.Errorf("bookmarks mismatch (-want +got):\n%s", diff)
+	}
+	if got.Header != db.Header {
+		t.Errorf("header not copied")
+	}
+}
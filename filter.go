@@ -0,0 +1,285 @@
+package grampsxml
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CustomFilters holds the filters defined in a Gramps custom_filters.xml
+// file.
+type CustomFilters struct {
+	XMLName xml.Name       `xml:"filters"`
+	Objects []FilterObject `xml:"object"`
+}
+
+// FilterObject holds the filters that select one type of object.
+type FilterObject struct {
+	// Type is the type of object selected, such as "Person" or "Family".
+	Type    string         `xml:"type,attr"`
+	Filters []CustomFilter `xml:"filter"`
+}
+
+// CustomFilter is a named filter made up of rules.
+type CustomFilter struct {
+	Name string `xml:"name,attr"`
+
+	// Function is "and" if every rule must match, "or" if any rule must
+	// match and "one" if exactly one rule must match. Empty means "and".
+	Function string `xml:"function,attr,omitempty"`
+
+	// Invert selects the objects that do not match instead.
+	Invert bool `xml:"invert,attr,omitempty"`
+
+	Comment string       `xml:"comment,attr,omitempty"`
+	Rules   []FilterRule `xml:"rule"`
+}
+
+// FilterRule is a single test in a filter.
+type FilterRule struct {
+	// Class is the name of the Gramps rule, such as "IsFemale".
+	Class string `xml:"class,attr"`
+
+	// UseRegex makes the text arguments of the rule regular expressions
+	// instead of text to search for.
+	UseRegex bool `xml:"use_regex,attr,omitempty"`
+
+	// UseCase makes text matching case sensitive.
+	UseCase bool `xml:"use_case,attr,omitempty"`
+
+	Args []FilterArg `xml:"arg"`
+}
+
+// FilterArg is an argument of a filter rule.
+type FilterArg struct {
+	Value string `xml:"value,attr"`
+}
+
+// ParseCustomFilters reads filters in the form of a Gramps
+// custom_filters.xml file.
+func ParseCustomFilters(r io.Reader) (*CustomFilters, error) {
+	var cf CustomFilters
+	if err := xml.NewDecoder(r).Decode(&cf); err != nil {
+		return nil, fmt.Errorf("grampsxml: parse custom filters: %w", err)
+	}
+	return &cf, nil
+}
+
+// Filter returns the filter with the given name for the given type of
+// object, such as "Person", or nil if there is none.
+func (cf *CustomFilters) Filter(objType, name string) *CustomFilter {
+	for i := range cf.Objects {
+		o := &cf.Objects[i]
+		if o.Type != objType {
+			continue
+		}
+		for j := range o.Filters {
+			if o.Filters[j].Name == name {
+				return &o.Filters[j]
+			}
+		}
+	}
+	return nil
+}
+
+// filterTypes maps the types of object named in custom filters to the
+// types used elsewhere.
+var filterTypes = map[string]string{
+	"Person": targetPerson,
+	"Family": targetFamily,
+	"Event":  targetEvent,
+	"Place":  targetPlace,
+	"Source": targetSource,
+}
+
+// Apply returns the handles of the objects in db selected by the filter with
+// the given name for the given type of object, in the order they appear in
+// db. Filters for people, families, events, places and sources are
+// supported, using the rules Gramps offers for them that depend only on the
+// data in db. Filters referred to by rules such as MatchesFilter are looked
+// up in cf. An error is returned if the filter or one it refers to does not
+// exist, refers to itself, or uses a rule that is not supported.
+func (cf *CustomFilters) Apply(db *Database, objType, name string) ([]string, error) {
+	c := &filterContext{
+		db:      db,
+		ix:      NewIndex(db),
+		filters: cf,
+		objects: make(map[string][]primaryObject),
+		results: make(map[string]map[string]bool),
+		active:  make(map[string]bool),
+	}
+	walkObjects(db, func(o primaryObject) { c.objects[o.objType] = append(c.objects[o.objType], o) })
+	matched, err := c.apply(objType, name)
+	if err != nil {
+		return nil, err
+	}
+	var handles []string
+	for _, o := range c.objects[filterTypes[objType]] {
+		if matched[o.handle] {
+			handles = append(handles, o.handle)
+		}
+	}
+	return handles, nil
+}
+
+// filterContext holds the state of the evaluation of a filter.
+type filterContext struct {
+	db      *Database
+	ix      *Index
+	filters *CustomFilters
+	objects map[string][]primaryObject // by type
+	results map[string]map[string]bool // by filter type and name
+	active  map[string]bool            // filters being evaluated
+}
+
+// apply returns the set of handles of the objects selected by the named
+// filter.
+func (c *filterContext) apply(objType, name string) (map[string]bool, error) {
+	key := objType + "\x00" + name
+	if r, ok := c.results[key]; ok {
+		return r, nil
+	}
+	if c.active[key] {
+		return nil, fmt.Errorf("grampsxml: %s filter %q refers to itself", objType, name)
+	}
+	f := c.filters.Filter(objType, name)
+	if f == nil {
+		return nil, fmt.Errorf("grampsxml: %s filter %q not found", objType, name)
+	}
+	target, ok := filterTypes[objType]
+	if !ok {
+		return nil, fmt.Errorf("grampsxml: %s filters are not supported", objType)
+	}
+	c.active[key] = true
+	defer delete(c.active, key)
+
+	rules := make([]filterMatcher, len(f.Rules))
+	for i := range f.Rules {
+		m, err := c.compile(target, &f.Rules[i])
+		if err != nil {
+			return nil, fmt.Errorf("grampsxml: %s filter %q: %w", objType, name, err)
+		}
+		rules[i] = m
+	}
+
+	result := make(map[string]bool)
+	for _, o := range c.objects[target] {
+		var match bool
+		switch f.Function {
+		case "", "and":
+			match = true
+			for _, m := range rules {
+				if !m(o) {
+					match = false
+					break
+				}
+			}
+		case "or":
+			for _, m := range rules {
+				if m(o) {
+					match = true
+					break
+				}
+			}
+		case "one":
+			n := 0
+			for _, m := range rules {
+				if m(o) {
+					n++
+				}
+			}
+			match = n == 1
+		default:
+			return nil, fmt.Errorf("grampsxml: %s filter %q: unknown function %q", objType, name, f.Function)
+		}
+		if match != f.Invert {
+			result[o.handle] = true
+		}
+	}
+	c.results[key] = result
+	return result, nil
+}
+
+// filterMatcher reports whether an object matches a rule.
+type filterMatcher func(o primaryObject) bool
+
+// ruleArgs gives access to the arguments of a rule.
+type ruleArgs struct {
+	rule *FilterRule
+}
+
+// arg returns the ith argument, or "" if there is none.
+func (a ruleArgs) arg(i int) string {
+	if i < len(a.rule.Args) {
+		return a.rule.Args[i].Value
+	}
+	return ""
+}
+
+// flag reports whether the ith argument is set, which Gramps records as
+// "1".
+func (a ruleArgs) flag(i int) bool {
+	return a.arg(i) == "1"
+}
+
+// text returns a function that reports whether a text value matches the
+// ith argument, as Gramps does: the argument is a regular expression to
+// search for if the rule uses them, and otherwise text to search for,
+// ignoring case unless the rule is case sensitive. An empty argument
+// matches everything.
+func (a ruleArgs) text(i int) (func(string) bool, error) {
+	arg := a.arg(i)
+	switch {
+	case arg == "":
+		return func(string) bool { return true }, nil
+	case a.rule.UseRegex:
+		expr := arg
+		if !a.rule.UseCase {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", a.rule.Class, err)
+		}
+		return re.MatchString, nil
+	case a.rule.UseCase:
+		return func(s string) bool { return strings.Contains(s, arg) }, nil
+	default:
+		arg = strings.ToLower(arg)
+		return func(s string) bool { return strings.Contains(strings.ToLower(s), arg) }, nil
+	}
+}
+
+// count returns a function that reports whether a number satisfies the
+// comparison given by the ith argument, a number, and the following one,
+// which is "less than", "greater than" or "equal to".
+func (a ruleArgs) count(i int) (func(int) bool, error) {
+	n, err := strconv.Atoi(cmp.Or(a.arg(i), "0"))
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: invalid count %q", a.rule.Class, a.arg(i))
+	}
+	switch a.arg(i + 1) {
+	case "less than", "lesser than":
+		return func(v int) bool { return v < n }, nil
+	case "", "greater than":
+		return func(v int) bool { return v > n }, nil
+	case "equal to":
+		return func(v int) bool { return v == n }, nil
+	}
+	return nil, fmt.Errorf("rule %s: invalid comparison %q", a.rule.Class, a.arg(i+1))
+}
+
+// date returns a function that reports whether a date may be the same as
+// the date given by the ith argument. An empty argument matches every date.
+func (a ruleArgs) date(i int) func(Date) bool {
+	arg := a.arg(i)
+	if arg == "" {
+		return func(Date) bool { return true }
+	}
+	want := ParseDate(arg)
+	return func(d Date) bool { return d.overlaps(want) }
+}
@@ -0,0 +1,192 @@
+package grampsxml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const filterSample = `<?xml version="1.0" encoding="utf-8"?>
+<filters>
+  <object type="Person">
+    <filter name="Women" function="and">
+      <rule class="IsFemale" use_regex="False">
+      </rule>
+    </filter>
+    <filter name="Men" function="and" invert="1">
+      <rule class="MatchesFilter" use_regex="False">
+        <arg value="Women"/>
+      </rule>
+    </filter>
+    <filter name="Garners" function="and">
+      <rule class="gramps.gen.filters.rules.person.HasNameOf" use_regex="False">
+        <arg value=""/><arg value="garner"/><arg value=""/><arg value=""/><arg value=""/><arg value=""/>
+        <arg value=""/><arg value=""/><arg value=""/><arg value=""/><arg value=""/>
+      </rule>
+    </filter>
+    <filter name="Women or Garners" function="or">
+      <rule class="MatchesFilter"><arg value="Women"/></rule>
+      <rule class="MatchesFilter"><arg value="Garners"/></rule>
+    </filter>
+    <filter name="Women or Garners not both" function="one">
+      <rule class="MatchesFilter"><arg value="Women"/></rule>
+      <rule class="MatchesFilter"><arg value="Garners"/></rule>
+    </filter>
+    <filter name="Born in Greenfield" function="and">
+      <rule class="HasBirth"><arg value=""/><arg value="Greenfield"/><arg value=""/></rule>
+    </filter>
+    <filter name="Born about 1859" function="and">
+      <rule class="HasBirth"><arg value="1859"/><arg value=""/><arg value=""/></rule>
+    </filter>
+    <filter name="Ancestors of Eliza" function="and">
+      <rule class="IsAncestorOf"><arg value="I0002"/><arg value="0"/></rule>
+    </filter>
+    <filter name="Parents of women" function="and">
+      <rule class="IsParentOfFilterMatch"><arg value="Women"/></rule>
+    </filter>
+    <filter name="Id regex" function="and">
+      <rule class="RegExpIdOf" use_regex="True"><arg value="I000[12]"/></rule>
+    </filter>
+    <filter name="Noted" function="and">
+      <rule class="HasNoteMatchingSubstringOf"><arg value="miller"/></rule>
+    </filter>
+    <filter name="No death" function="and">
+      <rule class="NoDeathdate"/>
+    </filter>
+    <filter name="Loop" function="and">
+      <rule class="MatchesFilter"><arg value="Loop"/></rule>
+    </filter>
+    <filter name="Unknown" function="and">
+      <rule class="HasSoundexName"><arg value="Garner"/></rule>
+    </filter>
+  </object>
+  <object type="Family">
+    <filter name="Married" function="and">
+      <rule class="HasRelType"><arg value="Married"/></rule>
+      <rule class="FatherHasIdOf"><arg value="I0000"/></rule>
+    </filter>
+  </object>
+  <object type="Event">
+    <filter name="Births of women" function="and">
+      <rule class="HasType"><arg value="Birth"/></rule>
+      <rule class="MatchesPersonFilter"><arg value="Women"/><arg value="0"/></rule>
+    </filter>
+    <filter name="Cited on folio" function="and">
+      <rule class="HasCitation"><arg value="folio"/><arg value="1855"/><arg value="Normal"/></rule>
+    </filter>
+    <filter name="Cited in 1850" function="and">
+      <rule class="HasCitation"><arg value=""/><arg value="1850"/><arg value=""/></rule>
+    </filter>
+    <filter name="Cited with high confidence" function="and">
+      <rule class="HasCitation"><arg value=""/><arg value=""/><arg value="3"/></rule>
+    </filter>
+    <filter name="In Yorkshire" function="and">
+      <rule class="MatchesPlaceFilter"><arg value="Yorkshire"/></rule>
+    </filter>
+  </object>
+  <object type="Place">
+    <filter name="Yorkshire" function="and">
+      <rule class="IsEnclosedBy"><arg value="P0001"/><arg value="1"/></rule>
+    </filter>
+    <filter name="Without coordinates" function="and">
+      <rule class="HasNoLatLon"/>
+    </filter>
+  </object>
+  <object type="Source">
+    <filter name="Registers" function="and">
+      <rule class="HasSource" use_regex="True"><arg value="^parish"/><arg value=""/><arg value=""/></rule>
+    </filter>
+  </object>
+</filters>
+`
+
+func TestCustomFiltersApply(t *testing.T) {
+	var db Database
+	if err := ReadCSV(strings.NewReader(csvSample), &db); err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	db.Citations.Citation[0].Page = new("Folio 12")
+	db.Citations.Citation[0].Dateval = &Dateval{Val: "1855-07-02"}
+	cf, err := ParseCustomFilters(strings.NewReader(filterSample))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	ids := make(map[string]string)
+	walkObjects(&db, func(o primaryObject) {
+		if o.id != nil {
+			ids[o.handle] = strval(*o.id)
+		}
+	})
+
+	testCases := []struct {
+		objType string
+		name    string
+		want    []string
+	}{
+		{objType: "Person", name: "Women", want: []string{"I0001", "I0002"}},
+		{objType: "Person", name: "Men", want: []string{"I0000"}},
+		{objType: "Person", name: "Garners", want: []string{"I0000", "I0002"}},
+		{objType: "Person", name: "Women or Garners", want: []string{"I0000", "I0001", "I0002"}},
+		{objType: "Person", name: "Women or Garners not both", want: []string{"I0000", "I0001"}},
+		{objType: "Person", name: "Born in Greenfield", want: []string{"I0000", "I0002"}},
+		{objType: "Person", name: "Born about 1859", want: []string{"I0001"}},
+		{objType: "Person", name: "Ancestors of Eliza", want: []string{"I0000", "I0001"}},
+		{objType: "Person", name: "Parents of women", want: []string{"I0000", "I0001"}},
+		{objType: "Person", name: "Id regex", want: []string{"I0001", "I0002"}},
+		{objType: "Person", name: "Noted", want: []string{"I0000"}},
+		{objType: "Person", name: "No death", want: []string{"I0001", "I0002"}},
+		{objType: "Family", name: "Married", want: []string{"F0000"}},
+		{objType: "Event", name: "Births of women", want: []string{"E0002", "E0003"}},
+		{objType: "Event", name: "Cited on folio", want: []string{"E0000"}},
+		{objType: "Event", name: "Cited in 1850"},
+		{objType: "Event", name: "Cited with high confidence"},
+		{objType: "Event", name: "In Yorkshire", want: []string{"E0000", "E0003", "E0004"}},
+		{objType: "Place", name: "Yorkshire", want: []string{"P0000", "P0001"}},
+		{objType: "Place", name: "Without coordinates", want: []string{"P0001"}},
+		{objType: "Source", name: "Registers", want: []string{"S0000"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.objType+"/"+tc.name, func(t *testing.T) {
+			handles, err := cf.Apply(&db, tc.objType, tc.name)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, h := range handles {
+				got = append(got, ids[h])
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCustomFiltersApplyErrors(t *testing.T) {
+	cf, err := ParseCustomFilters(strings.NewReader(filterSample))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	testCases := []struct {
+		objType string
+		name    string
+		want    string
+	}{
+		{objType: "Person", name: "Missing", want: "not found"},
+		{objType: "Person", name: "Loop", want: "refers to itself"},
+		{objType: "Person", name: "Unknown", want: "unsupported rule HasSoundexName"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := cf.Apply(&Database{}, tc.objType, tc.name)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got error %v, wanted one containing %q", err, tc.want)
+			}
+		})
+	}
+}
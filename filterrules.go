@@ -0,0 +1,811 @@
+package grampsxml
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// compile returns a matcher for objects of the given type implementing r.
+func (c *filterContext) compile(objType string, r *FilterRule) (filterMatcher, error) {
+	a := ruleArgs{rule: r}
+	class := r.Class[strings.LastIndex(r.Class, ".")+1:]
+	var m filterMatcher
+	var err error
+	switch objType {
+	case targetPerson:
+		m, err = c.personRule(class, a)
+	case targetFamily:
+		m, err = c.familyRule(class, a)
+	case targetEvent:
+		m, err = c.eventRule(class, a)
+	case targetPlace:
+		m, err = c.placeRule(class, a)
+	case targetSource:
+		m, err = c.sourceRule(class, a)
+	}
+	if m == nil && err == nil {
+		m, err = c.genericRule(objType, class, a)
+	}
+	if m == nil && err == nil {
+		return nil, fmt.Errorf("unsupported rule %s", class)
+	}
+	return m, err
+}
+
+// filterSet returns the handles of the objects selected by the filter for
+// the given type of object named by the ith argument.
+func (c *filterContext) filterSet(objType string, a ruleArgs, i int) (map[string]bool, error) {
+	for name, t := range filterTypes {
+		if t == objType {
+			return c.apply(name, a.arg(i))
+		}
+	}
+	return nil, fmt.Errorf("rule %s: unsupported object type %s", a.rule.Class, objType)
+}
+
+// genericRule returns a matcher for the rules Gramps offers for every type
+// of object, or nil if class is not one of them.
+func (c *filterContext) genericRule(objType, class string, a ruleArgs) (filterMatcher, error) {
+	switch class {
+	case "Everyone", "AllFamilies", "AllEvents", "AllPlaces", "AllSources":
+		return func(primaryObject) bool { return true }, nil
+
+	case "HasIdOf":
+		id := a.arg(0)
+		return func(o primaryObject) bool { return strval(*o.id) == id }, nil
+
+	case "RegExpIdOf":
+		match, err := ruleArgs{rule: withRegex(a.rule, true)}.text(0)
+		if err != nil {
+			return nil, err
+		}
+		return func(o primaryObject) bool { return match(strval(*o.id)) }, nil
+
+	case "HasTag":
+		tag := a.arg(0)
+		return func(o primaryObject) bool {
+			return slices.ContainsFunc(objectLinks(o, "Tagref"), func(h string) bool {
+				t := c.ix.Tag(h)
+				return t != nil && t.Name == tag
+			})
+		}, nil
+
+	case "MatchesFilter":
+		set, err := c.filterSet(objType, a, 0)
+		if err != nil {
+			return nil, err
+		}
+		return func(o primaryObject) bool { return set[o.handle] }, nil
+
+	case "HasNote":
+		return countMatcher(a, 0, func(o primaryObject) int { return len(objectLinks(o, "Noteref")) })
+
+	case "HasNoteMatchingSubstringOf", "HasNoteRegexp":
+		match, err := ruleArgs{rule: withRegex(a.rule, class == "HasNoteRegexp")}.text(0)
+		if err != nil {
+			return nil, err
+		}
+		return func(o primaryObject) bool {
+			return slices.ContainsFunc(objectLinks(o, "Noteref"), func(h string) bool {
+				n := c.ix.Note(h)
+				return n != nil && match(n.Text)
+			})
+		}, nil
+
+	case "HasSourceCount":
+		return countMatcher(a, 0, func(o primaryObject) int { return len(objectLinks(o, "Citationref")) })
+
+	case "HasCitation":
+		page, err := a.text(0)
+		if err != nil {
+			return nil, err
+		}
+		date := a.date(1)
+		var minConfidence int
+		if a.arg(2) != "" {
+			conf, err := ParseConfidence(a.arg(2))
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: invalid confidence %q", class, a.arg(2))
+			}
+			minConfidence, _ = strconv.Atoi(string(conf))
+		}
+		return func(o primaryObject) bool {
+			return slices.ContainsFunc(objectLinks(o, "Citationref"), func(h string) bool {
+				cit := c.ix.Citation(h)
+				if cit == nil || !page(strval(cit.Page)) {
+					return false
+				}
+				if a.arg(1) != "" && !date(cit.Date()) {
+					return false
+				}
+				conf, err := strconv.Atoi(cit.Confidence)
+				if err != nil {
+					conf, _ = strconv.Atoi(string(ConfidenceNormal))
+				}
+				return conf >= minConfidence
+			})
+		}, nil
+
+	case "HasGallery":
+		return countMatcher(a, 0, func(o primaryObject) int { return len(objectLinks(o, "Objref")) })
+
+	case "ChangedSince":
+		from, err := a.time(0)
+		if err != nil {
+			return nil, err
+		}
+		to, err := a.time(1)
+		if err != nil {
+			return nil, err
+		}
+		return func(o primaryObject) bool {
+			t := parseChange(*o.change)
+			return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+		}, nil
+
+	case "PeoplePrivate", "FamilyPrivate", "EventPrivate", "PlacePrivate", "SourcePrivate":
+		return objectPrivate, nil
+
+	case "PeoplePublic":
+		return func(o primaryObject) bool { return !objectPrivate(o) }, nil
+
+	case "IsBookmarked":
+		return func(o primaryObject) bool {
+			return slices.ContainsFunc(c.db.Bookmarks.list(), func(b Bookmark) bool {
+				return b.Target == objType && b.Hlink == o.handle
+			})
+		}, nil
+
+	case "HasAttribute":
+		match, err := a.text(1)
+		if err != nil {
+			return nil, err
+		}
+		attrType := a.arg(0)
+		return func(o primaryObject) bool {
+			v := reflect.ValueOf(o.value).Elem()
+			f := v.FieldByName("Attribute")
+			if !f.IsValid() {
+				f = v.FieldByName("Srcattribute")
+			}
+			if !f.IsValid() {
+				return false
+			}
+			for i := range f.Len() {
+				at := f.Index(i)
+				if (attrType == "" || at.FieldByName("Type").String() == attrType) && match(at.FieldByName("Value").String()) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+	return nil, nil
+}
+
+// personRule returns a matcher for the rules Gramps offers for people, or
+// nil if class is not one of them.
+func (c *filterContext) personRule(class string, a ruleArgs) (filterMatcher, error) {
+	person := func(fn func(p *Person) bool) filterMatcher {
+		return func(o primaryObject) bool { return fn(o.value.(*Person)) }
+	}
+	switch class {
+	case "IsMale":
+		return person(func(p *Person) bool { return p.Gender == string(GenderMale) }), nil
+	case "IsFemale":
+		return person(func(p *Person) bool { return p.Gender == string(GenderFemale) }), nil
+	case "HasUnknownGender":
+		return person(func(p *Person) bool {
+			return p.Gender != string(GenderMale) && p.Gender != string(GenderFemale)
+		}), nil
+
+	case "SearchName", "RegExpName":
+		match, err := ruleArgs{rule: withRegex(a.rule, class == "RegExpName")}.text(0)
+		if err != nil {
+			return nil, err
+		}
+		return person(func(p *Person) bool {
+			return slices.ContainsFunc(p.Name, func(n Name) bool {
+				return match(strings.Join(nameParts(&n), " "))
+			})
+		}), nil
+
+	case "HasNameOf":
+		match, err := nameMatcher(a)
+		if err != nil {
+			return nil, err
+		}
+		return person(func(p *Person) bool { return slices.ContainsFunc(p.Name, match) }), nil
+
+	case "HasAlternateName":
+		return person(func(p *Person) bool {
+			return slices.ContainsFunc(p.Name, func(n Name) bool { return n.Alt != nil && *n.Alt })
+		}), nil
+	case "HasNickname":
+		return person(func(p *Person) bool {
+			return slices.ContainsFunc(p.Name, func(n Name) bool { return strval(n.Nick) != "" }) ||
+				slices.ContainsFunc(p.Attribute, func(at Attribute) bool { return at.Type == string(AttributeNickname) })
+		}), nil
+	case "IncompleteNames":
+		return person(func(p *Person) bool {
+			return slices.ContainsFunc(p.Name, func(n Name) bool {
+				return strings.TrimSpace(strval(n.First)) == "" ||
+					!slices.ContainsFunc(n.Surname, func(s Surname) bool { return strings.TrimSpace(s.Surname) != "" })
+			})
+		}), nil
+
+	case "HasBirth", "HasDeath":
+		eventType := string(EventBirth)
+		if class == "HasDeath" {
+			eventType = string(EventDeath)
+		}
+		match, err := c.eventMatcher(a, -1, 0, 1, 2)
+		if err != nil {
+			return nil, err
+		}
+		return person(func(p *Person) bool {
+			return slices.ContainsFunc(p.Eventref, func(er Eventref) bool {
+				ev := c.ix.Event(er.Hlink)
+				return isRole(er.Role, string(RolePrimary)) && ev != nil && strval(ev.Type) == eventType && match(ev)
+			})
+		}), nil
+
+	case "HasEvent":
+		match, err := c.eventMatcher(a, 0, 1, 2, 3)
+		if err != nil {
+			return nil, err
+		}
+		primary := a.flag(4)
+		return person(func(p *Person) bool {
+			return slices.ContainsFunc(p.Eventref, func(er Eventref) bool {
+				ev := c.ix.Event(er.Hlink)
+				return (!primary || isRole(er.Role, string(RolePrimary))) && ev != nil && match(ev)
+			})
+		}), nil
+
+	case "NoBirthdate", "NoDeathdate":
+		eventType := string(EventBirth)
+		if class == "NoDeathdate" {
+			eventType = string(EventDeath)
+		}
+		return person(func(p *Person) bool {
+			ev := personEvent(c.ix, p, eventType)
+			return ev == nil || ev.Date().IsZero()
+		}), nil
+
+	case "PersonWithIncompleteEvent":
+		return person(func(p *Person) bool { return c.incompleteEvent(p.Eventref) }), nil
+	case "FamilyWithIncompleteEvent":
+		return person(func(p *Person) bool {
+			return slices.ContainsFunc(p.Parentin, func(pi Parentin) bool {
+				f := c.ix.Family(pi.Hlink)
+				return f != nil && c.incompleteEvent(f.Eventref)
+			})
+		}), nil
+
+	case "IsAncestorOf", "IsDescendantOf":
+		related := Ancestors
+		if class == "IsDescendantOf" {
+			related = Descendants
+		}
+		set := make(map[string]bool)
+		for _, o := range c.objects[targetPerson] {
+			if strval(*o.id) != a.arg(0) {
+				continue
+			}
+			people, _ := related(c.db, o.handle, 0)
+			for i, h := range people {
+				if i > 0 || a.flag(1) {
+					set[h] = true
+				}
+			}
+			break
+		}
+		return func(o primaryObject) bool { return set[o.handle] }, nil
+
+	case "IsChildOfFilterMatch", "IsParentOfFilterMatch", "IsSpouseOfFilterMatch", "IsSiblingOfFilterMatch":
+		set, err := c.filterSet(targetPerson, a, 0)
+		if err != nil {
+			return nil, err
+		}
+		inSet := func(hs ...string) bool { return slices.ContainsFunc(hs, func(h string) bool { return set[h] }) }
+		return person(func(p *Person) bool {
+			switch class {
+			case "IsChildOfFilterMatch":
+				return slices.ContainsFunc(p.Childof, func(co Childof) bool {
+					f := c.ix.Family(co.Hlink)
+					return f != nil && inSet(familyParents(f)...)
+				})
+			case "IsParentOfFilterMatch":
+				return slices.ContainsFunc(p.Parentin, func(pi Parentin) bool {
+					f := c.ix.Family(pi.Hlink)
+					return f != nil && slices.ContainsFunc(f.Childref, func(cr Childref) bool { return set[cr.Hlink] })
+				})
+			case "IsSpouseOfFilterMatch":
+				return slices.ContainsFunc(p.Parentin, func(pi Parentin) bool {
+					f := c.ix.Family(pi.Hlink)
+					return f != nil && slices.ContainsFunc(familyParents(f), func(h string) bool { return h != p.Handle && set[h] })
+				})
+			default:
+				return slices.ContainsFunc(p.Childof, func(co Childof) bool {
+					f := c.ix.Family(co.Hlink)
+					return f != nil && slices.ContainsFunc(f.Childref, func(cr Childref) bool { return cr.Hlink != p.Handle && set[cr.Hlink] })
+				})
+			}
+		}), nil
+
+	case "HaveChildren":
+		return person(func(p *Person) bool {
+			return slices.ContainsFunc(p.Parentin, func(pi Parentin) bool {
+				f := c.ix.Family(pi.Hlink)
+				return f != nil && len(f.Childref) > 0
+			})
+		}), nil
+	case "NeverMarried":
+		return person(func(p *Person) bool { return len(p.Parentin) == 0 }), nil
+	case "MultipleMarriages":
+		return person(func(p *Person) bool { return len(p.Parentin) > 1 }), nil
+	case "MissingParent":
+		return person(func(p *Person) bool {
+			return len(p.Childof) == 0 || slices.ContainsFunc(p.Childof, func(co Childof) bool {
+				f := c.ix.Family(co.Hlink)
+				return f == nil || len(familyParents(f)) < 2
+			})
+		}), nil
+	case "Disconnected":
+		return person(func(p *Person) bool { return len(p.Childof) == 0 && len(p.Parentin) == 0 }), nil
+	case "IsDefaultPerson":
+		return person(func(p *Person) bool { return c.db.People != nil && strval(c.db.People.Default) == p.Handle }), nil
+	case "HaveAltFamilies":
+		return person(func(p *Person) bool {
+			return slices.ContainsFunc(p.Childof, func(co Childof) bool {
+				f := c.ix.Family(co.Hlink)
+				return f != nil && slices.ContainsFunc(f.Childref, func(cr Childref) bool {
+					birth := func(rel *string) bool { return rel == nil || *rel == "" || *rel == string(ChildBirth) }
+					return cr.Hlink == p.Handle && (!birth(cr.Frel) || !birth(cr.Mrel))
+				})
+			})
+		}), nil
+	case "HasAddress":
+		return countMatcher(a, 0, func(o primaryObject) int { return len(o.value.(*Person).Address) })
+	case "HasAssociation":
+		return countMatcher(a, 0, func(o primaryObject) int { return len(o.value.(*Person).Personref) })
+	}
+	return nil, nil
+}
+
+// familyRule returns a matcher for the rules Gramps offers for families, or
+// nil if class is not one of them.
+func (c *filterContext) familyRule(class string, a ruleArgs) (filterMatcher, error) {
+	family := func(fn func(f *Family) bool) filterMatcher {
+		return func(o primaryObject) bool { return fn(o.value.(*Family)) }
+	}
+	members := func(f *Family, role string) []string {
+		switch role {
+		case "Father":
+			if f.Father != nil {
+				return []string{f.Father.Hlink}
+			}
+		case "Mother":
+			if f.Mother != nil {
+				return []string{f.Mother.Hlink}
+			}
+		default:
+			var hs []string
+			for _, cr := range f.Childref {
+				hs = append(hs, cr.Hlink)
+			}
+			return hs
+		}
+		return nil
+	}
+
+	switch class {
+	case "HasRelType":
+		want, _ := ParseFamilyRelType(a.arg(0))
+		return family(func(f *Family) bool {
+			rel := FamilyUnknown
+			if f.Rel != nil && f.Rel.Type != "" {
+				rel = FamilyRelType(f.Rel.Type)
+			}
+			return a.arg(0) == "" || rel == want
+		}), nil
+
+	case "HasEvent":
+		match, err := c.eventMatcher(a, 0, 1, 2, 3)
+		if err != nil {
+			return nil, err
+		}
+		return family(func(f *Family) bool {
+			return slices.ContainsFunc(f.Eventref, func(er Eventref) bool {
+				ev := c.ix.Event(er.Hlink)
+				return ev != nil && match(ev)
+			})
+		}), nil
+
+	case "FatherHasIdOf", "MotherHasIdOf", "ChildHasIdOf":
+		match, err := a.text(0)
+		if err != nil {
+			return nil, err
+		}
+		role := strings.TrimSuffix(class, "HasIdOf")
+		return family(func(f *Family) bool {
+			return slices.ContainsFunc(members(f, role), func(h string) bool {
+				p := c.ix.Person(h)
+				return p != nil && match(strval(p.ID))
+			})
+		}), nil
+
+	case "FatherHasNameOf", "MotherHasNameOf", "ChildHasNameOf":
+		match, err := nameMatcher(a)
+		if err != nil {
+			return nil, err
+		}
+		role := strings.TrimSuffix(class, "HasNameOf")
+		return family(func(f *Family) bool {
+			return slices.ContainsFunc(members(f, role), func(h string) bool {
+				p := c.ix.Person(h)
+				return p != nil && slices.ContainsFunc(p.Name, match)
+			})
+		}), nil
+	}
+	return nil, nil
+}
+
+// eventRule returns a matcher for the rules Gramps offers for events, or
+// nil if class is not one of them.
+func (c *filterContext) eventRule(class string, a ruleArgs) (filterMatcher, error) {
+	switch class {
+	case "HasType":
+		want, _ := ParseEventType(a.arg(0))
+		return func(o primaryObject) bool {
+			return a.arg(0) == "" || strval(o.value.(*Event).Type) == string(want)
+		}, nil
+
+	case "HasData":
+		match, err := c.eventMatcher(a, 0, 1, 2, 3)
+		if err != nil {
+			return nil, err
+		}
+		return func(o primaryObject) bool { return match(o.value.(*Event)) }, nil
+
+	case "MatchesPersonFilter":
+		set, err := c.filterSet(targetPerson, a, 0)
+		if err != nil {
+			return nil, err
+		}
+		events := make(map[string]bool)
+		for _, o := range c.objects[targetPerson] {
+			if !set[o.handle] {
+				continue
+			}
+			p := o.value.(*Person)
+			for _, er := range p.Eventref {
+				events[er.Hlink] = true
+			}
+			if a.flag(1) {
+				for _, pi := range p.Parentin {
+					if f := c.ix.Family(pi.Hlink); f != nil {
+						for _, er := range f.Eventref {
+							events[er.Hlink] = true
+						}
+					}
+				}
+			}
+		}
+		return func(o primaryObject) bool { return events[o.handle] }, nil
+
+	case "MatchesPlaceFilter":
+		set, err := c.filterSet(targetPlace, a, 0)
+		if err != nil {
+			return nil, err
+		}
+		return func(o primaryObject) bool {
+			ev := o.value.(*Event)
+			return ev.Place != nil && set[ev.Place.Hlink]
+		}, nil
+	}
+	return nil, nil
+}
+
+// placeRule returns a matcher for the rules Gramps offers for places, or
+// nil if class is not one of them.
+func (c *filterContext) placeRule(class string, a ruleArgs) (filterMatcher, error) {
+	switch class {
+	case "HasTitle":
+		match, err := a.text(0)
+		if err != nil {
+			return nil, err
+		}
+		return func(o primaryObject) bool { return match(c.placeTitle(o.value.(*Placeobj), Date{})) }, nil
+
+	case "HasData":
+		name, err := a.text(0)
+		if err != nil {
+			return nil, err
+		}
+		code, err := a.text(2)
+		if err != nil {
+			return nil, err
+		}
+		placeType, _ := ParsePlaceType(a.arg(1))
+		return func(o primaryObject) bool {
+			p := o.value.(*Placeobj)
+			return slices.ContainsFunc(p.Pname, func(n Pname) bool { return name(n.Value) }) &&
+				(a.arg(1) == "" || p.Type == string(placeType)) &&
+				(a.arg(2) == "" || code(strval(p.Code)))
+		}, nil
+
+	case "IsEnclosedBy":
+		var root string
+		for _, o := range c.objects[targetPlace] {
+			if strval(*o.id) == a.arg(0) {
+				root = o.handle
+				break
+			}
+		}
+		return func(o primaryObject) bool {
+			if root == "" {
+				return false
+			}
+			if o.handle == root {
+				return a.flag(1)
+			}
+			seen := map[string]bool{o.handle: true}
+			queue := []string{o.handle}
+			for len(queue) > 0 {
+				p := c.ix.Place(queue[0])
+				queue = queue[1:]
+				if p == nil {
+					continue
+				}
+				for _, pr := range p.Placeref {
+					if pr.Hlink == root {
+						return true
+					}
+					if !seen[pr.Hlink] {
+						seen[pr.Hlink] = true
+						queue = append(queue, pr.Hlink)
+					}
+				}
+			}
+			return false
+		}, nil
+
+	case "MatchesEventFilter":
+		set, err := c.filterSet(targetEvent, a, 0)
+		if err != nil {
+			return nil, err
+		}
+		places := make(map[string]bool)
+		for _, o := range c.objects[targetEvent] {
+			if ev := o.value.(*Event); set[o.handle] && ev.Place != nil {
+				places[ev.Place.Hlink] = true
+			}
+		}
+		return func(o primaryObject) bool { return places[o.handle] }, nil
+
+	case "HasNoLatLon":
+		return func(o primaryObject) bool {
+			co := o.value.(*Placeobj).Coord
+			return co == nil || co.Lat == "" || co.Long == ""
+		}, nil
+	}
+	return nil, nil
+}
+
+// sourceRule returns a matcher for the rules Gramps offers for sources, or
+// nil if class is not one of them.
+func (c *filterContext) sourceRule(class string, a ruleArgs) (filterMatcher, error) {
+	source := func(fn func(s *Source) bool) filterMatcher {
+		return func(o primaryObject) bool { return fn(o.value.(*Source)) }
+	}
+	switch class {
+	case "HasTitle", "MatchesTitleSubstringOf":
+		match, err := ruleArgs{rule: withRegex(a.rule, a.rule.UseRegex && class == "HasTitle")}.text(0)
+		if err != nil {
+			return nil, err
+		}
+		return source(func(s *Source) bool { return match(strval(s.Stitle)) }), nil
+
+	case "HasSource":
+		var fields [3]func(string) bool
+		for i := range fields {
+			m, err := a.text(i)
+			if err != nil {
+				return nil, err
+			}
+			fields[i] = m
+		}
+		return source(func(s *Source) bool {
+			return fields[0](strval(s.Stitle)) && fields[1](strval(s.Sauthor)) && fields[2](strval(s.Spubinfo))
+		}), nil
+
+	case "HasRepository":
+		return countMatcher(a, 0, func(o primaryObject) int { return len(o.value.(*Source).Reporef) })
+
+	case "HasRepositoryCallNumberRef":
+		match, err := a.text(0)
+		if err != nil {
+			return nil, err
+		}
+		return source(func(s *Source) bool {
+			return slices.ContainsFunc(s.Reporef, func(r Reporef) bool { return match(strval(r.Callno)) })
+		}), nil
+	}
+	return nil, nil
+}
+
+// eventMatcher returns a function that reports whether an event matches
+// the type, date, place and description given by the arguments with the
+// given indexes. A negative index means the rule has no such argument.
+func (c *filterContext) eventMatcher(a ruleArgs, typeArg, dateArg, placeArg, descArg int) (func(*Event) bool, error) {
+	var eventType EventType
+	if typeArg >= 0 {
+		eventType, _ = ParseEventType(a.arg(typeArg))
+	}
+	date := a.date(dateArg)
+	place, err := a.text(placeArg)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := a.text(descArg)
+	if err != nil {
+		return nil, err
+	}
+	return func(ev *Event) bool {
+		if eventType != "" && strval(ev.Type) != string(eventType) {
+			return false
+		}
+		if a.arg(dateArg) != "" && !date(ev.Date()) {
+			return false
+		}
+		if a.arg(placeArg) != "" {
+			if ev.Place == nil {
+				return false
+			}
+			p := c.ix.Place(ev.Place.Hlink)
+			if p == nil || !place(c.placeTitle(p, ev.Date())) {
+				return false
+			}
+		}
+		return desc(strval(ev.Description))
+	}, nil
+}
+
+// incompleteEvent reports whether any of the events referred to lacks a
+// date or a place.
+func (c *filterContext) incompleteEvent(refs []Eventref) bool {
+	return slices.ContainsFunc(refs, func(er Eventref) bool {
+		ev := c.ix.Event(er.Hlink)
+		return ev != nil && (ev.Date().IsZero() || ev.Place == nil)
+	})
+}
+
+// placeTitle returns the title of p, as given or as generated by Gramps.
+func (c *filterContext) placeTitle(p *Placeobj, date Date) string {
+	if t := strval(p.Ptitle); t != "" {
+		return t
+	}
+	t, _ := c.ix.PlaceTitle(p, date, PlaceFormat{})
+	return t
+}
+
+// nameMatcher returns a function that reports whether a name matches the
+// arguments of a HasNameOf rule, which are the given name, full family
+// name, title, suffix, call name, nickname, surname prefix, single surname,
+// connector, patronymic and family nickname.
+func nameMatcher(a ruleArgs) (func(Name) bool, error) {
+	var m [11]func(string) bool
+	for i := range m {
+		f, err := a.text(i)
+		if err != nil {
+			return nil, err
+		}
+		m[i] = f
+	}
+	set := func(i int) bool { return a.arg(i) != "" }
+	anySurname := func(n Name, i int, value func(Surname) string) bool {
+		return !set(i) || slices.ContainsFunc(n.Surname, func(s Surname) bool { return m[i](value(s)) })
+	}
+	return func(n Name) bool {
+		var family []string
+		for _, s := range n.Surname {
+			for _, v := range []string{strval(s.Prefix), s.Surname, strval(s.Connector)} {
+				if v != "" {
+					family = append(family, v)
+				}
+			}
+		}
+		return (!set(0) || m[0](strval(n.First))) &&
+			(!set(1) || m[1](strings.Join(family, " "))) &&
+			(!set(2) || m[2](strval(n.Title))) &&
+			(!set(3) || m[3](strval(n.Suffix))) &&
+			(!set(4) || m[4](strval(n.Call))) &&
+			(!set(5) || m[5](strval(n.Nick))) &&
+			anySurname(n, 6, func(s Surname) string { return strval(s.Prefix) }) &&
+			anySurname(n, 7, func(s Surname) string { return s.Surname }) &&
+			anySurname(n, 8, func(s Surname) string { return strval(s.Connector) }) &&
+			anySurname(n, 9, func(s Surname) string {
+				if strval(s.Derivation) != string(OriginPatronymic) {
+					return ""
+				}
+				return s.Surname
+			}) &&
+			(!set(10) || m[10](strval(n.Familynick)))
+	}, nil
+}
+
+// nameParts returns the non-empty parts of n that are searched by the
+// SearchName rule.
+func nameParts(n *Name) []string {
+	var parts []string
+	for _, v := range []string{strval(n.Title), strval(n.First), strval(n.Call), strval(n.Nick), strval(n.Suffix)} {
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+	for _, s := range n.Surname {
+		for _, v := range []string{strval(s.Prefix), s.Surname, strval(s.Connector)} {
+			if v != "" {
+				parts = append(parts, v)
+			}
+		}
+	}
+	return parts
+}
+
+func countMatcher(a ruleArgs, i int, count func(primaryObject) int) (filterMatcher, error) {
+	want, err := a.count(i)
+	if err != nil {
+		return nil, err
+	}
+	return func(o primaryObject) bool { return want(count(o)) }, nil
+}
+
+// time returns the time given by the ith argument, in the form
+// "2006-01-02 15:04:05" or "2006-01-02" in local time, or the zero time if
+// the argument is empty.
+func (a ruleArgs) time(i int) (time.Time, error) {
+	arg := a.arg(i)
+	if arg == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.DateTime, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, arg, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("rule %s: invalid time %q", a.rule.Class, arg)
+}
+
+// withRegex returns a copy of r that does or does not use regular
+// expressions.
+func withRegex(r *FilterRule, regex bool) *FilterRule {
+	c := *r
+	c.UseRegex = regex
+	return &c
+}
+
+// objectLinks returns the handles held by the list of references with the
+// given field name in the object, or nil if it has no such field.
+func objectLinks(o primaryObject, field string) []string {
+	f := reflect.ValueOf(o.value).Elem().FieldByName(field)
+	if !f.IsValid() || f.Kind() != reflect.Slice {
+		return nil
+	}
+	hs := make([]string, f.Len())
+	for i := range hs {
+		hs[i] = f.Index(i).FieldByName("Hlink").String()
+	}
+	return hs
+}
+
+func objectPrivate(o primaryObject) bool {
+	f := reflect.ValueOf(o.value).Elem().FieldByName("Priv")
+	return f.IsValid() && !f.IsNil() && f.Elem().Bool()
+}
@@ -0,0 +1,452 @@
+package grampsxml
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MapOptions selects the features written by [WriteGeoJSON] and [WriteKML].
+type MapOptions struct {
+	// OmitPlaces leaves out the feature written for each place.
+	OmitPlaces bool
+
+	// Events adds a feature for each event that took place at a place with
+	// coordinates, giving the event's type, date and participants.
+	Events bool
+
+	// Migrations adds a line for each person joining the places of their
+	// dated events in date order. The events of a person are those in
+	// which they have the primary role and the family events of the
+	// families in which they are a parent.
+	Migrations bool
+}
+
+// mapFeature is a feature on a map, independent of the format written.
+type mapFeature struct {
+	kind         string // "place", "event" or "migration"
+	handle       string
+	id           string
+	name         string
+	typ          string
+	date         Date
+	end          Date   // date of the last event of a migration
+	place        string // title of the place of an event
+	participants []mapParticipant
+	points       []mapPoint
+}
+
+type mapParticipant struct {
+	handle string
+	id     string
+	name   string
+	role   string
+}
+
+type mapPoint struct {
+	lat, long float64
+}
+
+type mapEvent struct {
+	ev       *Event
+	day      int // Julian day of the start of the date
+	point    mapPoint
+	position int
+}
+
+// mapFeatures returns the features selected by opts.
+func mapFeatures(db *Database, opts MapOptions) []mapFeature {
+	ix := NewIndex(db)
+	var features []mapFeature
+
+	points := make(map[string]mapPoint)
+	places := db.Places.list()
+	for i := range places {
+		p := &places[i]
+		if p.Coord == nil {
+			continue
+		}
+		lat, long, err := p.Coord.Degrees()
+		if err != nil {
+			continue
+		}
+		points[p.Handle] = mapPoint{lat, long}
+		if !opts.OmitPlaces {
+			features = append(features, mapFeature{
+				kind:   "place",
+				handle: p.Handle,
+				id:     strval(p.ID),
+				name:   mapPlaceTitle(ix, p),
+				typ:    p.Type,
+				points: []mapPoint{{lat, long}},
+			})
+		}
+	}
+
+	eventPoint := func(ev *Event) (mapPoint, bool) {
+		if ev.Place == nil {
+			return mapPoint{}, false
+		}
+		pt, ok := points[ev.Place.Hlink]
+		return pt, ok
+	}
+
+	if opts.Events {
+		participants := eventParticipants(ix, db)
+		events := db.Events.list()
+		for i := range events {
+			ev := &events[i]
+			pt, ok := eventPoint(ev)
+			if !ok {
+				continue
+			}
+			name := strval(ev.Type)
+			var principals []string
+			for _, pa := range participants[ev.Handle] {
+				if pa.role == "Primary" || pa.role == "Family" {
+					principals = append(principals, pa.name)
+				}
+			}
+			if len(principals) > 0 {
+				name += " of " + strings.Join(principals, " and ")
+			}
+			features = append(features, mapFeature{
+				kind:         "event",
+				handle:       ev.Handle,
+				id:           strval(ev.ID),
+				name:         name,
+				typ:          strval(ev.Type),
+				date:         ev.Date(),
+				place:        mapPlaceTitle(ix, ix.Place(ev.Place.Hlink)),
+				participants: participants[ev.Handle],
+				points:       []mapPoint{pt},
+			})
+		}
+	}
+
+	if opts.Migrations {
+		people := db.People.list()
+		for i := range people {
+			p := &people[i]
+			var evs []mapEvent
+			add := func(er Eventref, role string) {
+				if !isRole(er.Role, role) {
+					return
+				}
+				ev := ix.Event(er.Hlink)
+				if ev == nil {
+					return
+				}
+				day, ok := ev.Date().startDay()
+				pt, hasPoint := eventPoint(ev)
+				if ok && hasPoint {
+					evs = append(evs, mapEvent{ev: ev, day: day, point: pt, position: len(evs)})
+				}
+			}
+			for _, er := range p.Eventref {
+				add(er, "Primary")
+			}
+			for _, pi := range p.Parentin {
+				if f := ix.Family(pi.Hlink); f != nil {
+					for _, er := range f.Eventref {
+						add(er, "Family")
+					}
+				}
+			}
+			slices.SortFunc(evs, func(a, b mapEvent) int {
+				return cmp.Or(cmp.Compare(a.day, b.day), cmp.Compare(a.position, b.position))
+			})
+
+			var line []mapPoint
+			for _, e := range evs {
+				if len(line) == 0 || line[len(line)-1] != e.point {
+					line = append(line, e.point)
+				}
+			}
+			if len(line) < 2 {
+				continue
+			}
+			features = append(features, mapFeature{
+				kind:   "migration",
+				handle: p.Handle,
+				id:     strval(p.ID),
+				name:   fullName(primaryName(p)),
+				date:   evs[0].ev.Date(),
+				end:    evs[len(evs)-1].ev.Date(),
+				points: line,
+			})
+		}
+	}
+	return features
+}
+
+// mapPlaceTitle returns the title of p, falling back to its name if the
+// place hierarchy is not valid.
+func mapPlaceTitle(ix *Index, p *Placeobj) string {
+	if p.Ptitle != nil && *p.Ptitle != "" {
+		return *p.Ptitle
+	}
+	title, err := ix.PlaceTitle(p, Date{}, PlaceFormat{})
+	if err != nil {
+		return p.NameAt(today(), "")
+	}
+	return title
+}
+
+// eventParticipants returns the people taking part in each event, keyed by
+// event handle. The parents of a family are participants in the family's
+// events.
+func eventParticipants(ix *Index, db *Database) map[string][]mapParticipant {
+	participants := make(map[string][]mapParticipant)
+	addPerson := func(h, eventHandle, role string) {
+		p := ix.Person(h)
+		if p == nil {
+			return
+		}
+		participants[eventHandle] = append(participants[eventHandle], mapParticipant{
+			handle: p.Handle,
+			id:     strval(p.ID),
+			name:   fullName(primaryName(p)),
+			role:   role,
+		})
+	}
+	people := db.People.list()
+	for i := range people {
+		for _, er := range people[i].Eventref {
+			role := strval(er.Role)
+			if role == "" {
+				role = "Primary"
+			}
+			addPerson(people[i].Handle, er.Hlink, role)
+		}
+	}
+	families := db.Families.list()
+	for i := range families {
+		f := &families[i]
+		for _, er := range f.Eventref {
+			role := strval(er.Role)
+			if role == "" {
+				role = "Family"
+			}
+			for _, h := range familyParents(f) {
+				addPerson(h, er.Hlink, role)
+			}
+		}
+	}
+	return participants
+}
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string          `json:"type"`
+	Geometry   geoJSONGeometry `json:"geometry"`
+	Properties map[string]any  `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+type geoJSONParticipant struct {
+	Handle   string `json:"handle"`
+	GrampsID string `json:"gramps_id,omitempty"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+}
+
+// WriteGeoJSON writes the places in db that have valid coordinates to w as
+// a GeoJSON feature collection, with further features selected by opts.
+//
+// Every feature has the properties kind, which is "place", "event" or
+// "migration", handle, gramps_id and name. Places are points with a type
+// property. Events are points with type, date and place properties and a
+// participants property listing the handle, Gramps ID, name and role of
+// each person taking part. Migrations are lines whose handle, gramps_id
+// and name are those of the person, with date and end_date properties
+// giving the dates of their first and last events.
+func WriteGeoJSON(w io.Writer, db *Database, opts MapOptions) error {
+	fc := geoJSONFeatureCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+	for _, f := range mapFeatures(db, opts) {
+		props := map[string]any{
+			"kind":   f.kind,
+			"handle": f.handle,
+			"name":   f.name,
+		}
+		if f.id != "" {
+			props["gramps_id"] = f.id
+		}
+		if f.typ != "" {
+			props["type"] = f.typ
+		}
+		if !f.date.IsZero() {
+			props["date"] = f.date.String()
+		}
+		if !f.end.IsZero() {
+			props["end_date"] = f.end.String()
+		}
+		if f.place != "" {
+			props["place"] = f.place
+		}
+		if f.kind == "event" {
+			ps := []geoJSONParticipant{}
+			for _, pa := range f.participants {
+				ps = append(ps, geoJSONParticipant{Handle: pa.handle, GrampsID: pa.id, Name: pa.name, Role: pa.role})
+			}
+			props["participants"] = ps
+		}
+
+		coords := make([][]float64, len(f.points))
+		for i, pt := range f.points {
+			coords[i] = []float64{pt.long, pt.lat}
+		}
+		geom := geoJSONGeometry{Type: "LineString", Coordinates: coords}
+		if f.kind != "migration" {
+			geom = geoJSONGeometry{Type: "Point", Coordinates: coords[0]}
+		}
+		fc.Features = append(fc.Features, geoJSONFeature{Type: "Feature", Geometry: geom, Properties: props})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(fc)
+}
+
+type kmlDocument struct {
+	XMLName    xml.Name       `xml:"http://www.opengis.net/kml/2.2 kml"`
+	Placemarks []kmlPlacemark `xml:"Document>Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name         string        `xml:"name"`
+	Description  string        `xml:"description,omitempty"`
+	TimeStamp    *kmlTimeStamp `xml:"TimeStamp,omitempty"`
+	TimeSpan     *kmlTimeSpan  `xml:"TimeSpan,omitempty"`
+	ExtendedData []kmlData     `xml:"ExtendedData>Data,omitempty"`
+	Point        *kmlGeometry  `xml:"Point,omitempty"`
+	LineString   *kmlGeometry  `xml:"LineString,omitempty"`
+}
+
+type kmlTimeStamp struct {
+	When string `xml:"when"`
+}
+
+type kmlTimeSpan struct {
+	Begin string `xml:"begin,omitempty"`
+	End   string `xml:"end,omitempty"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlGeometry struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+// WriteKML writes the places in db that have valid coordinates to w as KML
+// placemarks, with further placemarks selected by opts.
+//
+// Placemarks carry the same information as the features written by
+// [WriteGeoJSON], held as extended data. Participants in events are listed
+// in the description, one per line. Dates of events and migrations are
+// also given as a KML time stamp or time span when they are Gregorian
+// dates without a modifier, so that they can be shown on a time line.
+func WriteKML(w io.Writer, db *Database, opts MapOptions) error {
+	doc := kmlDocument{}
+	for _, f := range mapFeatures(db, opts) {
+		pm := kmlPlacemark{Name: f.name}
+		data := func(name, value string) {
+			if value != "" {
+				pm.ExtendedData = append(pm.ExtendedData, kmlData{Name: name, Value: value})
+			}
+		}
+		data("kind", f.kind)
+		data("handle", f.handle)
+		data("gramps_id", f.id)
+		data("type", f.typ)
+		data("date", f.date.String())
+		data("end_date", f.end.String())
+		data("place", f.place)
+
+		var lines []string
+		for _, pa := range f.participants {
+			lines = append(lines, pa.name+" ("+pa.role+")")
+		}
+		pm.Description = strings.Join(lines, "\n")
+		begin, end := kmlWhen(f.date, true), kmlWhen(f.date, false)
+		if f.kind == "migration" {
+			end = kmlWhen(f.end, false)
+		}
+		switch {
+		case begin == "" || end == "":
+		case begin == end:
+			pm.TimeStamp = &kmlTimeStamp{When: begin}
+		default:
+			pm.TimeSpan = &kmlTimeSpan{Begin: begin, End: end}
+		}
+
+		coords := make([]string, len(f.points))
+		for i, pt := range f.points {
+			coords[i] = strconv.FormatFloat(pt.long, 'f', -1, 64) + "," + strconv.FormatFloat(pt.lat, 'f', -1, 64)
+		}
+		if f.kind == "migration" {
+			pm.LineString = &kmlGeometry{Coordinates: strings.Join(coords, " ")}
+		} else {
+			pm.Point = &kmlGeometry{Coordinates: coords[0]}
+		}
+		doc.Placemarks = append(doc.Placemarks, pm)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// kmlWhen returns the start or end of a Gregorian date without a modifier
+// or quality in the form used by KML, or "" for other dates. Unknown months
+// and days are left out, along with any day that follows an unknown month.
+func kmlWhen(d Date, start bool) string {
+	plain := func(quality, cformat *string) bool {
+		return strval(quality) == "" && strval(cformat) == ""
+	}
+	var val string
+	switch {
+	case d.Dateval != nil && strval(d.Dateval.Type) == "" && plain(d.Dateval.Quality, d.Dateval.Cformat):
+		val = d.Dateval.Val
+	case d.Daterange != nil && plain(d.Daterange.Quality, d.Daterange.Cformat):
+		val = d.Daterange.Stop
+		if start {
+			val = d.Daterange.Start
+		}
+	case d.Datespan != nil && plain(d.Datespan.Quality, d.Datespan.Cformat):
+		val = d.Datespan.Stop
+		if start {
+			val = d.Datespan.Start
+		}
+	}
+	y, m, day := parseDateValue(val)
+	if y == 0 {
+		return ""
+	}
+	if m == 0 {
+		day = 0
+	}
+	return formatDateValue(y, m, day)
+}
@@ -0,0 +1,258 @@
+package grampsxml
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+var geoSample = Database{
+	Places: &Places{
+		Place: []Placeobj{
+			{Handle: "_p1", ID: new("P0001"), Type: "Town", Pname: []Pname{{Value: "Greenfield"}}, Coord: &Coord{Lat: "53.5", Long: "-2.0"}},
+			{Handle: "_p2", ID: new("P0002"), Type: "City", Pname: []Pname{{Value: "Leeds"}}, Coord: &Coord{Lat: "N 53°47'49.2\"", Long: "1°32'56.4\"W"}},
+			{Handle: "_p3", ID: new("P0003"), Type: "Country", Pname: []Pname{{Value: "England"}}},
+		},
+	},
+	Events: &Events{
+		Event: []Event{
+			{Handle: "_e1", ID: new("E0001"), Type: new("Birth"), Dateval: &Dateval{Val: "1855-06-21"}, Place: &Place{Hlink: "_p1"}},
+			{Handle: "_e2", ID: new("E0002"), Type: new("Marriage"), Datespan: &Datespan{Start: "1879", Stop: "1880"}, Place: &Place{Hlink: "_p2"}},
+			{Handle: "_e3", ID: new("E0003"), Type: new("Census"), Dateval: &Dateval{Val: "1871"}, Place: &Place{Hlink: "_p1"}},
+			{Handle: "_e4", ID: new("E0004"), Type: new("Residence"), Place: &Place{Hlink: "_p3"}},
+		},
+	},
+	People: &People{
+		Person: []Person{
+			{
+				Handle:   "_i1",
+				ID:       new("I0001"),
+				Name:     []Name{{First: new("Lewis"), Surname: []Surname{{Surname: "Garner"}}}},
+				Eventref: []Eventref{{Hlink: "_e1"}, {Hlink: "_e3"}, {Hlink: "_e4"}},
+				Parentin: []Parentin{{Hlink: "_f1"}},
+			},
+			{
+				Handle:   "_i2",
+				ID:       new("I0002"),
+				Name:     []Name{{First: new("Anna"), Surname: []Surname{{Surname: "Hall"}}}},
+				Eventref: []Eventref{{Hlink: "_e3", Role: new("Witness")}},
+				Parentin: []Parentin{{Hlink: "_f1"}},
+			},
+		},
+	},
+	Families: &Families{
+		Family: []Family{
+			{Handle: "_f1", Father: &Father{Hlink: "_i1"}, Mother: &Mother{Hlink: "_i2"}, Eventref: []Eventref{{Hlink: "_e2"}}},
+		},
+	},
+}
+
+func TestWriteGeoJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteGeoJSON(&buf, &geoSample, MapOptions{OmitPlaces: true, Events: true, Migrations: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{
+  "type": "FeatureCollection",
+  "features": [
+    {
+      "type": "Feature",
+      "geometry": {"type": "Point", "coordinates": [-2, 53.5]},
+      "properties": {
+        "kind": "event", "handle": "_e1", "gramps_id": "E0001", "name": "Birth of Lewis Garner",
+        "type": "Birth", "date": "1855-06-21", "place": "Greenfield",
+        "participants": [{"handle": "_i1", "gramps_id": "I0001", "name": "Lewis Garner", "role": "Primary"}]
+      }
+    },
+    {
+      "type": "Feature",
+      "geometry": {"type": "Point", "coordinates": [-1.549, 53.797]},
+      "properties": {
+        "kind": "event", "handle": "_e2", "gramps_id": "E0002", "name": "Marriage of Lewis Garner and Anna Hall",
+        "type": "Marriage", "date": "from 1879 to 1880", "place": "Leeds",
+        "participants": [
+          {"handle": "_i1", "gramps_id": "I0001", "name": "Lewis Garner", "role": "Family"},
+          {"handle": "_i2", "gramps_id": "I0002", "name": "Anna Hall", "role": "Family"}
+        ]
+      }
+    },
+    {
+      "type": "Feature",
+      "geometry": {"type": "Point", "coordinates": [-2, 53.5]},
+      "properties": {
+        "kind": "event", "handle": "_e3", "gramps_id": "E0003", "name": "Census of Lewis Garner",
+        "type": "Census", "date": "1871", "place": "Greenfield",
+        "participants": [
+          {"handle": "_i1", "gramps_id": "I0001", "name": "Lewis Garner", "role": "Primary"},
+          {"handle": "_i2", "gramps_id": "I0002", "name": "Anna Hall", "role": "Witness"}
+        ]
+      }
+    },
+    {
+      "type": "Feature",
+      "geometry": {"type": "LineString", "coordinates": [[-2, 53.5], [-1.549, 53.797]]},
+      "properties": {
+        "kind": "migration", "handle": "_i1", "gramps_id": "I0001", "name": "Lewis Garner",
+        "date": "1855-06-21", "end_date": "from 1879 to 1880"
+      }
+    }
+  ]
+}`
+
+	var got, wantv any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantv); err != nil {
+		t.Fatalf("unexpected error in wanted JSON: %v", err)
+	}
+	if diff := cmp.Diff(wantv, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteKML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteKML(&buf, &geoSample, MapOptions{Migrations: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+  <Document>
+    <Placemark>
+      <name>Greenfield</name>
+      <ExtendedData>
+        <Data name="kind">
+          <value>place</value>
+        </Data>
+        <Data name="handle">
+          <value>_p1</value>
+        </Data>
+        <Data name="gramps_id">
+          <value>P0001</value>
+        </Data>
+        <Data name="type">
+          <value>Town</value>
+        </Data>
+      </ExtendedData>
+      <Point>
+        <coordinates>-2,53.5</coordinates>
+      </Point>
+    </Placemark>
+    <Placemark>
+      <name>Leeds</name>
+      <ExtendedData>
+        <Data name="kind">
+          <value>place</value>
+        </Data>
+        <Data name="handle">
+          <value>_p2</value>
+        </Data>
+        <Data name="gramps_id">
+          <value>P0002</value>
+        </Data>
+        <Data name="type">
+          <value>City</value>
+        </Data>
+      </ExtendedData>
+      <Point>
+        <coordinates>-1.549,53.797</coordinates>
+      </Point>
+    </Placemark>
+    <Placemark>
+      <name>Lewis Garner</name>
+      <TimeSpan>
+        <begin>1855-06-21</begin>
+        <end>1880</end>
+      </TimeSpan>
+      <ExtendedData>
+        <Data name="kind">
+          <value>migration</value>
+        </Data>
+        <Data name="handle">
+          <value>_i1</value>
+        </Data>
+        <Data name="gramps_id">
+          <value>I0001</value>
+        </Data>
+        <Data name="date">
+          <value>1855-06-21</value>
+        </Data>
+        <Data name="end_date">
+          <value>from 1879 to 1880</value>
+        </Data>
+      </ExtendedData>
+      <LineString>
+        <coordinates>-2,53.5 -1.549,53.797</coordinates>
+      </LineString>
+    </Placemark>
+  </Document>
+</kml>
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMapFeaturesMigrationOrder(t *testing.T) {
+	db := &Database{
+		Places: geoSample.Places,
+		Events: &Events{
+			Event: []Event{
+				{Handle: "_e1", Type: new("Death"), Dateval: &Dateval{Val: "1900", Type: new("before")}, Place: &Place{Hlink: "_p1"}},
+				{Handle: "_e2", Type: new("Birth"), Dateval: &Dateval{Val: "1850", Type: new("about")}, Place: &Place{Hlink: "_p1"}},
+				{Handle: "_e3", Type: new("Census"), Dateval: &Dateval{Val: "1820"}, Place: &Place{Hlink: "_p2"}},
+			},
+		},
+		People: &People{
+			Person: []Person{
+				{Handle: "_i1", Eventref: []Eventref{{Hlink: "_e1"}, {Hlink: "_e2"}, {Hlink: "_e3"}}},
+			},
+		},
+	}
+
+	features := mapFeatures(db, MapOptions{OmitPlaces: true, Migrations: true})
+	if len(features) != 1 {
+		t.Fatalf("got %d features, wanted 1", len(features))
+	}
+	want := []mapPoint{{lat: 53.797, long: -1.549}, {lat: 53.5, long: -2}}
+	if diff := cmp.Diff(want, features[0].points, cmp.AllowUnexported(mapPoint{}), cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("points mismatch (-want +got):\n%s", diff)
+	}
+	if got := features[0].date.String(); got != "1820" {
+		t.Errorf("got start date %q, wanted 1820", got)
+	}
+	if got := features[0].end.String(); got != "before 1900" {
+		t.Errorf("got end date %q, wanted before 1900", got)
+	}
+}
+
+func TestKMLWhen(t *testing.T) {
+	testCases := []struct {
+		date  string
+		start bool
+		want  string
+	}{
+		{"1850-03-12", true, "1850-03-12"},
+		{"1850-03", true, "1850-03"},
+		{"1850-??", true, "1850"},
+		{"1850-??-12", true, "1850"},
+		{"1850-03-??", true, "1850-03"},
+		{"????-03-12", true, ""},
+		{"between 1850-??-01 and 1860-05", true, "1850"},
+		{"between 1850-??-01 and 1860-05", false, "1860-05"},
+		{"about 1850", true, ""},
+		{"1850 (Julian)", true, ""},
+	}
+
+	for _, tc := range testCases {
+		if got := kmlWhen(ParseDate(tc.date), tc.start); got != tc.want {
+			t.Errorf("kmlWhen(%q, %v) = %q, wanted %q", tc.date, tc.start, got, tc.want)
+		}
+	}
+}
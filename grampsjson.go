@@ -0,0 +1,1675 @@
+package grampsxml
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// This file converts between the types in this package and the JSON
+// serialization of Gramps objects used by Gramps 5.2 and Gramps Web, in
+// which each object carries a _class member naming its Gramps class.
+//
+// Handles in Gramps JSON lack the leading underscore that Gramps adds when
+// writing XML; it is removed when encoding and restored when decoding.
+
+// gjType is the JSON form of a Gramps enumerated type. String holds the
+// text of custom values and is empty for standard values.
+type gjType struct {
+	Class  string `json:"_class"`
+	String string `json:"string"`
+	Value  int    `json:"value"`
+}
+
+// gjTypeOf encodes s, a value of the enumerated type t as written to XML.
+func gjTypeOf(t *grampsType, s string) gjType {
+	if v, ok := t.byXML(s); ok && v.code != t.custom {
+		return gjType{Class: t.class, Value: v.code}
+	}
+	return gjType{Class: t.class, String: s, Value: t.custom}
+}
+
+// gjTypeOfPtr encodes an optional value of t, treating nil as the value with
+// code def.
+func gjTypeOfPtr(t *grampsType, s *string, def int) gjType {
+	if s == nil {
+		return gjType{Class: t.class, Value: def}
+	}
+	return gjTypeOf(t, *s)
+}
+
+// xml returns the XML form of the value.
+func (v gjType) xml(t *grampsType) string {
+	if v.Value == t.custom || v.String != "" {
+		return v.String
+	}
+	if tv, ok := t.byCode(v.Value); ok {
+		return tv.xml
+	}
+	return ""
+}
+
+// xmlPtr returns the XML form of an optional value, or nil if it has code def.
+func (v gjType) xmlPtr(t *grampsType, def int) *string {
+	if v.Value == def && v.String == "" {
+		return nil
+	}
+	return new(v.xml(t))
+}
+
+// Date modifiers used by Gramps.
+const (
+	gjModNone     = 0
+	gjModBefore   = 1
+	gjModAfter    = 2
+	gjModAbout    = 3
+	gjModRange    = 4
+	gjModSpan     = 5
+	gjModTextOnly = 6
+	gjModFrom     = 7
+	gjModTo       = 8
+)
+
+var gjDateModifiers = map[string]int{
+	"":       gjModNone,
+	"before": gjModBefore,
+	"after":  gjModAfter,
+	"about":  gjModAbout,
+	"from":   gjModFrom,
+	"to":     gjModTo,
+}
+
+type gjDate struct {
+	Class    string  `json:"_class"`
+	Calendar int     `json:"calendar"`
+	Modifier int     `json:"modifier"`
+	Quality  int     `json:"quality"`
+	Dateval  []any   `json:"dateval"`
+	Text     string  `json:"text"`
+	Sortval  int     `json:"sortval"`
+	Newyear  int     `json:"newyear"`
+	Format   *string `json:"format"`
+}
+
+func gjDateOf(d Date) gjDate {
+	gd := gjDate{Class: "Date", Dateval: []any{0, 0, 0, false}}
+
+	var quality, cformat, newyear *string
+	var dualdated *bool
+	var start, stop string
+	switch {
+	case d.Dateval != nil:
+		v := d.Dateval
+		quality, cformat, newyear, dualdated = v.Quality, v.Cformat, v.Newyear, v.Dualdated
+		start = v.Val
+		gd.Modifier = gjDateModifiers[strval(v.Type)]
+	case d.Daterange != nil:
+		v := d.Daterange
+		quality, cformat, newyear, dualdated = v.Quality, v.Cformat, v.Newyear, v.Dualdated
+		start, stop = v.Start, v.Stop
+		gd.Modifier = gjModRange
+	case d.Datespan != nil:
+		v := d.Datespan
+		quality, cformat, newyear, dualdated = v.Quality, v.Cformat, v.Newyear, v.Dualdated
+		start, stop = v.Start, v.Stop
+		gd.Modifier = gjModSpan
+	case d.Datestr != nil:
+		gd.Modifier = gjModTextOnly
+		gd.Text = d.Datestr.Val
+		return gd
+	default:
+		return gd
+	}
+
+	gd.Quality = gjTypeOfPtr(dateQualityCodes, quality, 0).Value
+	gd.Calendar = gjTypeOfPtr(dateCalendars, cformat, 0).Value
+	gd.Newyear = gjTypeOfPtr(dateNewYears, newyear, 0).Value
+	slash := dualdated != nil && *dualdated
+
+	y, m, dd := parseDateValue(start)
+	gd.Dateval = []any{dd, m, y, slash}
+	if gd.Modifier == gjModRange || gd.Modifier == gjModSpan {
+		y2, m2, d2 := parseDateValue(stop)
+		gd.Dateval = append(gd.Dateval, d2, m2, y2, slash)
+	}
+	gd.Sortval = julianDay(strval(cformat), y, m, dd)
+	return gd
+}
+
+func (gd *gjDate) date() Date {
+	n := func(i int) int {
+		if i < len(gd.Dateval) {
+			if f, ok := gd.Dateval[i].(float64); ok {
+				return int(f)
+			}
+			if v, ok := gd.Dateval[i].(int); ok {
+				return v
+			}
+		}
+		return 0
+	}
+	slash := func(i int) *bool {
+		if i < len(gd.Dateval) {
+			if b, ok := gd.Dateval[i].(bool); ok && b {
+				return new(true)
+			}
+		}
+		return nil
+	}
+
+	if gd.Modifier == gjModTextOnly {
+		return Date{Datestr: &Datestr{Val: gd.Text}}
+	}
+	if gd.Modifier == gjModNone && n(0) == 0 && n(1) == 0 && n(2) == 0 {
+		return Date{}
+	}
+
+	quality := gjType{Value: gd.Quality}.xmlPtr(dateQualityCodes, 0)
+	cformat := gjType{Value: gd.Calendar}.xmlPtr(dateCalendars, 0)
+	newyear := gjType{Value: gd.Newyear}.xmlPtr(dateNewYears, 0)
+	start := formatDateValue(n(2), n(1), n(0))
+
+	switch gd.Modifier {
+	case gjModRange:
+		return Date{Daterange: &Daterange{Start: start, Stop: formatDateValue(n(6), n(5), n(4)), Quality: quality, Cformat: cformat, Dualdated: slash(3), Newyear: newyear}}
+	case gjModSpan:
+		return Date{Datespan: &Datespan{Start: start, Stop: formatDateValue(n(6), n(5), n(4)), Quality: quality, Cformat: cformat, Dualdated: slash(3), Newyear: newyear}}
+	}
+	v := &Dateval{Val: start, Quality: quality, Cformat: cformat, Dualdated: slash(3), Newyear: newyear}
+	for mod, code := range gjDateModifiers {
+		if code == gd.Modifier && mod != "" {
+			v.Type = new(mod)
+		}
+	}
+	return Date{Dateval: v}
+}
+
+// Handle conversion. Gramps JSON handles have no leading underscore.
+
+func gjHandle(h string) string {
+	return strings.TrimPrefix(h, "_")
+}
+
+func xmlHandle(h string) string {
+	if h == "" {
+		return ""
+	}
+	return "_" + h
+}
+
+// gjHandles returns the handles in refs, where hlink extracts the handle
+// from each reference. The result is never nil since Gramps JSON writes
+// empty lists as [].
+func gjHandles[T any](refs []T, hlink func(T) string) []string {
+	hs := make([]string, 0, len(refs))
+	for _, r := range refs {
+		hs = append(hs, gjHandle(hlink(r)))
+	}
+	return hs
+}
+
+func xmlRefs[T any](hs []string, ref func(string) T) []T {
+	if len(hs) == 0 {
+		return nil
+	}
+	refs := make([]T, 0, len(hs))
+	for _, h := range hs {
+		refs = append(refs, ref(xmlHandle(h)))
+	}
+	return refs
+}
+
+func gjList[T, U any](s []T, conv func(*T) U) []U {
+	out := make([]U, 0, len(s))
+	for i := range s {
+		out = append(out, conv(&s[i]))
+	}
+	return out
+}
+
+func xmlList[T, U any](s []T, conv func(*T) U) []U {
+	if len(s) == 0 {
+		return nil
+	}
+	out := make([]U, 0, len(s))
+	for i := range s {
+		out = append(out, conv(&s[i]))
+	}
+	return out
+}
+
+func citationHlink(r Citationref) string { return r.Hlink }
+func noteHlink(r Noteref) string         { return r.Hlink }
+func tagHlink(r Tagref) string           { return r.Hlink }
+
+func citationRef(h string) Citationref { return Citationref{Hlink: h} }
+func noteRef(h string) Noteref         { return Noteref{Hlink: h} }
+func tagRef(h string) Tagref           { return Tagref{Hlink: h} }
+
+func boolval(b *bool) bool {
+	return b != nil && *b
+}
+
+// boolptr returns a pointer to true, or nil for false, matching the way
+// Gramps omits false flags from XML.
+func boolptr(b bool) *bool {
+	if !b {
+		return nil
+	}
+	return new(true)
+}
+
+func gjChange(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+func xmlChange(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+func optstr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return new(s)
+}
+
+// Secondary objects.
+
+type gjSurname struct {
+	Class      string `json:"_class"`
+	Surname    string `json:"surname"`
+	Prefix     string `json:"prefix"`
+	Primary    bool   `json:"primary"`
+	Origintype gjType `json:"origintype"`
+	Connector  string `json:"connector"`
+}
+
+type gjName struct {
+	Class        string      `json:"_class"`
+	Private      bool        `json:"private"`
+	SurnameList  []gjSurname `json:"surname_list"`
+	CitationList []string    `json:"citation_list"`
+	NoteList     []string    `json:"note_list"`
+	Date         gjDate      `json:"date"`
+	FirstName    string      `json:"first_name"`
+	Suffix       string      `json:"suffix"`
+	Title        string      `json:"title"`
+	Type         gjType      `json:"type"`
+	GroupAs      string      `json:"group_as"`
+	SortAs       int         `json:"sort_as"`
+	DisplayAs    int         `json:"display_as"`
+	Call         string      `json:"call"`
+	Nick         string      `json:"nick"`
+	Famnick      string      `json:"famnick"`
+}
+
+func gjNameOf(n *Name) gjName {
+	sortAs, _ := strconv.Atoi(strval(n.Sort))
+	displayAs, _ := strconv.Atoi(strval(n.Display))
+	return gjName{
+		Class:   "Name",
+		Private: boolval(n.Priv),
+		SurnameList: gjList(n.Surname, func(s *Surname) gjSurname {
+			return gjSurname{
+				Class:      "Surname",
+				Surname:    s.Surname,
+				Prefix:     strval(s.Prefix),
+				Primary:    s.Prim == nil || *s.Prim,
+				Origintype: gjTypeOfPtr(nameOriginType, s.Derivation, 1),
+				Connector:  strval(s.Connector),
+			}
+		}),
+		CitationList: gjHandles(n.Citationref, citationHlink),
+		NoteList:     gjHandles(n.Noteref, noteHlink),
+		Date:         gjDateOf(n.Date()),
+		FirstName:    strval(n.First),
+		Suffix:       strval(n.Suffix),
+		Title:        strval(n.Title),
+		Type:         gjTypeOfPtr(nameType, n.Type, 2),
+		GroupAs:      strval(n.Group),
+		SortAs:       sortAs,
+		DisplayAs:    displayAs,
+		Call:         strval(n.Call),
+		Nick:         strval(n.Nick),
+		Famnick:      strval(n.Familynick),
+	}
+}
+
+func (gn *gjName) name() Name {
+	n := Name{
+		Priv: boolptr(gn.Private),
+		Surname: xmlList(gn.SurnameList, func(s *gjSurname) Surname {
+			sn := Surname{
+				Surname:    s.Surname,
+				Prefix:     optstr(s.Prefix),
+				Derivation: s.Origintype.xmlPtr(nameOriginType, 1),
+				Connector:  optstr(s.Connector),
+			}
+			if !s.Primary {
+				sn.Prim = new(false)
+			}
+			return sn
+		}),
+		Citationref: xmlRefs(gn.CitationList, citationRef),
+		Noteref:     xmlRefs(gn.NoteList, noteRef),
+		First:       optstr(gn.FirstName),
+		Suffix:      optstr(gn.Suffix),
+		Title:       optstr(gn.Title),
+		Type:        new(gn.Type.xml(nameType)),
+		Group:       optstr(gn.GroupAs),
+		Call:        optstr(gn.Call),
+		Nick:        optstr(gn.Nick),
+		Familynick:  optstr(gn.Famnick),
+	}
+	if gn.SortAs != 0 {
+		n.Sort = new(strconv.Itoa(gn.SortAs))
+	}
+	if gn.DisplayAs != 0 {
+		n.Display = new(strconv.Itoa(gn.DisplayAs))
+	}
+	n.SetDate(gn.Date.date())
+	return n
+}
+
+type gjAttribute struct {
+	Class        string   `json:"_class"`
+	Private      bool     `json:"private"`
+	CitationList []string `json:"citation_list"`
+	NoteList     []string `json:"note_list"`
+	Type         gjType   `json:"type"`
+	Value        string   `json:"value"`
+}
+
+func gjAttributeOf(a *Attribute) gjAttribute {
+	return gjAttribute{
+		Class:        "Attribute",
+		Private:      boolval(a.Priv),
+		CitationList: gjHandles(a.Citationref, citationHlink),
+		NoteList:     []string{},
+		Type:         gjTypeOf(attributeType, a.Type),
+		Value:        a.Value,
+	}
+}
+
+func (ga *gjAttribute) attribute() Attribute {
+	return Attribute{
+		Priv:        boolptr(ga.Private),
+		Citationref: xmlRefs(ga.CitationList, citationRef),
+		Type:        ga.Type.xml(attributeType),
+		Value:       ga.Value,
+	}
+}
+
+type gjSrcAttribute struct {
+	Class   string `json:"_class"`
+	Private bool   `json:"private"`
+	Type    gjType `json:"type"`
+	Value   string `json:"value"`
+}
+
+func gjSrcAttributeOf(a *Srcattribute) gjSrcAttribute {
+	return gjSrcAttribute{Class: "SrcAttribute", Private: boolval(a.Priv), Type: gjTypeOf(srcAttributeType, a.Type), Value: a.Value}
+}
+
+func (ga *gjSrcAttribute) srcattribute() Srcattribute {
+	return Srcattribute{Priv: boolptr(ga.Private), Type: ga.Type.xml(srcAttributeType), Value: ga.Value}
+}
+
+type gjEventRef struct {
+	Class         string        `json:"_class"`
+	Private       bool          `json:"private"`
+	CitationList  []string      `json:"citation_list"`
+	NoteList      []string      `json:"note_list"`
+	AttributeList []gjAttribute `json:"attribute_list"`
+	Ref           string        `json:"ref"`
+	Role          gjType        `json:"role"`
+}
+
+func gjEventRefOf(r *Eventref) gjEventRef {
+	return gjEventRef{
+		Class:         "EventRef",
+		Private:       boolval(r.Priv),
+		CitationList:  []string{},
+		NoteList:      gjHandles(r.Noteref, noteHlink),
+		AttributeList: gjList(r.Attribute, gjAttributeOf),
+		Ref:           gjHandle(r.Hlink),
+		Role:          gjTypeOfPtr(eventRoleType, r.Role, 1),
+	}
+}
+
+func (gr *gjEventRef) eventref() Eventref {
+	return Eventref{
+		Hlink:     xmlHandle(gr.Ref),
+		Priv:      boolptr(gr.Private),
+		Role:      new(gr.Role.xml(eventRoleType)),
+		Attribute: xmlList(gr.AttributeList, (*gjAttribute).attribute),
+		Noteref:   xmlRefs(gr.NoteList, noteRef),
+	}
+}
+
+type gjAddress struct {
+	Class        string   `json:"_class"`
+	Private      bool     `json:"private"`
+	CitationList []string `json:"citation_list"`
+	NoteList     []string `json:"note_list"`
+	Date         gjDate   `json:"date"`
+	Street       string   `json:"street"`
+	Locality     string   `json:"locality"`
+	City         string   `json:"city"`
+	County       string   `json:"county"`
+	State        string   `json:"state"`
+	Country      string   `json:"country"`
+	Postal       string   `json:"postal"`
+	Phone        string   `json:"phone"`
+}
+
+func gjAddressOf(a *Address) gjAddress {
+	return gjAddress{
+		Class:        "Address",
+		CitationList: gjHandles(a.Citationref, citationHlink),
+		NoteList:     gjHandles(a.Noteref, noteHlink),
+		Date:         gjDateOf(a.Date()),
+		Street:       strval(a.Street),
+		Locality:     strval(a.Locality),
+		City:         strval(a.City),
+		County:       strval(a.County),
+		State:        strval(a.State),
+		Country:      strval(a.Country),
+		Postal:       strval(a.Postal),
+		Phone:        strval(a.Phone),
+	}
+}
+
+func (ga *gjAddress) address() Address {
+	a := Address{
+		Citationref: xmlRefs(ga.CitationList, citationRef),
+		Noteref:     xmlRefs(ga.NoteList, noteRef),
+		Street:      optstr(ga.Street),
+		Locality:    optstr(ga.Locality),
+		City:        optstr(ga.City),
+		County:      optstr(ga.County),
+		State:       optstr(ga.State),
+		Country:     optstr(ga.Country),
+		Postal:      optstr(ga.Postal),
+		Phone:       optstr(ga.Phone),
+	}
+	a.SetDate(ga.Date.date())
+	return a
+}
+
+type gjURL struct {
+	Class   string `json:"_class"`
+	Private bool   `json:"private"`
+	Path    string `json:"path"`
+	Desc    string `json:"desc"`
+	Type    gjType `json:"type"`
+}
+
+func gjURLOf(u *Url) gjURL {
+	return gjURL{Class: "Url", Private: boolval(u.Priv), Path: u.Href, Desc: strval(u.Description), Type: gjTypeOfPtr(urlType, u.Type, -1)}
+}
+
+func (gu *gjURL) url() Url {
+	return Url{Priv: boolptr(gu.Private), Href: gu.Path, Description: optstr(gu.Desc), Type: gu.Type.xmlPtr(urlType, -1)}
+}
+
+type gjMediaRef struct {
+	Class         string        `json:"_class"`
+	Private       bool          `json:"private"`
+	CitationList  []string      `json:"citation_list"`
+	NoteList      []string      `json:"note_list"`
+	AttributeList []gjAttribute `json:"attribute_list"`
+	Ref           string        `json:"ref"`
+	Rect          []int         `json:"rect"`
+}
+
+func gjMediaRefOf(r *Objref) gjMediaRef {
+	gr := gjMediaRef{
+		Class:         "MediaRef",
+		Private:       boolval(r.Priv),
+		CitationList:  []string{},
+		NoteList:      []string{},
+		AttributeList: []gjAttribute{},
+		Ref:           gjHandle(r.Hlink),
+	}
+	if rg := r.Region; rg != nil {
+		intval := func(p *int) int {
+			if p == nil {
+				return 0
+			}
+			return *p
+		}
+		gr.Rect = []int{intval(rg.Corner1x), intval(rg.Corner1y), intval(rg.Corner2x), intval(rg.Corner2y)}
+	}
+	return gr
+}
+
+func (gr *gjMediaRef) objref() Objref {
+	r := Objref{Hlink: xmlHandle(gr.Ref), Priv: boolptr(gr.Private)}
+	if len(gr.Rect) == 4 {
+		r.Region = &Region{Corner1x: new(gr.Rect[0]), Corner1y: new(gr.Rect[1]), Corner2x: new(gr.Rect[2]), Corner2y: new(gr.Rect[3])}
+	}
+	return r
+}
+
+type gjPersonRef struct {
+	Class        string   `json:"_class"`
+	Private      bool     `json:"private"`
+	CitationList []string `json:"citation_list"`
+	NoteList     []string `json:"note_list"`
+	Ref          string   `json:"ref"`
+	Rel          string   `json:"rel"`
+}
+
+func gjPersonRefOf(r *Personref) gjPersonRef {
+	return gjPersonRef{
+		Class:        "PersonRef",
+		Private:      boolval(r.Priv),
+		CitationList: gjHandles(r.Citationref, citationHlink),
+		NoteList:     gjHandles(r.Noteref, noteHlink),
+		Ref:          gjHandle(r.Hlink),
+		Rel:          r.Rel,
+	}
+}
+
+func (gr *gjPersonRef) personref() Personref {
+	return Personref{
+		Hlink:       xmlHandle(gr.Ref),
+		Priv:        boolptr(gr.Private),
+		Rel:         gr.Rel,
+		Citationref: xmlRefs(gr.CitationList, citationRef),
+		Noteref:     xmlRefs(gr.NoteList, noteRef),
+	}
+}
+
+type gjChildRef struct {
+	Class        string   `json:"_class"`
+	Private      bool     `json:"private"`
+	CitationList []string `json:"citation_list"`
+	NoteList     []string `json:"note_list"`
+	Ref          string   `json:"ref"`
+	Frel         gjType   `json:"frel"`
+	Mrel         gjType   `json:"mrel"`
+}
+
+func gjChildRefOf(r *Childref) gjChildRef {
+	return gjChildRef{
+		Class:        "ChildRef",
+		Private:      boolval(r.Priv),
+		CitationList: []string{},
+		NoteList:     []string{},
+		Ref:          gjHandle(r.Hlink),
+		Frel:         gjTypeOfPtr(childRefType, r.Frel, 1),
+		Mrel:         gjTypeOfPtr(childRefType, r.Mrel, 1),
+	}
+}
+
+func (gr *gjChildRef) childref() Childref {
+	return Childref{
+		Hlink: xmlHandle(gr.Ref),
+		Priv:  boolptr(gr.Private),
+		Frel:  gr.Frel.xmlPtr(childRefType, 1),
+		Mrel:  gr.Mrel.xmlPtr(childRefType, 1),
+	}
+}
+
+type gjLdsOrd struct {
+	Class        string   `json:"_class"`
+	Private      bool     `json:"private"`
+	CitationList []string `json:"citation_list"`
+	NoteList     []string `json:"note_list"`
+	Date         gjDate   `json:"date"`
+	Type         int      `json:"type"`
+	Place        string   `json:"place"`
+	Famc         string   `json:"famc"`
+	Temple       string   `json:"temple"`
+	Status       int      `json:"status"`
+}
+
+func gjLdsOrdOf(l *LdsOrd) gjLdsOrd {
+	gl := gjLdsOrd{
+		Class:        "LdsOrd",
+		Private:      boolval(l.Priv),
+		CitationList: gjHandles(l.Citationref, citationHlink),
+		NoteList:     gjHandles(l.Noteref, noteHlink),
+		Date:         gjDateOf(l.Date()),
+		Type:         gjTypeOf(ldsOrdType, l.Type).Value,
+	}
+	if l.Place != nil {
+		gl.Place = gjHandle(l.Place.Hlink)
+	}
+	if l.SealedTo != nil {
+		gl.Famc = gjHandle(l.SealedTo.Hlink)
+	}
+	if l.Temple != nil {
+		gl.Temple = l.Temple.Val
+	}
+	if l.Status != nil {
+		gl.Status = gjTypeOf(ldsOrdStatus, l.Status.Val).Value
+	}
+	return gl
+}
+
+func (gl *gjLdsOrd) ldsord() LdsOrd {
+	l := LdsOrd{
+		Priv:        boolptr(gl.Private),
+		Citationref: xmlRefs(gl.CitationList, citationRef),
+		Noteref:     xmlRefs(gl.NoteList, noteRef),
+		Type:        gjType{Value: gl.Type}.xml(ldsOrdType),
+	}
+	if gl.Place != "" {
+		l.Place = &Place{Hlink: xmlHandle(gl.Place)}
+	}
+	if gl.Famc != "" {
+		l.SealedTo = &SealedTo{Hlink: xmlHandle(gl.Famc)}
+	}
+	if gl.Temple != "" {
+		l.Temple = &Temple{Val: gl.Temple}
+	}
+	if gl.Status != 0 {
+		l.Status = &Status{Val: gjType{Value: gl.Status}.xml(ldsOrdStatus)}
+	}
+	l.SetDate(gl.Date.date())
+	return l
+}
+
+// Primary objects.
+
+type gjPerson struct {
+	Class            string        `json:"_class"`
+	Handle           string        `json:"handle"`
+	Change           int64         `json:"change"`
+	Private          bool          `json:"private"`
+	TagList          []string      `json:"tag_list"`
+	GrampsID         string        `json:"gramps_id"`
+	Gender           int           `json:"gender"`
+	PrimaryName      gjName        `json:"primary_name"`
+	AlternateNames   []gjName      `json:"alternate_names"`
+	DeathRefIndex    int           `json:"death_ref_index"`
+	BirthRefIndex    int           `json:"birth_ref_index"`
+	EventRefList     []gjEventRef  `json:"event_ref_list"`
+	FamilyList       []string      `json:"family_list"`
+	ParentFamilyList []string      `json:"parent_family_list"`
+	MediaList        []gjMediaRef  `json:"media_list"`
+	AddressList      []gjAddress   `json:"address_list"`
+	AttributeList    []gjAttribute `json:"attribute_list"`
+	Urls             []gjURL       `json:"urls"`
+	LdsOrdList       []gjLdsOrd    `json:"lds_ord_list"`
+	CitationList     []string      `json:"citation_list"`
+	NoteList         []string      `json:"note_list"`
+	PersonRefList    []gjPersonRef `json:"person_ref_list"`
+}
+
+func gjPersonOf(ix *Index, p *Person) gjPerson {
+	gp := gjPerson{
+		Class:            "Person",
+		Handle:           gjHandle(p.Handle),
+		Change:           gjChange(p.Change),
+		Private:          boolval(p.Priv),
+		TagList:          gjHandles(p.Tagref, tagHlink),
+		GrampsID:         strval(p.ID),
+		Gender:           gjTypeOf(genderType, p.Gender).Value,
+		PrimaryName:      gjName{Class: "Name", SurnameList: []gjSurname{}, CitationList: []string{}, NoteList: []string{}, Date: gjDateOf(Date{}), Type: gjTypeOf(nameType, "Birth Name")},
+		AlternateNames:   []gjName{},
+		DeathRefIndex:    -1,
+		BirthRefIndex:    -1,
+		EventRefList:     gjList(p.Eventref, gjEventRefOf),
+		FamilyList:       gjHandles(p.Parentin, func(r Parentin) string { return r.Hlink }),
+		ParentFamilyList: gjHandles(p.Childof, func(r Childof) string { return r.Hlink }),
+		MediaList:        gjList(p.Objref, gjMediaRefOf),
+		AddressList:      gjList(p.Address, gjAddressOf),
+		AttributeList:    gjList(p.Attribute, gjAttributeOf),
+		Urls:             gjList(p.Url, gjURLOf),
+		LdsOrdList:       gjList(p.LdsOrd, gjLdsOrdOf),
+		CitationList:     gjHandles(p.Citationref, citationHlink),
+		NoteList:         gjHandles(p.Noteref, noteHlink),
+		PersonRefList:    gjList(p.Personref, gjPersonRefOf),
+	}
+	if gp.Gender == genderType.custom {
+		gp.Gender = genderType.unknown
+	}
+
+	primary := -1
+	for i := range p.Name {
+		if primary == -1 && !boolval(p.Name[i].Alt) {
+			primary = i
+			gp.PrimaryName = gjNameOf(&p.Name[i])
+			continue
+		}
+		gp.AlternateNames = append(gp.AlternateNames, gjNameOf(&p.Name[i]))
+	}
+
+	if ix != nil {
+		for i, er := range p.Eventref {
+			ev := ix.Event(er.Hlink)
+			if ev == nil || !isRole(er.Role, "Primary") {
+				continue
+			}
+			switch strval(ev.Type) {
+			case "Birth":
+				if gp.BirthRefIndex == -1 {
+					gp.BirthRefIndex = i
+				}
+			case "Death":
+				if gp.DeathRefIndex == -1 {
+					gp.DeathRefIndex = i
+				}
+			}
+		}
+	}
+	return gp
+}
+
+func (gp *gjPerson) person() *Person {
+	p := &Person{
+		ID:          optstr(gp.GrampsID),
+		Handle:      xmlHandle(gp.Handle),
+		Priv:        boolptr(gp.Private),
+		Change:      xmlChange(gp.Change),
+		Gender:      gjType{Value: gp.Gender}.xml(genderType),
+		Eventref:    xmlList(gp.EventRefList, (*gjEventRef).eventref),
+		LdsOrd:      xmlList(gp.LdsOrdList, (*gjLdsOrd).ldsord),
+		Objref:      xmlList(gp.MediaList, (*gjMediaRef).objref),
+		Address:     xmlList(gp.AddressList, (*gjAddress).address),
+		Attribute:   xmlList(gp.AttributeList, (*gjAttribute).attribute),
+		Url:         xmlList(gp.Urls, (*gjURL).url),
+		Childof:     xmlRefs(gp.ParentFamilyList, func(h string) Childof { return Childof{Hlink: h} }),
+		Parentin:    xmlRefs(gp.FamilyList, func(h string) Parentin { return Parentin{Hlink: h} }),
+		Personref:   xmlList(gp.PersonRefList, (*gjPersonRef).personref),
+		Noteref:     xmlRefs(gp.NoteList, noteRef),
+		Citationref: xmlRefs(gp.CitationList, citationRef),
+		Tagref:      xmlRefs(gp.TagList, tagRef),
+	}
+	p.Name = append(p.Name, gp.PrimaryName.name())
+	for i := range gp.AlternateNames {
+		n := gp.AlternateNames[i].name()
+		n.Alt = new(true)
+		p.Name = append(p.Name, n)
+	}
+	return p
+}
+
+type gjFamily struct {
+	Class         string        `json:"_class"`
+	Handle        string        `json:"handle"`
+	Change        int64         `json:"change"`
+	Private       bool          `json:"private"`
+	TagList       []string      `json:"tag_list"`
+	GrampsID      string        `json:"gramps_id"`
+	FatherHandle  *string       `json:"father_handle"`
+	MotherHandle  *string       `json:"mother_handle"`
+	ChildRefList  []gjChildRef  `json:"child_ref_list"`
+	Type          gjType        `json:"type"`
+	EventRefList  []gjEventRef  `json:"event_ref_list"`
+	MediaList     []gjMediaRef  `json:"media_list"`
+	AttributeList []gjAttribute `json:"attribute_list"`
+	LdsOrdList    []gjLdsOrd    `json:"lds_ord_list"`
+	CitationList  []string      `json:"citation_list"`
+	NoteList      []string      `json:"note_list"`
+	Complete      int           `json:"complete"`
+}
+
+func gjFamilyOf(f *Family) gjFamily {
+	gf := gjFamily{
+		Class:         "Family",
+		Handle:        gjHandle(f.Handle),
+		Change:        gjChange(f.Change),
+		Private:       boolval(f.Priv),
+		TagList:       gjHandles(f.Tagref, tagHlink),
+		GrampsID:      strval(f.ID),
+		ChildRefList:  gjList(f.Childref, gjChildRefOf),
+		Type:          gjTypeOf(familyRelType, "Unknown"),
+		EventRefList:  gjList(f.Eventref, gjEventRefOf),
+		MediaList:     gjList(f.Objref, gjMediaRefOf),
+		AttributeList: gjList(f.Attribute, gjAttributeOf),
+		LdsOrdList:    gjList(f.LdsOrd, gjLdsOrdOf),
+		CitationList:  gjHandles(f.Citationref, citationHlink),
+		NoteList:      gjHandles(f.Noteref, noteHlink),
+	}
+	for i := range gf.EventRefList {
+		if f.Eventref[i].Role == nil {
+			gf.EventRefList[i].Role = gjTypeOf(eventRoleType, "Family")
+		}
+	}
+	if f.Rel != nil {
+		gf.Type = gjTypeOf(familyRelType, f.Rel.Type)
+	}
+	if f.Father != nil {
+		gf.FatherHandle = new(gjHandle(f.Father.Hlink))
+	}
+	if f.Mother != nil {
+		gf.MotherHandle = new(gjHandle(f.Mother.Hlink))
+	}
+	return gf
+}
+
+func (gf *gjFamily) family() *Family {
+	f := &Family{
+		ID:          optstr(gf.GrampsID),
+		Handle:      xmlHandle(gf.Handle),
+		Priv:        boolptr(gf.Private),
+		Change:      xmlChange(gf.Change),
+		Rel:         &Rel{Type: gf.Type.xml(familyRelType)},
+		Eventref:    xmlList(gf.EventRefList, (*gjEventRef).eventref),
+		LdsOrd:      xmlList(gf.LdsOrdList, (*gjLdsOrd).ldsord),
+		Objref:      xmlList(gf.MediaList, (*gjMediaRef).objref),
+		Childref:    xmlList(gf.ChildRefList, (*gjChildRef).childref),
+		Attribute:   xmlList(gf.AttributeList, (*gjAttribute).attribute),
+		Noteref:     xmlRefs(gf.NoteList, noteRef),
+		Citationref: xmlRefs(gf.CitationList, citationRef),
+		Tagref:      xmlRefs(gf.TagList, tagRef),
+	}
+	if gf.FatherHandle != nil && *gf.FatherHandle != "" {
+		f.Father = &Father{Hlink: xmlHandle(*gf.FatherHandle)}
+	}
+	if gf.MotherHandle != nil && *gf.MotherHandle != "" {
+		f.Mother = &Mother{Hlink: xmlHandle(*gf.MotherHandle)}
+	}
+	return f
+}
+
+type gjEvent struct {
+	Class         string        `json:"_class"`
+	Handle        string        `json:"handle"`
+	Change        int64         `json:"change"`
+	Private       bool          `json:"private"`
+	TagList       []string      `json:"tag_list"`
+	GrampsID      string        `json:"gramps_id"`
+	CitationList  []string      `json:"citation_list"`
+	NoteList      []string      `json:"note_list"`
+	MediaList     []gjMediaRef  `json:"media_list"`
+	AttributeList []gjAttribute `json:"attribute_list"`
+	Date          gjDate        `json:"date"`
+	Place         string        `json:"place"`
+	Type          gjType        `json:"type"`
+	Description   string        `json:"description"`
+}
+
+func gjEventOf(e *Event) gjEvent {
+	ge := gjEvent{
+		Class:         "Event",
+		Handle:        gjHandle(e.Handle),
+		Change:        gjChange(e.Change),
+		Private:       boolval(e.Priv),
+		TagList:       gjHandles(e.Tagref, tagHlink),
+		GrampsID:      strval(e.ID),
+		CitationList:  gjHandles(e.Citationref, citationHlink),
+		NoteList:      gjHandles(e.Noteref, noteHlink),
+		MediaList:     gjList(e.Objref, gjMediaRefOf),
+		AttributeList: gjList(e.Attribute, gjAttributeOf),
+		Date:          gjDateOf(e.Date()),
+		Type:          gjTypeOfPtr(eventType, e.Type, -1),
+		Description:   strval(e.Description),
+	}
+	if e.Place != nil {
+		ge.Place = gjHandle(e.Place.Hlink)
+	}
+	if e.Cause != nil {
+		a := Attribute{Type: "Cause", Value: *e.Cause}
+		ge.AttributeList = append(ge.AttributeList, gjAttributeOf(&a))
+	}
+	return ge
+}
+
+func (ge *gjEvent) event() *Event {
+	e := &Event{
+		ID:          optstr(ge.GrampsID),
+		Handle:      xmlHandle(ge.Handle),
+		Priv:        boolptr(ge.Private),
+		Change:      xmlChange(ge.Change),
+		Type:        new(ge.Type.xml(eventType)),
+		Description: optstr(ge.Description),
+		Attribute:   xmlList(ge.AttributeList, (*gjAttribute).attribute),
+		Noteref:     xmlRefs(ge.NoteList, noteRef),
+		Citationref: xmlRefs(ge.CitationList, citationRef),
+		Objref:      xmlList(ge.MediaList, (*gjMediaRef).objref),
+		Tagref:      xmlRefs(ge.TagList, tagRef),
+	}
+	if ge.Place != "" {
+		e.Place = &Place{Hlink: xmlHandle(ge.Place)}
+	}
+	// The cause is written as the last attribute, so a public Cause
+	// attribute without citations in that position is read back as one.
+	if n := len(e.Attribute); n > 0 {
+		if a := e.Attribute[n-1]; a.Type == "Cause" && a.Priv == nil && len(a.Citationref) == 0 {
+			e.Cause = new(a.Value)
+			e.Attribute = e.Attribute[:n-1]
+			if len(e.Attribute) == 0 {
+				e.Attribute = nil
+			}
+		}
+	}
+	e.SetDate(ge.Date.date())
+	return e
+}
+
+type gjPlaceName struct {
+	Class string `json:"_class"`
+	Value string `json:"value"`
+	Date  gjDate `json:"date"`
+	Lang  string `json:"lang"`
+}
+
+func gjPlaceNameOf(n *Pname) gjPlaceName {
+	return gjPlaceName{Class: "PlaceName", Value: n.Value, Date: gjDateOf(n.Date()), Lang: strval(n.Lang)}
+}
+
+func (gn *gjPlaceName) pname() Pname {
+	n := Pname{Value: gn.Value, Lang: optstr(gn.Lang)}
+	n.SetDate(gn.Date.date())
+	return n
+}
+
+type gjPlaceRef struct {
+	Class string `json:"_class"`
+	Ref   string `json:"ref"`
+	Date  gjDate `json:"date"`
+}
+
+type gjLocation struct {
+	Class    string `json:"_class"`
+	Street   string `json:"street"`
+	Locality string `json:"locality"`
+	City     string `json:"city"`
+	County   string `json:"county"`
+	State    string `json:"state"`
+	Country  string `json:"country"`
+	Postal   string `json:"postal"`
+	Phone    string `json:"phone"`
+	Parish   string `json:"parish"`
+}
+
+func gjLocationOf(l *Location) gjLocation {
+	return gjLocation{
+		Class:    "Location",
+		Street:   strval(l.Street),
+		Locality: strval(l.Locality),
+		City:     strval(l.City),
+		County:   strval(l.County),
+		State:    strval(l.State),
+		Country:  strval(l.Country),
+		Postal:   strval(l.Postal),
+		Phone:    strval(l.Phone),
+		Parish:   strval(l.Parish),
+	}
+}
+
+func (gl *gjLocation) location() Location {
+	return Location{
+		Street:   optstr(gl.Street),
+		Locality: optstr(gl.Locality),
+		City:     optstr(gl.City),
+		County:   optstr(gl.County),
+		State:    optstr(gl.State),
+		Country:  optstr(gl.Country),
+		Postal:   optstr(gl.Postal),
+		Phone:    optstr(gl.Phone),
+		Parish:   optstr(gl.Parish),
+	}
+}
+
+type gjPlace struct {
+	Class        string        `json:"_class"`
+	Handle       string        `json:"handle"`
+	Change       int64         `json:"change"`
+	Private      bool          `json:"private"`
+	TagList      []string      `json:"tag_list"`
+	GrampsID     string        `json:"gramps_id"`
+	Title        string        `json:"title"`
+	Long         string        `json:"long"`
+	Lat          string        `json:"lat"`
+	PlacerefList []gjPlaceRef  `json:"placeref_list"`
+	Name         gjPlaceName   `json:"name"`
+	AltNames     []gjPlaceName `json:"alt_names"`
+	PlaceType    gjType        `json:"place_type"`
+	Code         string        `json:"code"`
+	AltLoc       []gjLocation  `json:"alt_loc"`
+	Urls         []gjURL       `json:"urls"`
+	MediaList    []gjMediaRef  `json:"media_list"`
+	CitationList []string      `json:"citation_list"`
+	NoteList     []string      `json:"note_list"`
+}
+
+func gjPlaceOf(p *Placeobj) gjPlace {
+	gp := gjPlace{
+		Class:        "Place",
+		Handle:       gjHandle(p.Handle),
+		Change:       gjChange(p.Change),
+		Private:      boolval(p.Priv),
+		TagList:      gjHandles(p.Tagref, tagHlink),
+		GrampsID:     strval(p.ID),
+		Title:        strval(p.Ptitle),
+		PlacerefList: gjList(p.Placeref, gjPlaceRefOf),
+		Name:         gjPlaceName{Class: "PlaceName", Date: gjDateOf(Date{})},
+		AltNames:     []gjPlaceName{},
+		PlaceType:    gjTypeOf(placeType, p.Type),
+		Code:         strval(p.Code),
+		AltLoc:       gjList(p.Location, gjLocationOf),
+		Urls:         gjList(p.Url, gjURLOf),
+		MediaList:    gjList(p.Objref, gjMediaRefOf),
+		CitationList: gjHandles(p.Citationref, citationHlink),
+		NoteList:     gjHandles(p.Noteref, noteHlink),
+	}
+	if p.Coord != nil {
+		gp.Long, gp.Lat = p.Coord.Long, p.Coord.Lat
+	}
+	for i := range p.Pname {
+		if i == 0 {
+			gp.Name = gjPlaceNameOf(&p.Pname[i])
+			continue
+		}
+		gp.AltNames = append(gp.AltNames, gjPlaceNameOf(&p.Pname[i]))
+	}
+	return gp
+}
+
+func (gp *gjPlace) place() *Placeobj {
+	p := &Placeobj{
+		ID:          optstr(gp.GrampsID),
+		Handle:      xmlHandle(gp.Handle),
+		Priv:        boolptr(gp.Private),
+		Change:      xmlChange(gp.Change),
+		Type:        gp.PlaceType.xml(placeType),
+		Ptitle:      optstr(gp.Title),
+		Code:        optstr(gp.Code),
+		Placeref:    xmlList(gp.PlacerefList, (*gjPlaceRef).placeref),
+		Location:    xmlList(gp.AltLoc, (*gjLocation).location),
+		Url:         xmlList(gp.Urls, (*gjURL).url),
+		Objref:      xmlList(gp.MediaList, (*gjMediaRef).objref),
+		Noteref:     xmlRefs(gp.NoteList, noteRef),
+		Citationref: xmlRefs(gp.CitationList, citationRef),
+		Tagref:      xmlRefs(gp.TagList, tagRef),
+	}
+	if gp.Long != "" || gp.Lat != "" {
+		p.Coord = &Coord{Long: gp.Long, Lat: gp.Lat}
+	}
+	p.Pname = append(p.Pname, gp.Name.pname())
+	for i := range gp.AltNames {
+		p.Pname = append(p.Pname, gp.AltNames[i].pname())
+	}
+	return p
+}
+
+func gjPlaceRefOf(r *Placeref) gjPlaceRef {
+	return gjPlaceRef{Class: "PlaceRef", Ref: gjHandle(r.Hlink), Date: gjDateOf(r.Date())}
+}
+
+func (gr *gjPlaceRef) placeref() Placeref {
+	r := Placeref{Hlink: xmlHandle(gr.Ref)}
+	r.SetDate(gr.Date.date())
+	return r
+}
+
+type gjRepoRef struct {
+	Class      string   `json:"_class"`
+	Private    bool     `json:"private"`
+	NoteList   []string `json:"note_list"`
+	Ref        string   `json:"ref"`
+	CallNumber string   `json:"call_number"`
+	MediaType  gjType   `json:"media_type"`
+}
+
+func gjRepoRefOf(r *Reporef) gjRepoRef {
+	return gjRepoRef{
+		Class:      "RepoRef",
+		Private:    boolval(r.Priv),
+		NoteList:   []string{},
+		Ref:        gjHandle(r.Hlink),
+		CallNumber: strval(r.Callno),
+		MediaType:  gjTypeOfPtr(sourceMediaType, r.Medium, -1),
+	}
+}
+
+func (gr *gjRepoRef) reporef() Reporef {
+	return Reporef{
+		Hlink:  xmlHandle(gr.Ref),
+		Priv:   boolptr(gr.Private),
+		Callno: optstr(gr.CallNumber),
+		Medium: gr.MediaType.xmlPtr(sourceMediaType, -1),
+	}
+}
+
+type gjSource struct {
+	Class         string           `json:"_class"`
+	Handle        string           `json:"handle"`
+	Change        int64            `json:"change"`
+	Private       bool             `json:"private"`
+	TagList       []string         `json:"tag_list"`
+	GrampsID      string           `json:"gramps_id"`
+	Title         string           `json:"title"`
+	Author        string           `json:"author"`
+	Pubinfo       string           `json:"pubinfo"`
+	Abbrev        string           `json:"abbrev"`
+	NoteList      []string         `json:"note_list"`
+	MediaList     []gjMediaRef     `json:"media_list"`
+	AttributeList []gjSrcAttribute `json:"attribute_list"`
+	ReporefList   []gjRepoRef      `json:"reporef_list"`
+}
+
+func gjSourceOf(s *Source) gjSource {
+	return gjSource{
+		Class:         "Source",
+		Handle:        gjHandle(s.Handle),
+		Change:        gjChange(s.Change),
+		Private:       boolval(s.Priv),
+		TagList:       gjHandles(s.Tagref, tagHlink),
+		GrampsID:      strval(s.ID),
+		Title:         strval(s.Stitle),
+		Author:        strval(s.Sauthor),
+		Pubinfo:       strval(s.Spubinfo),
+		Abbrev:        strval(s.Sabbrev),
+		NoteList:      gjHandles(s.Noteref, noteHlink),
+		MediaList:     gjList(s.Objref, gjMediaRefOf),
+		AttributeList: gjList(s.Srcattribute, gjSrcAttributeOf),
+		ReporefList:   gjList(s.Reporef, gjRepoRefOf),
+	}
+}
+
+func (gs *gjSource) source() *Source {
+	return &Source{
+		ID:           optstr(gs.GrampsID),
+		Handle:       xmlHandle(gs.Handle),
+		Priv:         boolptr(gs.Private),
+		Change:       xmlChange(gs.Change),
+		Stitle:       optstr(gs.Title),
+		Sauthor:      optstr(gs.Author),
+		Spubinfo:     optstr(gs.Pubinfo),
+		Sabbrev:      optstr(gs.Abbrev),
+		Noteref:      xmlRefs(gs.NoteList, noteRef),
+		Objref:       xmlList(gs.MediaList, (*gjMediaRef).objref),
+		Srcattribute: xmlList(gs.AttributeList, (*gjSrcAttribute).srcattribute),
+		Reporef:      xmlList(gs.ReporefList, (*gjRepoRef).reporef),
+		Tagref:       xmlRefs(gs.TagList, tagRef),
+	}
+}
+
+type gjCitation struct {
+	Class         string           `json:"_class"`
+	Handle        string           `json:"handle"`
+	Change        int64            `json:"change"`
+	Private       bool             `json:"private"`
+	TagList       []string         `json:"tag_list"`
+	GrampsID      string           `json:"gramps_id"`
+	Date          gjDate           `json:"date"`
+	Page          string           `json:"page"`
+	Confidence    int              `json:"confidence"`
+	SourceHandle  *string          `json:"source_handle"`
+	NoteList      []string         `json:"note_list"`
+	MediaList     []gjMediaRef     `json:"media_list"`
+	AttributeList []gjSrcAttribute `json:"attribute_list"`
+}
+
+func gjCitationOf(c *Citation) gjCitation {
+	gc := gjCitation{
+		Class:         "Citation",
+		Handle:        gjHandle(c.Handle),
+		Change:        gjChange(c.Change),
+		Private:       boolval(c.Priv),
+		TagList:       gjHandles(c.Tagref, tagHlink),
+		GrampsID:      strval(c.ID),
+		Date:          gjDateOf(c.Date()),
+		Page:          strval(c.Page),
+		Confidence:    gjTypeOf(confidenceType, c.Confidence).Value,
+		NoteList:      gjHandles(c.Noteref, noteHlink),
+		MediaList:     gjList(c.Objref, gjMediaRefOf),
+		AttributeList: gjList(c.Srcattribute, gjSrcAttributeOf),
+	}
+	if gc.Confidence == confidenceType.custom {
+		gc.Confidence = confidenceType.unknown
+	}
+	if c.Sourceref != nil {
+		gc.SourceHandle = new(gjHandle(c.Sourceref.Hlink))
+	}
+	return gc
+}
+
+func (gc *gjCitation) citation() *Citation {
+	c := &Citation{
+		ID:           optstr(gc.GrampsID),
+		Handle:       xmlHandle(gc.Handle),
+		Priv:         boolptr(gc.Private),
+		Change:       xmlChange(gc.Change),
+		Page:         optstr(gc.Page),
+		Confidence:   strconv.Itoa(gc.Confidence),
+		Noteref:      xmlRefs(gc.NoteList, noteRef),
+		Objref:       xmlList(gc.MediaList, (*gjMediaRef).objref),
+		Srcattribute: xmlList(gc.AttributeList, (*gjSrcAttribute).srcattribute),
+		Tagref:       xmlRefs(gc.TagList, tagRef),
+	}
+	if gc.SourceHandle != nil && *gc.SourceHandle != "" {
+		c.Sourceref = &Sourceref{Hlink: xmlHandle(*gc.SourceHandle)}
+	}
+	c.SetDate(gc.Date.date())
+	return c
+}
+
+type gjMedia struct {
+	Class         string        `json:"_class"`
+	Handle        string        `json:"handle"`
+	Change        int64         `json:"change"`
+	Private       bool          `json:"private"`
+	TagList       []string      `json:"tag_list"`
+	GrampsID      string        `json:"gramps_id"`
+	Path          string        `json:"path"`
+	Mime          string        `json:"mime"`
+	Desc          string        `json:"desc"`
+	Checksum      string        `json:"checksum"`
+	AttributeList []gjAttribute `json:"attribute_list"`
+	CitationList  []string      `json:"citation_list"`
+	NoteList      []string      `json:"note_list"`
+	Date          gjDate        `json:"date"`
+}
+
+func gjMediaOf(o *Object) gjMedia {
+	return gjMedia{
+		Class:         "Media",
+		Handle:        gjHandle(o.Handle),
+		Change:        gjChange(o.Change),
+		Private:       boolval(o.Priv),
+		TagList:       gjHandles(o.Tagref, tagHlink),
+		GrampsID:      strval(o.ID),
+		Path:          o.File.Src,
+		Mime:          o.File.Mime,
+		Desc:          o.File.Description,
+		Checksum:      strval(o.File.Checksum),
+		AttributeList: gjList(o.Attribute, gjAttributeOf),
+		CitationList:  gjHandles(o.Citationref, citationHlink),
+		NoteList:      gjHandles(o.Noteref, noteHlink),
+		Date:          gjDateOf(o.Date()),
+	}
+}
+
+func (gm *gjMedia) media() *Object {
+	o := &Object{
+		ID:          optstr(gm.GrampsID),
+		Handle:      xmlHandle(gm.Handle),
+		Priv:        boolptr(gm.Private),
+		Change:      xmlChange(gm.Change),
+		File:        File{Src: gm.Path, Mime: gm.Mime, Description: gm.Desc, Checksum: optstr(gm.Checksum)},
+		Attribute:   xmlList(gm.AttributeList, (*gjAttribute).attribute),
+		Noteref:     xmlRefs(gm.NoteList, noteRef),
+		Citationref: xmlRefs(gm.CitationList, citationRef),
+		Tagref:      xmlRefs(gm.TagList, tagRef),
+	}
+	o.SetDate(gm.Date.date())
+	return o
+}
+
+type gjRepository struct {
+	Class       string      `json:"_class"`
+	Handle      string      `json:"handle"`
+	Change      int64       `json:"change"`
+	Private     bool        `json:"private"`
+	TagList     []string    `json:"tag_list"`
+	GrampsID    string      `json:"gramps_id"`
+	Type        gjType      `json:"type"`
+	Name        string      `json:"name"`
+	NoteList    []string    `json:"note_list"`
+	AddressList []gjAddress `json:"address_list"`
+	Urls        []gjURL     `json:"urls"`
+}
+
+func gjRepositoryOf(r *Repository) gjRepository {
+	return gjRepository{
+		Class:       "Repository",
+		Handle:      gjHandle(r.Handle),
+		Change:      gjChange(r.Change),
+		Private:     boolval(r.Priv),
+		TagList:     gjHandles(r.Tagref, tagHlink),
+		GrampsID:    strval(r.ID),
+		Type:        gjTypeOf(repositoryType, r.Type),
+		Name:        r.Rname,
+		NoteList:    gjHandles(r.Noteref, noteHlink),
+		AddressList: gjList(r.Address, gjAddressOf),
+		Urls:        gjList(r.Url, gjURLOf),
+	}
+}
+
+func (gr *gjRepository) repository() *Repository {
+	return &Repository{
+		ID:      optstr(gr.GrampsID),
+		Handle:  xmlHandle(gr.Handle),
+		Priv:    boolptr(gr.Private),
+		Change:  xmlChange(gr.Change),
+		Rname:   gr.Name,
+		Type:    gr.Type.xml(repositoryType),
+		Address: xmlList(gr.AddressList, (*gjAddress).address),
+		Url:     xmlList(gr.Urls, (*gjURL).url),
+		Noteref: xmlRefs(gr.NoteList, noteRef),
+		Tagref:  xmlRefs(gr.TagList, tagRef),
+	}
+}
+
+type gjStyledTextTag struct {
+	Class  string   `json:"_class"`
+	Name   gjType   `json:"name"`
+	Value  any      `json:"value"`
+	Ranges [][2]int `json:"ranges"`
+}
+
+type gjStyledText struct {
+	Class  string            `json:"_class"`
+	String string            `json:"string"`
+	Tags   []gjStyledTextTag `json:"tags"`
+}
+
+type gjNote struct {
+	Class    string       `json:"_class"`
+	Handle   string       `json:"handle"`
+	Change   int64        `json:"change"`
+	Private  bool         `json:"private"`
+	TagList  []string     `json:"tag_list"`
+	GrampsID string       `json:"gramps_id"`
+	Text     gjStyledText `json:"text"`
+	Format   int          `json:"format"`
+	Type     gjType       `json:"type"`
+}
+
+func gjNoteOf(n *Note) gjNote {
+	gn := gjNote{
+		Class:    "Note",
+		Handle:   gjHandle(n.Handle),
+		Change:   gjChange(n.Change),
+		Private:  boolval(n.Priv),
+		TagList:  gjHandles(n.Tagref, tagHlink),
+		GrampsID: strval(n.ID),
+		Text: gjStyledText{
+			Class:  "StyledText",
+			String: n.Text,
+			Tags: gjList(n.Style, func(s *Style) gjStyledTextTag {
+				t := gjStyledTextTag{Class: "StyledTextTag", Name: gjTypeOf(styledTextTagType, s.Name), Ranges: [][2]int{}}
+				if s.Value != nil {
+					t.Value = *s.Value
+				}
+				for _, r := range s.Range {
+					t.Ranges = append(t.Ranges, [2]int{r.Start, r.End})
+				}
+				return t
+			}),
+		},
+		Type: gjTypeOf(noteType, n.Type),
+	}
+	if boolval(n.Format) {
+		gn.Format = 1
+	}
+	return gn
+}
+
+func (gn *gjNote) note() *Note {
+	n := &Note{
+		ID:     optstr(gn.GrampsID),
+		Handle: xmlHandle(gn.Handle),
+		Priv:   boolptr(gn.Private),
+		Change: xmlChange(gn.Change),
+		Format: boolptr(gn.Format == 1),
+		Type:   gn.Type.xml(noteType),
+		Text:   gn.Text.String,
+		Style: xmlList(gn.Text.Tags, func(t *gjStyledTextTag) Style {
+			s := Style{Name: t.Name.xml(styledTextTagType)}
+			switch v := t.Value.(type) {
+			case string:
+				s.Value = new(v)
+			case float64:
+				s.Value = new(strconv.FormatFloat(v, 'f', -1, 64))
+			}
+			for _, r := range t.Ranges {
+				s.Range = append(s.Range, Range{Start: r[0], End: r[1]})
+			}
+			return s
+		}),
+		Tagref: xmlRefs(gn.TagList, tagRef),
+	}
+	return n
+}
+
+type gjTag struct {
+	Class    string `json:"_class"`
+	Handle   string `json:"handle"`
+	Change   int64  `json:"change"`
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+	Priority int    `json:"priority"`
+}
+
+func gjTagOf(t *Tag) gjTag {
+	priority, _ := strconv.Atoi(t.Priority)
+	return gjTag{Class: "Tag", Handle: gjHandle(t.Handle), Change: gjChange(t.Change), Name: t.Name, Color: t.Color, Priority: priority}
+}
+
+func (gt *gjTag) tag() *Tag {
+	return &Tag{Handle: xmlHandle(gt.Handle), Change: xmlChange(gt.Change), Name: gt.Name, Color: gt.Color, Priority: strconv.Itoa(gt.Priority)}
+}
+
+// MarshalGrampsJSON returns the Gramps JSON form of v, which must be a
+// pointer to a Person, Family, Event, Placeobj, Source, Citation, Object,
+// Repository, Note or Tag. The index is used to find the birth and death
+// events of a person and may be nil, in which case they are not recorded.
+//
+// Gramps JSON has no equivalent of an event's cause, which is written as an
+// attribute of type Cause as Gramps does when importing XML. See the package
+// documentation for the fields that do not survive a round trip.
+func MarshalGrampsJSON(ix *Index, v any) ([]byte, error) {
+	switch o := v.(type) {
+	case *Person:
+		return json.Marshal(gjPersonOf(ix, o))
+	case *Family:
+		return json.Marshal(gjFamilyOf(o))
+	case *Event:
+		return json.Marshal(gjEventOf(o))
+	case *Placeobj:
+		return json.Marshal(gjPlaceOf(o))
+	case *Source:
+		return json.Marshal(gjSourceOf(o))
+	case *Citation:
+		return json.Marshal(gjCitationOf(o))
+	case *Object:
+		return json.Marshal(gjMediaOf(o))
+	case *Repository:
+		return json.Marshal(gjRepositoryOf(o))
+	case *Note:
+		return json.Marshal(gjNoteOf(o))
+	case *Tag:
+		return json.Marshal(gjTagOf(o))
+	}
+	return nil, fmt.Errorf("grampsxml: cannot convert %T to Gramps JSON", v)
+}
+
+// UnmarshalGrampsJSON decodes a single object in Gramps JSON form. The
+// result is a pointer to a Person, Family, Event, Placeobj, Source,
+// Citation, Object, Repository, Note or Tag according to the object's
+// _class member. The citations of event references and the notes of
+// attributes are discarded, since the types in this package cannot hold
+// them.
+func UnmarshalGrampsJSON(data []byte) (any, error) {
+	var head struct {
+		Class string `json:"_class"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, err
+	}
+
+	var dec interface{ object() any }
+	switch head.Class {
+	case "Person":
+		dec = new(gjPersonDecoder)
+	case "Family":
+		dec = new(gjFamilyDecoder)
+	case "Event":
+		dec = new(gjEventDecoder)
+	case "Place":
+		dec = new(gjPlaceDecoder)
+	case "Source":
+		dec = new(gjSourceDecoder)
+	case "Citation":
+		dec = new(gjCitationDecoder)
+	case "Media":
+		dec = new(gjMediaDecoder)
+	case "Repository":
+		dec = new(gjRepositoryDecoder)
+	case "Note":
+		dec = new(gjNoteDecoder)
+	case "Tag":
+		dec = new(gjTagDecoder)
+	default:
+		return nil, fmt.Errorf("grampsxml: unsupported Gramps JSON class %q", head.Class)
+	}
+	if err := json.Unmarshal(data, dec); err != nil {
+		return nil, fmt.Errorf("grampsxml: decode %s: %w", head.Class, err)
+	}
+	return dec.object(), nil
+}
+
+// The decoder types adapt each Gramps JSON type to a common interface.
+type (
+	gjPersonDecoder     struct{ gjPerson }
+	gjFamilyDecoder     struct{ gjFamily }
+	gjEventDecoder      struct{ gjEvent }
+	gjPlaceDecoder      struct{ gjPlace }
+	gjSourceDecoder     struct{ gjSource }
+	gjCitationDecoder   struct{ gjCitation }
+	gjMediaDecoder      struct{ gjMedia }
+	gjRepositoryDecoder struct{ gjRepository }
+	gjNoteDecoder       struct{ gjNote }
+	gjTagDecoder        struct{ gjTag }
+)
+
+func (d *gjPersonDecoder) object() any     { return d.person() }
+func (d *gjFamilyDecoder) object() any     { return d.family() }
+func (d *gjEventDecoder) object() any      { return d.event() }
+func (d *gjPlaceDecoder) object() any      { return d.place() }
+func (d *gjSourceDecoder) object() any     { return d.source() }
+func (d *gjCitationDecoder) object() any   { return d.citation() }
+func (d *gjMediaDecoder) object() any      { return d.media() }
+func (d *gjRepositoryDecoder) object() any { return d.repository() }
+func (d *gjNoteDecoder) object() any       { return d.note() }
+func (d *gjTagDecoder) object() any        { return d.tag() }
+
+// WriteGrampsJSON writes every primary object in db to w in Gramps JSON
+// form, one object per line.
+func WriteGrampsJSON(w io.Writer, db *Database) error {
+	ix := NewIndex(db)
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+
+	var err error
+	put := func(v any) {
+		if err == nil {
+			err = enc.Encode(v)
+		}
+	}
+	people := db.People.list()
+	for i := range people {
+		put(gjPersonOf(ix, &people[i]))
+	}
+	families := db.Families.list()
+	for i := range families {
+		put(gjFamilyOf(&families[i]))
+	}
+	events := db.Events.list()
+	for i := range events {
+		put(gjEventOf(&events[i]))
+	}
+	places := db.Places.list()
+	for i := range places {
+		put(gjPlaceOf(&places[i]))
+	}
+	sources := db.Sources.list()
+	for i := range sources {
+		put(gjSourceOf(&sources[i]))
+	}
+	citations := db.Citations.list()
+	for i := range citations {
+		put(gjCitationOf(&citations[i]))
+	}
+	objects := db.Objects.list()
+	for i := range objects {
+		put(gjMediaOf(&objects[i]))
+	}
+	repositories := db.Repositories.list()
+	for i := range repositories {
+		put(gjRepositoryOf(&repositories[i]))
+	}
+	notes := db.Notes.list()
+	for i := range notes {
+		put(gjNoteOf(&notes[i]))
+	}
+	tags := db.Tags.list()
+	for i := range tags {
+		put(gjTagOf(&tags[i]))
+	}
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// ReadGrampsJSON reads objects in Gramps JSON form from r and appends them
+// to db. The input may hold a single JSON array of objects or a sequence of
+// objects, such as one per line.
+func ReadGrampsJSON(r io.Reader, db *Database) error {
+	br := bufio.NewReader(r)
+	dec := json.NewDecoder(br)
+
+	add := func(raw json.RawMessage) error {
+		v, err := UnmarshalGrampsJSON(raw)
+		if err != nil {
+			return err
+		}
+		addObject(db, v)
+		return nil
+	}
+
+	first, err := peekNonSpace(br)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if first == '[' {
+		var raws []json.RawMessage
+		if err := dec.Decode(&raws); err != nil {
+			return err
+		}
+		for _, raw := range raws {
+			if err := add(raw); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := add(raw); err != nil {
+			return err
+		}
+	}
+}
+
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if !bytes.ContainsAny(b, " \t\r\n") {
+			return b[0], nil
+		}
+		if _, err := br.ReadByte(); err != nil {
+			return 0, err
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package grampsxml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var grampsJSONSample = Database{
+	Tags: &Tags{
+		Tag: []Tag{
+			{Handle: "_t1", Change: "1700000000", Name: "ToDo", Color: "#ff0000", Priority: "1"},
+		},
+	},
+	Events: &Events{
+		Event: []Event{
+			{Handle: "_e1", Change: "1700000001", ID: new("E0001"), Type: new("Birth"), Dateval: &Dateval{Val: "1855-06-21"}, Place: &Place{Hlink: "_pl1"}},
+			{Handle: "_e2", Change: "1700000002", ID: new("E0002"), Type: new("Marriage"), Daterange: &Daterange{Start: "1879", Stop: "1880", Quality: new("estimated")}},
+			{Handle: "_e3", Change: "1700000003", ID: new("E0003"), Type: new("Blacksmithing"), Datestr: &Datestr{Val: "in the spring"}, Description: new("Apprenticed")},
+		},
+	},
+	People: &People{
+		Person: []Person{
+			{
+				Handle: "_i1",
+				Change: "1700000004",
+				ID:     new("I0001"),
+				Gender: "M",
+				Name: []Name{
+					{Type: new("Birth Name"), First: new("Lewis"), Surname: []Surname{{Surname: "Garner"}}},
+					{Alt: new(true), Type: new("Also Known As"), First: new("Lew"), Surname: []Surname{{Surname: "Garner"}}},
+				},
+				Eventref: []Eventref{{Hlink: "_e1", Role: new("Primary")}, {Hlink: "_e3", Role: new("Apprentice")}},
+				Parentin: []Parentin{{Hlink: "_f1"}},
+				Tagref:   []Tagref{{Hlink: "_t1"}},
+			},
+			{
+				Handle:      "_i2",
+				Change:      "1700000005",
+				ID:          new("I0002"),
+				Gender:      "F",
+				Priv:        new(true),
+				Name:        []Name{{Type: new("Birth Name"), First: new("Eliza"), Surname: []Surname{{Surname: "Garner"}}}},
+				Childof:     []Childof{{Hlink: "_f1"}},
+				Noteref:     []Noteref{{Hlink: "_n1"}},
+				Citationref: []Citationref{{Hlink: "_c1"}},
+			},
+		},
+	},
+	Families: &Families{
+		Family: []Family{
+			{
+				Handle:   "_f1",
+				Change:   "1700000006",
+				ID:       new("F0001"),
+				Rel:      &Rel{Type: "Married"},
+				Father:   &Father{Hlink: "_i1"},
+				Eventref: []Eventref{{Hlink: "_e2", Role: new("Family")}},
+				Childref: []Childref{{Hlink: "_i2"}, {Hlink: "_i3", Frel: new("Adopted")}},
+			},
+		},
+	},
+	Citations: &Citations{
+		Citation: []Citation{
+			{Handle: "_c1", Change: "1700000007", ID: new("C0001"), Page: new("p. 12"), Confidence: "3", Sourceref: &Sourceref{Hlink: "_s1"}},
+		},
+	},
+	Sources: &Sources{
+		Source: []Source{
+			{Handle: "_s1", Change: "1700000008", ID: new("S0001"), Stitle: new("Parish register")},
+		},
+	},
+	Places: &Places{
+		Place: []Placeobj{
+			{Handle: "_pl1", Change: "1700000009", ID: new("P0001"), Type: "Town", Pname: []Pname{{Value: "Greenfield"}}, Coord: &Coord{Lat: "53.5", Long: "-2.0"}, Placeref: []Placeref{{Hlink: "_pl2", Datespan: &Datespan{Start: "1800", Stop: "1974-03-31"}}}},
+			{Handle: "_pl2", Change: "1700000010", ID: new("P0002"), Type: "County", Pname: []Pname{{Value: "Yorkshire"}, {Value: "Yorks", Lang: new("en")}}},
+		},
+	},
+	Notes: &Notes{
+		Note: []Note{
+			{Handle: "_n1", Change: "1700000011", ID: new("N0001"), Type: "General", Text: "Miller at Greenfield", Style: []Style{{Name: "bold", Range: []Range{{Start: 0, End: 6}}}}},
+		},
+	},
+}
+
+func TestGrampsJSONRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteGrampsJSON(&buf, &grampsJSONSample); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+
+	var got Database
+	if err := ReadGrampsJSON(&buf, &got); err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+
+	if diff := cmp.Diff(grampsJSONSample, got); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadGrampsJSONArray(t *testing.T) {
+	input := `[
+		{"_class": "Note", "handle": "n1", "gramps_id": "N0001", "change": 0, "private": false, "tag_list": [],
+		 "type": {"_class": "NoteType", "string": "", "value": 2},
+		 "text": {"_class": "StyledText", "string": "Check census", "tags": []}, "format": 0},
+		{"_class": "Tag", "handle": "t1", "name": "ToDo", "color": "#000000000000", "priority": 0, "change": 0}
+	]`
+	var db Database
+	if err := ReadGrampsJSON(strings.NewReader(input), &db); err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	want := Database{
+		Tags:  &Tags{Tag: []Tag{{Handle: "_t1", Change: "0", Name: "ToDo", Color: "#000000000000", Priority: "0"}}},
+		Notes: &Notes{Note: []Note{{Handle: "_n1", Change: "0", ID: new("N0001"), Type: "Research", Text: "Check census"}}},
+	}
+	if diff := cmp.Diff(want, db); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMarshalGrampsJSONEvent(t *testing.T) {
+	ev := &Event{
+		Handle:  "_e1",
+		Change:  "1700000001",
+		ID:      new("E0001"),
+		Type:    new("Birth"),
+		Dateval: &Dateval{Val: "1855-06-21", Type: new("about")},
+	}
+	got, err := MarshalGrampsJSON(nil, ev)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"_class":"Event","handle":"e1","change":1700000001,"private":false,"tag_list":[],"gramps_id":"E0001",` +
+		`"citation_list":[],"note_list":[],"media_list":[],"attribute_list":[],` +
+		`"date":{"_class":"Date","calendar":0,"modifier":3,"quality":0,"dateval":[21,6,1855,false],"text":"","sortval":2398756,"newyear":0,"format":null},` +
+		`"place":"","type":{"_class":"EventType","string":"","value":12},"description":""}`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	back, err := UnmarshalGrampsJSON(got)
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	if diff := cmp.Diff(ev, back); diff != "" {
+		t.Errorf("decode mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGrampsJSONEventRoleCodes(t *testing.T) {
+	testCases := []struct {
+		role string
+		want gjType
+	}{
+		{role: "Unknown", want: gjType{Class: "EventRoleType", Value: -1}},
+		{role: "Primary", want: gjType{Class: "EventRoleType", Value: 1}},
+		{role: "Clergy", want: gjType{Class: "EventRoleType", Value: 2}},
+		{role: "Celebrant", want: gjType{Class: "EventRoleType", Value: 3}},
+		{role: "Aide", want: gjType{Class: "EventRoleType", Value: 4}},
+		{role: "Bride", want: gjType{Class: "EventRoleType", Value: 5}},
+		{role: "Groom", want: gjType{Class: "EventRoleType", Value: 6}},
+		{role: "Witness", want: gjType{Class: "EventRoleType", Value: 7}},
+		{role: "Family", want: gjType{Class: "EventRoleType", Value: 8}},
+		{role: "Informant", want: gjType{Class: "EventRoleType", Value: 9}},
+		{role: "Photographer", want: gjType{Class: "EventRoleType", String: "Photographer", Value: 0}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.role, func(t *testing.T) {
+			got := gjEventRefOf(&Eventref{Hlink: "_e1", Role: new(tc.role)}).Role
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGrampsJSONRoundTripFields(t *testing.T) {
+	testCases := []struct {
+		name string
+		obj  any
+	}{
+		{
+			name: "event cause",
+			obj:  &Event{Handle: "_e1", Change: "0", Type: new("Death"), Cause: new("Consumption")},
+		},
+		{
+			name: "event cause with attributes",
+			obj: &Event{
+				Handle: "_e1", Change: "0", Type: new("Death"), Cause: new("Consumption"),
+				Attribute: []Attribute{{Type: "Cause", Value: "Fever", Priv: new(true)}, {Type: "Age", Value: "42"}},
+			},
+		},
+		{
+			name: "event reference notes and attributes",
+			obj: &Person{
+				Handle: "_i1", Change: "0", Gender: "U",
+				Name: []Name{{Type: new("Birth Name"), First: new("Lewis")}},
+				Eventref: []Eventref{{
+					Hlink: "_e1", Role: new("Witness"), Priv: new(true),
+					Attribute: []Attribute{{Type: "Age", Value: "30"}},
+					Noteref:   []Noteref{{Hlink: "_n1"}},
+				}},
+			},
+		},
+		{
+			name: "attribute citations",
+			obj: &Family{
+				Handle: "_f1", Change: "0", Rel: &Rel{Type: "Married"},
+				Attribute: []Attribute{{Type: "Number of Children", Value: "3", Citationref: []Citationref{{Hlink: "_c1"}}}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := MarshalGrampsJSON(nil, tc.obj)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := UnmarshalGrampsJSON(data)
+			if err != nil {
+				t.Fatalf("unexpected decode error: %v", err)
+			}
+			if diff := cmp.Diff(tc.obj, got); diff != "" {
+				t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshalGrampsJSONDiscardedFields(t *testing.T) {
+	// Event reference citations and attribute notes cannot be held by the
+	// XML types, so they are read without error and discarded.
+	input := `{"_class": "Person", "handle": "i1", "change": 0, "gender": 2,
+		"event_ref_list": [{"_class": "EventRef", "ref": "e1", "private": false,
+			"citation_list": ["c1"], "note_list": ["n1"], "attribute_list": [],
+			"role": {"_class": "EventRoleType", "string": "", "value": 1}}],
+		"attribute_list": [{"_class": "Attribute", "private": false, "citation_list": ["c2"], "note_list": ["n2"],
+			"type": {"_class": "AttributeType", "string": "", "value": 7}, "value": "Jack"}]}`
+	got, err := UnmarshalGrampsJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	p, ok := got.(*Person)
+	if !ok {
+		t.Fatalf("got %T, wanted *Person", got)
+	}
+	if diff := cmp.Diff([]Eventref{{Hlink: "_e1", Role: new("Primary"), Noteref: []Noteref{{Hlink: "_n1"}}}}, p.Eventref); diff != "" {
+		t.Errorf("event reference mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]Attribute{{Type: "Nickname", Value: "Jack", Citationref: []Citationref{{Hlink: "_c2"}}}}, p.Attribute); diff != "" {
+		t.Errorf("attribute mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,359 @@
+package grampsxml
+
+import "strings"
+
+// grampsType describes one of the enumerated types used by Gramps for the
+// type, role and relationship strings found in Gramps XML. Gramps stores
+// these as numeric codes internally, with a designated code for custom
+// values whose text is supplied by the user.
+type grampsType struct {
+	class   string // Gramps class name, used in Gramps JSON
+	custom  int    // code used for custom values
+	unknown int    // code used for unknown values
+	values  []grampsTypeValue
+}
+
+// grampsTypeValue is one of the standard values of a grampsType.
+type grampsTypeValue struct {
+	code  int
+	xml   string // value written to Gramps XML
+	label string // English label displayed by Gramps
+}
+
+// byXML returns the standard value written to XML as s.
+func (t *grampsType) byXML(s string) (grampsTypeValue, bool) {
+	for _, v := range t.values {
+		if v.xml == s {
+			return v, true
+		}
+	}
+	return grampsTypeValue{}, false
+}
+
+// byCode returns the standard value with the given code.
+func (t *grampsType) byCode(code int) (grampsTypeValue, bool) {
+	for _, v := range t.values {
+		if v.code == code {
+			return v, true
+		}
+	}
+	return grampsTypeValue{}, false
+}
+
+// sameLabel builds values whose English label matches their XML form.
+func sameLabel(first int, names ...string) []grampsTypeValue {
+	vs := make([]grampsTypeValue, len(names))
+	for i, n := range names {
+		vs[i] = grampsTypeValue{code: first + i, xml: n, label: n}
+	}
+	return vs
+}
+
+var genderType = &grampsType{
+	class:   "Gender",
+	custom:  -1,
+	unknown: 2,
+	values: []grampsTypeValue{
+		{0, "F", "female"},
+		{1, "M", "male"},
+		{2, "U", "unknown"},
+		{3, "X", "other"},
+	},
+}
+
+var eventType = &grampsType{
+	class:   "EventType",
+	custom:  0,
+	unknown: -1,
+	values: append([]grampsTypeValue{
+		{-1, "Unknown", "Unknown"},
+		{0, "Custom", "Custom"},
+	}, sameLabel(1,
+		"Marriage", "Marriage Settlement", "Marriage License", "Marriage Contract", "Marriage Banns",
+		"Engagement", "Divorce", "Divorce Filing", "Annulment", "Alternate Marriage", "Adopted",
+		"Birth", "Death", "Adult Christening", "Baptism", "Bar Mitzvah", "Bas Mitzvah", "Blessing",
+		"Burial", "Cause Of Death", "Census", "Christening", "Confirmation", "Cremation", "Degree",
+		"Education", "Elected", "Emigration", "First Communion", "Immigration", "Graduation",
+		"Medical Information", "Military Service", "Naturalization", "Nobility Title",
+		"Number of Marriages", "Occupation", "Ordination", "Probate", "Property", "Religion",
+		"Residence", "Retirement", "Will", "Stillbirth",
+	)...),
+}
+
+var eventRoleType = &grampsType{
+	class:   "EventRoleType",
+	custom:  0,
+	unknown: -1,
+	values: append([]grampsTypeValue{
+		{-1, "Unknown", "Unknown"},
+		{0, "Custom", "Custom"},
+	}, sameLabel(1,
+		"Primary", "Clergy", "Celebrant", "Aide", "Bride", "Groom", "Witness", "Family", "Informant",
+	)...),
+}
+
+var familyRelType = &grampsType{
+	class:   "FamilyRelType",
+	custom:  4,
+	unknown: 3,
+	values:  sameLabel(0, "Married", "Unmarried", "Civil Union", "Unknown", "Custom"),
+}
+
+var childRefType = &grampsType{
+	class:   "ChildRefType",
+	custom:  7,
+	unknown: 6,
+	values:  sameLabel(0, "None", "Birth", "Adopted", "Stepchild", "Sponsored", "Foster", "Unknown", "Custom"),
+}
+
+var nameType = &grampsType{
+	class:   "NameType",
+	custom:  0,
+	unknown: -1,
+	values: append([]grampsTypeValue{
+		{-1, "Unknown", "Unknown"},
+		{0, "Custom", "Custom"},
+	}, sameLabel(1, "Also Known As", "Birth Name", "Married Name")...),
+}
+
+var nameOriginType = &grampsType{
+	class:   "NameOriginType",
+	custom:  0,
+	unknown: -1,
+	values: append([]grampsTypeValue{
+		{-1, "Unknown", "Unknown"},
+		{0, "Custom", "Custom"},
+		{1, "", ""},
+	}, sameLabel(2,
+		"Inherited", "Given", "Taken", "Patronymic", "Matronymic", "Feudal", "Pseudonym",
+		"Patrilineal", "Matrilineal", "Occupation", "Location",
+	)...),
+}
+
+var placeType = &grampsType{
+	class:   "PlaceType",
+	custom:  0,
+	unknown: -1,
+	values: append([]grampsTypeValue{
+		{-1, "Unknown", "Unknown"},
+		{0, "Custom", "Custom"},
+	}, sameLabel(1,
+		"Country", "State", "County", "City", "Parish", "Locality", "Street", "Province",
+		"Region", "Department", "Neighborhood", "District", "Borough", "Municipality", "Town",
+		"Village", "Hamlet", "Farm", "Building", "Number",
+	)...),
+}
+
+var noteType = &grampsType{
+	class:   "NoteType",
+	custom:  0,
+	unknown: -1,
+	values: append([]grampsTypeValue{
+		{-1, "Unknown", "Unknown"},
+		{0, "Custom", "Custom"},
+	}, sameLabel(1,
+		"General", "Research", "Transcript", "Person Note", "Attribute Note", "Address Note",
+		"Association Note", "LDS Note", "Family Note", "Event Note", "Event Reference Note",
+		"Source Note", "Source Reference Note", "Place Note", "Repository Note",
+		"Repository Reference Note", "Media Note", "Media Reference Note",
+		"Child Reference Note", "Name Note", "Source text", "Citation", "Report", "Html code",
+		"To Do", "Link",
+	)...),
+}
+
+var urlType = &grampsType{
+	class:   "UrlType",
+	custom:  0,
+	unknown: -1,
+	values: append([]grampsTypeValue{
+		{-1, "Unknown", "Unknown"},
+		{0, "Custom", "Custom"},
+	}, sameLabel(1, "E-mail", "Web Home", "Web Search", "FTP")...),
+}
+
+var repositoryType = &grampsType{
+	class:   "RepositoryType",
+	custom:  0,
+	unknown: -1,
+	values: append([]grampsTypeValue{
+		{-1, "Unknown", "Unknown"},
+		{0, "Custom", "Custom"},
+	}, sameLabel(1,
+		"Library", "Cemetery", "Church", "Archive", "Album", "Web site", "Bookstore",
+		"Collection", "Safe",
+	)...),
+}
+
+var sourceMediaType = &grampsType{
+	class:   "SourceMediaType",
+	custom:  0,
+	unknown: -1,
+	values: append([]grampsTypeValue{
+		{-1, "Unknown", "Unknown"},
+		{0, "Custom", "Custom"},
+	}, sameLabel(1,
+		"Audio", "Book", "Card", "Electronic", "Fiche", "Film", "Magazine", "Manuscript",
+		"Map", "Newspaper", "Photo", "Tombstone", "Video",
+	)...),
+}
+
+var attributeType = &grampsType{
+	class:   "AttributeType",
+	custom:  0,
+	unknown: -1,
+	values: append([]grampsTypeValue{
+		{-1, "Unknown", "Unknown"},
+		{0, "Custom", "Custom"},
+	}, sameLabel(1,
+		"Caste", "Description", "Identification Number", "National Origin",
+		"Number of Children", "Social Security Number", "Nickname", "Cause", "Agency", "Age",
+		"Father's Age", "Mother's Age", "Witness", "Time", "Occupation",
+	)...),
+}
+
+var srcAttributeType = &grampsType{
+	class:   "SrcAttributeType",
+	custom:  0,
+	unknown: -1,
+	values: []grampsTypeValue{
+		{-1, "Unknown", "Unknown"},
+		{0, "Custom", "Custom"},
+	},
+}
+
+var confidenceType = &grampsType{
+	class:   "Confidence",
+	custom:  -1,
+	unknown: 2,
+	values: []grampsTypeValue{
+		{0, "0", "Very Low"},
+		{1, "1", "Low"},
+		{2, "2", "Normal"},
+		{3, "3", "High"},
+		{4, "4", "Very High"},
+	},
+}
+
+var styledTextTagType = &grampsType{
+	class:   "StyledTextTagType",
+	custom:  -1,
+	unknown: -1,
+	values: []grampsTypeValue{
+		{0, "bold", "Bold"},
+		{1, "italic", "Italic"},
+		{2, "underline", "Underline"},
+		{3, "fontface", "Fontface"},
+		{4, "fontsize", "Fontsize"},
+		{5, "fontcolor", "Fontcolor"},
+		{6, "highlight", "Highlight"},
+		{7, "superscript", "Superscript"},
+		{8, "link", "Link"},
+		{9, "strikethrough", "Strikethrough"},
+		{10, "subscript", "Subscript"},
+	},
+}
+
+var ldsOrdType = &grampsType{
+	class:   "LdsOrdType",
+	custom:  -1,
+	unknown: -1,
+	values: []grampsTypeValue{
+		{0, "baptism", "Baptism"},
+		{1, "endowment", "Endowment"},
+		{2, "sealed_to_parents", "Sealed to Parents"},
+		{3, "sealed_to_spouse", "Sealed to Spouse"},
+		{4, "confirmation", "Confirmation"},
+	},
+}
+
+var ldsOrdStatus = &grampsType{
+	class:   "LdsOrdStatus",
+	custom:  -1,
+	unknown: 0,
+	values: []grampsTypeValue{
+		{0, "None", "<No Status>"},
+		{1, "BIC", "BIC"},
+		{2, "Canceled", "Canceled"},
+		{3, "Child", "Child"},
+		{4, "Cleared", "Cleared"},
+		{5, "Completed", "Completed"},
+		{6, "DNS", "DNS"},
+		{7, "Infant", "Infant"},
+		{8, "Pre-1970", "Pre-1970"},
+		{9, "Qualified", "Qualified"},
+		{10, "DNS/CAN", "DNS/CAN"},
+		{11, "Stillborn", "Stillborn"},
+		{12, "Submitted", "Submitted"},
+		{13, "Uncleared", "Uncleared"},
+	},
+}
+
+var dateCalendars = &grampsType{
+	class:   "Calendar",
+	custom:  -1,
+	unknown: 0,
+	values: []grampsTypeValue{
+		{0, "", "Gregorian"},
+		{1, "Julian", "Julian"},
+		{2, "Hebrew", "Hebrew"},
+		{3, "French Republican", "French Republican"},
+		{4, "Persian", "Persian"},
+		{5, "Islamic", "Islamic"},
+		{6, "Swedish", "Swedish"},
+	},
+}
+
+var dateQualityCodes = &grampsType{
+	class:   "Quality",
+	custom:  -1,
+	unknown: 0,
+	values: []grampsTypeValue{
+		{0, "", "regular"},
+		{1, "estimated", "estimated"},
+		{2, "calculated", "calculated"},
+	},
+}
+
+var dateNewYears = &grampsType{
+	class:   "NewYear",
+	custom:  -1,
+	unknown: 0,
+	values: []grampsTypeValue{
+		{0, "", "January 1"},
+		{1, "Mar1", "March 1"},
+		{2, "Mar25", "March 25"},
+		{3, "Sep1", "September 1"},
+	},
+}
+
+// isStandard reports whether s is the XML form of one of the standard values
+// of t, other than the value marking custom text.
+func (t *grampsType) isStandard(s string) bool {
+	v, ok := t.byXML(s)
+	return ok && v.code != t.custom
+}
+
+// parse returns the XML form of the standard value of t whose XML form or
+// English label matches s, ignoring case and surrounding space, and true. If
+// there is no such value it returns s unchanged and false.
+func (t *grampsType) parse(s string) (string, bool) {
+	key := strings.TrimSpace(s)
+	for _, v := range t.values {
+		if v.code == t.custom {
+			continue
+		}
+		if strings.EqualFold(v.xml, key) || strings.EqualFold(v.label, key) {
+			return v.xml, true
+		}
+	}
+	return s, false
+}
+
+// label returns the English label of s, or s itself if it is not one of
+// the standard values of t.
+func (t *grampsType) label(s string) string {
+	if v, ok := t.byXML(s); ok && v.code != t.custom {
+		return v.label
+	}
+	return s
+}
@@ -0,0 +1,75 @@
+package grampsxml
+
+import (
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// newHandle returns a new handle in the form Gramps generates: the current
+// time in units of 100µs followed by a random number, both in hex, with the
+// leading underscore used in XML exports.
+func newHandle() string {
+	return fmt.Sprintf("_%08x%08x", time.Now().UnixNano()/1e5, rand.Uint32())
+}
+
+// changeStamp formats t as the Unix timestamp used by the change attribute.
+func changeStamp(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+// idSequence allocates Gramps IDs from a printf style pattern such as
+// "I%04d", skipping IDs that are already in use.
+type idSequence struct {
+	format string
+	next   int
+	used   map[string]bool
+}
+
+// checkIDFormats returns an error if any of the patterns, keyed by object
+// type, does not produce IDs that can be read back as the number they were
+// made from, as happens when a pattern lacks exactly one integer verb.
+func checkIDFormats(formats map[string]string) error {
+	for _, objType := range slices.Sorted(maps.Keys(formats)) {
+		format := formats[objType]
+		for _, n := range []int{0, 42} {
+			if got, ok := parseID(format, fmt.Sprintf(format, n)); !ok || got != n {
+				return fmt.Errorf("grampsxml: invalid %s ID format %q", objType, format)
+			}
+		}
+	}
+	return nil
+}
+
+func newIDSequence(format string) *idSequence {
+	return &idSequence{format: format, used: make(map[string]bool)}
+}
+
+// reserve marks id as in use.
+func (s *idSequence) reserve(id string) {
+	s.used[id] = true
+}
+
+// allocate returns the next unused ID and marks it as in use.
+func (s *idSequence) allocate() string {
+	for {
+		id := fmt.Sprintf(s.format, s.next)
+		s.next++
+		if !s.used[id] {
+			s.used[id] = true
+			return id
+		}
+	}
+}
+
+// parseID returns the number of id if it is in the form given by format.
+func parseID(format, id string) (int, bool) {
+	var n int
+	if _, err := fmt.Sscanf(id, format, &n); err != nil || n < 0 {
+		return 0, false
+	}
+	return n, fmt.Sprintf(format, n) == id
+}
@@ -0,0 +1,158 @@
+package grampsxml
+
+import "slices"
+
+// defaultIDFormats are the patterns Gramps uses for the IDs of each type of
+// object.
+var defaultIDFormats = map[string]string{
+	targetPerson:     "I%04d",
+	targetFamily:     "F%04d",
+	targetEvent:      "E%04d",
+	targetPlace:      "P%04d",
+	targetSource:     "S%04d",
+	targetCitation:   "C%04d",
+	targetMedia:      "O%04d",
+	targetRepository: "R%04d",
+	targetNote:       "N%04d",
+}
+
+// ImportOptions controls [Import].
+type ImportOptions struct {
+	// IDFormats holds the printf style patterns, such as "I%04d", used to
+	// allocate new Gramps IDs, keyed by object type as named in
+	// [ChangedObject]. Types that are not listed use the patterns Gramps
+	// uses by default. The IDs of objects of other types are never changed.
+	IDFormats map[string]string
+}
+
+// ImportReport describes the objects added to a database by [Import].
+type ImportReport struct {
+	Objects []ImportedObject `json:"objects"`
+}
+
+// ImportedObject records the handle and ID an imported object had in the
+// source database and those it was given in the target.
+type ImportedObject struct {
+	Type      string `json:"type"`
+	Handle    string `json:"handle"`
+	ID        string `json:"id,omitempty"`
+	NewHandle string `json:"new_handle"`
+	NewID     string `json:"new_id,omitempty"`
+
+	// Merged reports that a tag was merged with an existing tag of the same
+	// name instead of being added. NewHandle is the handle of that tag.
+	Merged bool `json:"merged,omitempty"`
+}
+
+// Import adds a copy of every primary object in src to dst, leaving src
+// unchanged. Objects whose handles are already used in dst by an object of
+// the same type are given new handles, and objects whose Gramps IDs are
+// already in use are given new IDs allocated from the patterns in opts.
+// Every reference held by the imported objects and the bookmarks of src is
+// rewritten to match. Tags are merged by name, so imported objects refer
+// to the existing tag when dst has a tag with the same name. Bookmarks that
+// dst does not already have are added, while the header and the home and
+// default people of dst are kept. An error is returned, and dst is left
+// unchanged, if any of the patterns in opts is invalid.
+func Import(dst, src *Database, opts ImportOptions) (*ImportReport, error) {
+	if err := checkIDFormats(opts.IDFormats); err != nil {
+		return nil, err
+	}
+	handles := make(map[string]bool)
+	ids := make(map[string]*idSequence)
+	sequence := func(objType string) *idSequence {
+		if s, ok := ids[objType]; ok {
+			return s
+		}
+		format := opts.IDFormats[objType]
+		if format == "" {
+			format = defaultIDFormats[objType]
+		}
+		var s *idSequence
+		if format != "" {
+			s = newIDSequence(format)
+		}
+		ids[objType] = s
+		return s
+	}
+	tagsByName := make(map[string]string)
+	walkObjects(dst, func(o primaryObject) {
+		handles[o.objType+"\x00"+o.handle] = true
+		if o.id != nil && *o.id != nil {
+			if s := sequence(o.objType); s != nil {
+				s.reserve(**o.id)
+			}
+		}
+	})
+	tags := dst.Tags.list()
+	for i := range tags {
+		tagsByName[tags[i].Name] = tags[i].Handle
+	}
+
+	imported := clone(*src)
+	report := &ImportReport{}
+	remap := make(map[string]string)
+	merged := make(map[string]bool)
+	walkObjects(&imported, func(o primaryObject) {
+		rec := ImportedObject{Type: o.objType, Handle: o.handle, NewHandle: o.handle}
+		if o.objType == targetTag {
+			name := o.value.(*Tag).Name
+			if h, ok := tagsByName[name]; ok {
+				rec.NewHandle, rec.Merged = h, true
+				merged[o.handle] = true
+				remap[o.objType+"\x00"+o.handle] = h
+				report.Objects = append(report.Objects, rec)
+				return
+			}
+		}
+
+		if handles[o.objType+"\x00"+o.handle] {
+			h := newHandle()
+			for handles[o.objType+"\x00"+h] {
+				h = newHandle()
+			}
+			rec.NewHandle = h
+			o.setHandle(h)
+			remap[o.objType+"\x00"+rec.Handle] = h
+		}
+		handles[o.objType+"\x00"+o.handle] = true
+		if o.objType == targetTag {
+			tagsByName[o.value.(*Tag).Name] = o.handle
+		}
+
+		if o.id != nil && *o.id != nil {
+			rec.ID, rec.NewID = **o.id, **o.id
+			if s := sequence(o.objType); s != nil {
+				if s.used[rec.ID] {
+					rec.NewID = s.allocate()
+					*o.id = new(rec.NewID)
+				} else {
+					s.reserve(rec.ID)
+				}
+			}
+		}
+		report.Objects = append(report.Objects, rec)
+	})
+
+	walkReferences(&imported, func(r reference) {
+		if h, ok := remap[r.target+"\x00"+*r.hlink]; ok {
+			*r.hlink = h
+		}
+	})
+	walkObjects(&imported, func(o primaryObject) {
+		if o.objType != targetTag || !merged[o.handle] {
+			addObject(dst, o.value)
+		}
+	})
+
+	for _, b := range imported.Bookmarks.list() {
+		if slices.Contains(dst.Bookmarks.list(), b) {
+			continue
+		}
+		if dst.Bookmarks == nil {
+			dst.Bookmarks = &Bookmarks{}
+		}
+		dst.Bookmarks.Bookmark = append(dst.Bookmarks.Bookmark, b)
+	}
+	return report, nil
+}
@@ -0,0 +1,114 @@
+package grampsxml
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestImport(t *testing.T) {
+	dst := &Database{
+		Tags: &Tags{Tag: []Tag{{Handle: "_t1", Name: "ToDo", Color: "#ff0000"}}},
+		People: &People{
+			Person: []Person{
+				{Handle: "_i1", ID: new("I0000"), Gender: "M"},
+				{Handle: "_i2", ID: new("I0001"), Gender: "F"},
+			},
+		},
+		Bookmarks: &Bookmarks{Bookmark: []Bookmark{{Target: "person", Hlink: "_i1"}}},
+	}
+	src := &Database{
+		Tags: &Tags{
+			Tag: []Tag{
+				{Handle: "_t9", Name: "ToDo", Color: "#00ff00"},
+				{Handle: "_t1", Name: "Checked"},
+			},
+		},
+		People: &People{
+			Person: []Person{
+				{Handle: "_i1", ID: new("I0000"), Gender: "F", Parentin: []Parentin{{Hlink: "_f1"}}, Tagref: []Tagref{{Hlink: "_t9"}, {Hlink: "_t1"}}},
+				{Handle: "_i7", ID: new("I0007"), Gender: "M", Parentin: []Parentin{{Hlink: "_f1"}}},
+			},
+		},
+		Families: &Families{
+			Family: []Family{
+				{Handle: "_f1", ID: new("F0000"), Father: &Father{Hlink: "_i7"}, Mother: &Mother{Hlink: "_i1"}},
+			},
+		},
+		Bookmarks: &Bookmarks{Bookmark: []Bookmark{{Target: "person", Hlink: "_i1"}}},
+	}
+	srcCopy := clone(*src)
+
+	report, err := Import(dst, src, ImportOptions{IDFormats: map[string]string{"person": "P%03d"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(&srcCopy, src); diff != "" {
+		t.Errorf("source was modified (-want +got):\n%s", diff)
+	}
+
+	var newPerson, newTag string
+	for _, o := range report.Objects {
+		if o.Type == "person" && o.Handle == "_i1" {
+			newPerson = o.NewHandle
+		}
+		if o.Type == "tag" && o.Handle == "_t1" {
+			newTag = o.NewHandle
+		}
+	}
+	if newPerson == "_i1" || newPerson == "" || newTag == "_t1" || newTag == "" {
+		t.Fatalf("colliding handles were not remapped: %v", report.Objects)
+	}
+
+	wantReport := []ImportedObject{
+		{Type: "tag", Handle: "_t9", NewHandle: "_t1", Merged: true},
+		{Type: "tag", Handle: "_t1", NewHandle: newTag},
+		{Type: "person", Handle: "_i1", ID: "I0000", NewHandle: newPerson, NewID: "P000"},
+		{Type: "person", Handle: "_i7", ID: "I0007", NewHandle: "_i7", NewID: "I0007"},
+		{Type: "family", Handle: "_f1", ID: "F0000", NewHandle: "_f1", NewID: "F0000"},
+	}
+	if diff := cmp.Diff(wantReport, report.Objects); diff != "" {
+		t.Errorf("report mismatch (-want +got):\n%s", diff)
+	}
+
+	want := &Database{
+		Tags: &Tags{
+			Tag: []Tag{
+				{Handle: "_t1", Name: "ToDo", Color: "#ff0000"},
+				{Handle: newTag, Name: "Checked"},
+			},
+		},
+		People: &People{
+			Person: []Person{
+				{Handle: "_i1", ID: new("I0000"), Gender: "M"},
+				{Handle: "_i2", ID: new("I0001"), Gender: "F"},
+				{Handle: newPerson, ID: new("P000"), Gender: "F", Parentin: []Parentin{{Hlink: "_f1"}}, Tagref: []Tagref{{Hlink: "_t1"}, {Hlink: newTag}}},
+				{Handle: "_i7", ID: new("I0007"), Gender: "M", Parentin: []Parentin{{Hlink: "_f1"}}},
+			},
+		},
+		Families: &Families{
+			Family: []Family{
+				{Handle: "_f1", ID: new("F0000"), Father: &Father{Hlink: "_i7"}, Mother: &Mother{Hlink: newPerson}},
+			},
+		},
+		Bookmarks: &Bookmarks{
+			Bookmark: []Bookmark{{Target: "person", Hlink: "_i1"}, {Target: "person", Hlink: newPerson}},
+		},
+	}
+	if diff := cmp.Diff(want, dst, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("database mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestImportInvalidFormat(t *testing.T) {
+	dst := &Database{}
+	src := &Database{People: &People{Person: []Person{{Handle: "_i1", ID: new("I0000")}}}}
+	if _, err := Import(dst, src, ImportOptions{IDFormats: map[string]string{"person": "P"}}); err == nil {
+		t.Errorf("expected error")
+	}
+	if dst.People != nil {
+		t.Errorf("destination was modified")
+	}
+}
@@ -0,0 +1,191 @@
+package grampsxml
+
+// Index provides lookup of the primary objects in a Database by handle.
+// It holds pointers into the database's slices so it must be rebuilt after
+// objects are added to or removed from the database.
+type Index struct {
+	people       map[string]*Person
+	families     map[string]*Family
+	events       map[string]*Event
+	places       map[string]*Placeobj
+	sources      map[string]*Source
+	citations    map[string]*Citation
+	objects      map[string]*Object
+	repositories map[string]*Repository
+	notes        map[string]*Note
+	tags         map[string]*Tag
+}
+
+// NewIndex builds an index of the objects in db.
+func NewIndex(db *Database) *Index {
+	ix := &Index{
+		people:       make(map[string]*Person),
+		families:     make(map[string]*Family),
+		events:       make(map[string]*Event),
+		places:       make(map[string]*Placeobj),
+		sources:      make(map[string]*Source),
+		citations:    make(map[string]*Citation),
+		objects:      make(map[string]*Object),
+		repositories: make(map[string]*Repository),
+		notes:        make(map[string]*Note),
+		tags:         make(map[string]*Tag),
+	}
+	people := db.People.list()
+	for i := range people {
+		ix.people[people[i].Handle] = &people[i]
+	}
+	families := db.Families.list()
+	for i := range families {
+		ix.families[families[i].Handle] = &families[i]
+	}
+	events := db.Events.list()
+	for i := range events {
+		ix.events[events[i].Handle] = &events[i]
+	}
+	places := db.Places.list()
+	for i := range places {
+		ix.places[places[i].Handle] = &places[i]
+	}
+	sources := db.Sources.list()
+	for i := range sources {
+		ix.sources[sources[i].Handle] = &sources[i]
+	}
+	citations := db.Citations.list()
+	for i := range citations {
+		ix.citations[citations[i].Handle] = &citations[i]
+	}
+	objects := db.Objects.list()
+	for i := range objects {
+		ix.objects[objects[i].Handle] = &objects[i]
+	}
+	repositories := db.Repositories.list()
+	for i := range repositories {
+		ix.repositories[repositories[i].Handle] = &repositories[i]
+	}
+	notes := db.Notes.list()
+	for i := range notes {
+		ix.notes[notes[i].Handle] = &notes[i]
+	}
+	tags := db.Tags.list()
+	for i := range tags {
+		ix.tags[tags[i].Handle] = &tags[i]
+	}
+	return ix
+}
+
+// Person returns the person with the given handle, or nil if there is none.
+func (ix *Index) Person(handle string) *Person { return ix.people[handle] }
+
+// Family returns the family with the given handle, or nil if there is none.
+func (ix *Index) Family(handle string) *Family { return ix.families[handle] }
+
+// Event returns the event with the given handle, or nil if there is none.
+func (ix *Index) Event(handle string) *Event { return ix.events[handle] }
+
+// Place returns the place with the given handle, or nil if there is none.
+func (ix *Index) Place(handle string) *Placeobj { return ix.places[handle] }
+
+// Source returns the source with the given handle, or nil if there is none.
+func (ix *Index) Source(handle string) *Source { return ix.sources[handle] }
+
+// Citation returns the citation with the given handle, or nil if there is none.
+func (ix *Index) Citation(handle string) *Citation { return ix.citations[handle] }
+
+// Object returns the media object with the given handle, or nil if there is none.
+func (ix *Index) Object(handle string) *Object { return ix.objects[handle] }
+
+// Repository returns the repository with the given handle, or nil if there is none.
+func (ix *Index) Repository(handle string) *Repository { return ix.repositories[handle] }
+
+// Note returns the note with the given handle, or nil if there is none.
+func (ix *Index) Note(handle string) *Note { return ix.notes[handle] }
+
+// Tag returns the tag with the given handle, or nil if there is none.
+func (ix *Index) Tag(handle string) *Tag { return ix.tags[handle] }
+
+// The list methods return the objects held by a container, treating a nil
+// container as empty.
+
+func (c *Tags) list() []Tag {
+	if c == nil {
+		return nil
+	}
+	return c.Tag
+}
+
+func (c *Events) list() []Event {
+	if c == nil {
+		return nil
+	}
+	return c.Event
+}
+
+func (c *People) list() []Person {
+	if c == nil {
+		return nil
+	}
+	return c.Person
+}
+
+func (c *Families) list() []Family {
+	if c == nil {
+		return nil
+	}
+	return c.Family
+}
+
+func (c *Citations) list() []Citation {
+	if c == nil {
+		return nil
+	}
+	return c.Citation
+}
+
+func (c *Sources) list() []Source {
+	if c == nil {
+		return nil
+	}
+	return c.Source
+}
+
+func (c *Places) list() []Placeobj {
+	if c == nil {
+		return nil
+	}
+	return c.Place
+}
+
+func (c *Objects) list() []Object {
+	if c == nil {
+		return nil
+	}
+	return c.Object
+}
+
+func (c *Repositories) list() []Repository {
+	if c == nil {
+		return nil
+	}
+	return c.Repository
+}
+
+func (c *Notes) list() []Note {
+	if c == nil {
+		return nil
+	}
+	return c.Note
+}
+
+func (c *Bookmarks) list() []Bookmark {
+	if c == nil {
+		return nil
+	}
+	return c.Bookmark
+}
+
+func (c *Namemaps) list() []Map {
+	if c == nil {
+		return nil
+	}
+	return c.Map
+}
@@ -0,0 +1,362 @@
+package grampsxml
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSONVersion is the version of the JSON encoding produced by
+// [Database.MarshalJSON]. It is incremented whenever a change is made that
+// an older decoder could not read correctly.
+//
+// The JSON encoding of a Database is an object with the following members,
+// each omitted when the corresponding part of the database is absent:
+//
+//	version         the encoding version, currently 1
+//	header          the export header
+//	name_formats    array of name formats
+//	tags, events, people, families, citations, sources, places, objects,
+//	repositories, notes, bookmarks, namemaps
+//	                arrays of the objects of each type
+//	default_person  handle of the default person
+//	home_person     handle of the home person
+//
+// Builds using the gramps_schema180 tag additionally write dnatests and
+// dnamatches arrays.
+//
+// Primary objects and the records nested within them are encoded using the
+// json struct tags in this package. Field names are lower case with words
+// separated by underscores, references to other objects are objects with a
+// single ref member holding the handle, and lists are named in the plural
+// (event_refs, names, attributes). Optional values are omitted when not
+// set; an explicit false or empty string is written so that it can be
+// distinguished from an absent value. The four mutually exclusive date
+// elements carried by events, names, citations and other records are
+// replaced by a single date member, described by [Date.MarshalJSON].
+//
+// Decoding a value produced by encoding a Database yields an identical
+// Database, except that empty lists decode as nil slices.
+const JSONVersion = 1
+
+// jsonDate is the JSON form of a Date.
+type jsonDate struct {
+	Kind      string  `json:"kind"`
+	Val       string  `json:"val,omitempty"`
+	Start     string  `json:"start,omitempty"`
+	Stop      string  `json:"stop,omitempty"`
+	Type      *string `json:"type,omitempty"`
+	Quality   *string `json:"quality,omitempty"`
+	Cformat   *string `json:"cformat,omitempty"`
+	Dualdated *bool   `json:"dualdated,omitempty"`
+	Newyear   *string `json:"newyear,omitempty"`
+	Text      string  `json:"text,omitempty"`
+}
+
+// Kinds of date used in the JSON encoding.
+const (
+	jsonDateVal   = "val"
+	jsonDateRange = "range"
+	jsonDateSpan  = "span"
+	jsonDateStr   = "str"
+)
+
+// MarshalJSON encodes the date as a single object. Its kind member is one
+// of "val", "range", "span" or "str" according to which date element is
+// present. The remaining members are named after the attributes of that
+// element: val, type, quality, cformat, dualdated and newyear for a date
+// value, start and stop in place of val for ranges and spans, and val alone
+// for a text date. A text member holding the date as formatted by
+// [Date.String] is included for display and ignored when decoding.
+// The zero Date encodes as null.
+func (d Date) MarshalJSON() ([]byte, error) {
+	jd := newJSONDate(d)
+	if jd == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(jd)
+}
+
+// UnmarshalJSON decodes a date encoded by MarshalJSON.
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var jd *jsonDate
+	if err := json.Unmarshal(b, &jd); err != nil {
+		return err
+	}
+	v, err := jd.date()
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func newJSONDate(d Date) *jsonDate {
+	switch {
+	case d.Dateval != nil:
+		v := d.Dateval
+		return &jsonDate{Kind: jsonDateVal, Val: v.Val, Type: v.Type, Quality: v.Quality, Cformat: v.Cformat, Dualdated: v.Dualdated, Newyear: v.Newyear, Text: d.String()}
+	case d.Daterange != nil:
+		v := d.Daterange
+		return &jsonDate{Kind: jsonDateRange, Start: v.Start, Stop: v.Stop, Quality: v.Quality, Cformat: v.Cformat, Dualdated: v.Dualdated, Newyear: v.Newyear, Text: d.String()}
+	case d.Datespan != nil:
+		v := d.Datespan
+		return &jsonDate{Kind: jsonDateSpan, Start: v.Start, Stop: v.Stop, Quality: v.Quality, Cformat: v.Cformat, Dualdated: v.Dualdated, Newyear: v.Newyear, Text: d.String()}
+	case d.Datestr != nil:
+		return &jsonDate{Kind: jsonDateStr, Val: d.Datestr.Val, Text: d.String()}
+	}
+	return nil
+}
+
+func (jd *jsonDate) date() (Date, error) {
+	if jd == nil {
+		return Date{}, nil
+	}
+	switch jd.Kind {
+	case jsonDateVal:
+		return Date{Dateval: &Dateval{Val: jd.Val, Type: jd.Type, Quality: jd.Quality, Cformat: jd.Cformat, Dualdated: jd.Dualdated, Newyear: jd.Newyear}}, nil
+	case jsonDateRange:
+		return Date{Daterange: &Daterange{Start: jd.Start, Stop: jd.Stop, Quality: jd.Quality, Cformat: jd.Cformat, Dualdated: jd.Dualdated, Newyear: jd.Newyear}}, nil
+	case jsonDateSpan:
+		return Date{Datespan: &Datespan{Start: jd.Start, Stop: jd.Stop, Quality: jd.Quality, Cformat: jd.Cformat, Dualdated: jd.Dualdated, Newyear: jd.Newyear}}, nil
+	case jsonDateStr:
+		return Date{Datestr: &Datestr{Val: jd.Val}}, nil
+	}
+	return Date{}, fmt.Errorf("grampsxml: unknown date kind %q", jd.Kind)
+}
+
+// MarshalJSON encodes the database using the versioned layout described by
+// [JSONVersion].
+func (db Database) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONDatabase(&db))
+}
+
+// UnmarshalJSON decodes a database encoded by MarshalJSON. It returns an
+// error if the encoding version is missing or newer than [JSONVersion].
+func (db *Database) UnmarshalJSON(b []byte) error {
+	var jdb jsonDatabase
+	if err := json.Unmarshal(b, &jdb); err != nil {
+		return err
+	}
+	if jdb.Version == 0 {
+		return fmt.Errorf("grampsxml: missing JSON encoding version")
+	}
+	if jdb.Version > JSONVersion {
+		return fmt.Errorf("grampsxml: unsupported JSON encoding version %d", jdb.Version)
+	}
+	*db = Database{}
+	jdb.copyTo(db)
+	return nil
+}
+
+// jsonList returns a pointer to the slice held by a list container, or nil
+// if the container is absent, so that an empty container is written as []
+// and an absent one is omitted.
+func jsonList[T any](present bool, s []T) *[]T {
+	if !present {
+		return nil
+	}
+	if s == nil {
+		s = []T{}
+	}
+	return &s
+}
+
+// fromJSONList is the inverse of jsonList, returning the slice to store in
+// the container and whether the container is present.
+func fromJSONList[T any](p *[]T) ([]T, bool) {
+	if p == nil {
+		return nil, false
+	}
+	if len(*p) == 0 {
+		return nil, true
+	}
+	return *p, true
+}
+
+// The types below carry the four date elements. Their JSON encodings
+// replace those elements with a single date member.
+
+// MarshalJSON encodes the event with its date as a single date member.
+func (e Event) MarshalJSON() ([]byte, error) {
+	type plain Event
+	return json.Marshal(struct {
+		plain
+		Date Date `json:"date,omitzero"`
+	}{plain(e), e.Date()})
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *Event) UnmarshalJSON(b []byte) error {
+	type plain Event
+	v := struct {
+		*plain
+		Date Date `json:"date"`
+	}{plain: (*plain)(e)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	e.SetDate(v.Date)
+	return nil
+}
+
+// MarshalJSON encodes the name with its date as a single date member.
+func (n Name) MarshalJSON() ([]byte, error) {
+	type plain Name
+	return json.Marshal(struct {
+		plain
+		Date Date `json:"date,omitzero"`
+	}{plain(n), n.Date()})
+}
+
+// UnmarshalJSON decodes a name encoded by MarshalJSON.
+func (n *Name) UnmarshalJSON(b []byte) error {
+	type plain Name
+	v := struct {
+		*plain
+		Date Date `json:"date"`
+	}{plain: (*plain)(n)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	n.SetDate(v.Date)
+	return nil
+}
+
+// MarshalJSON encodes the address with its date as a single date member.
+func (a Address) MarshalJSON() ([]byte, error) {
+	type plain Address
+	return json.Marshal(struct {
+		plain
+		Date Date `json:"date,omitzero"`
+	}{plain(a), a.Date()})
+}
+
+// UnmarshalJSON decodes an address encoded by MarshalJSON.
+func (a *Address) UnmarshalJSON(b []byte) error {
+	type plain Address
+	v := struct {
+		*plain
+		Date Date `json:"date"`
+	}{plain: (*plain)(a)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	a.SetDate(v.Date)
+	return nil
+}
+
+// MarshalJSON encodes the place name with its date as a single date member.
+func (p Pname) MarshalJSON() ([]byte, error) {
+	type plain Pname
+	return json.Marshal(struct {
+		plain
+		Date Date `json:"date,omitzero"`
+	}{plain(p), p.Date()})
+}
+
+// UnmarshalJSON decodes a place name encoded by MarshalJSON.
+func (p *Pname) UnmarshalJSON(b []byte) error {
+	type plain Pname
+	v := struct {
+		*plain
+		Date Date `json:"date"`
+	}{plain: (*plain)(p)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	p.SetDate(v.Date)
+	return nil
+}
+
+// MarshalJSON encodes the place reference with its date as a single date
+// member.
+func (p Placeref) MarshalJSON() ([]byte, error) {
+	type plain Placeref
+	return json.Marshal(struct {
+		plain
+		Date Date `json:"date,omitzero"`
+	}{plain(p), p.Date()})
+}
+
+// UnmarshalJSON decodes a place reference encoded by MarshalJSON.
+func (p *Placeref) UnmarshalJSON(b []byte) error {
+	type plain Placeref
+	v := struct {
+		*plain
+		Date Date `json:"date"`
+	}{plain: (*plain)(p)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	p.SetDate(v.Date)
+	return nil
+}
+
+// MarshalJSON encodes the media object with its date as a single date member.
+func (o Object) MarshalJSON() ([]byte, error) {
+	type plain Object
+	return json.Marshal(struct {
+		plain
+		Date Date `json:"date,omitzero"`
+	}{plain(o), o.Date()})
+}
+
+// UnmarshalJSON decodes a media object encoded by MarshalJSON.
+func (o *Object) UnmarshalJSON(b []byte) error {
+	type plain Object
+	v := struct {
+		*plain
+		Date Date `json:"date"`
+	}{plain: (*plain)(o)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	o.SetDate(v.Date)
+	return nil
+}
+
+// MarshalJSON encodes the citation with its date as a single date member.
+func (c Citation) MarshalJSON() ([]byte, error) {
+	type plain Citation
+	return json.Marshal(struct {
+		plain
+		Date Date `json:"date,omitzero"`
+	}{plain(c), c.Date()})
+}
+
+// UnmarshalJSON decodes a citation encoded by MarshalJSON.
+func (c *Citation) UnmarshalJSON(b []byte) error {
+	type plain Citation
+	v := struct {
+		*plain
+		Date Date `json:"date"`
+	}{plain: (*plain)(c)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	c.SetDate(v.Date)
+	return nil
+}
+
+// MarshalJSON encodes the LDS ordinance with its date as a single date member.
+func (l LdsOrd) MarshalJSON() ([]byte, error) {
+	type plain LdsOrd
+	return json.Marshal(struct {
+		plain
+		Date Date `json:"date,omitzero"`
+	}{plain(l), l.Date()})
+}
+
+// UnmarshalJSON decodes an LDS ordinance encoded by MarshalJSON.
+func (l *LdsOrd) UnmarshalJSON(b []byte) error {
+	type plain LdsOrd
+	v := struct {
+		*plain
+		Date Date `json:"date"`
+	}{plain: (*plain)(l)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	l.SetDate(v.Date)
+	return nil
+}
@@ -0,0 +1,105 @@
+package grampsxml
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testJSONRoundTrip(t *testing.T, cases []wellFormedCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.want)
+			if err != nil {
+				t.Fatalf("unexpected marshal error: %v", err)
+			}
+
+			var db Database
+			if err := json.Unmarshal(data, &db); err != nil {
+				t.Fatalf("unexpected unmarshal error: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, &db); diff != "" {
+				t.Errorf("round trip mismatch (-want +got):\n%s\njson: %s", diff, data)
+			}
+		})
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	testJSONRoundTrip(t, wellFormedCases)
+	testJSONRoundTrip(t, []wellFormedCase{
+		{
+			name: "dates",
+			want: &Database{
+				Events: &Events{
+					Event: []Event{
+						{Handle: "_e1", Daterange: &Daterange{Start: "1850", Stop: "1860", Quality: new("estimated")}},
+						{Handle: "_e2", Datespan: &Datespan{Start: "1850", Stop: "1860", Cformat: new("Julian"), Dualdated: new(false)}},
+						{Handle: "_e3", Datestr: &Datestr{Val: "Christmas"}},
+						{Handle: "_e4", Dateval: &Dateval{Val: "1850", Type: new("")}},
+					},
+				},
+				People: &People{Home: new("_p1")},
+				Notes:  &Notes{},
+			},
+		},
+	})
+}
+
+func TestDatabaseValueJSON(t *testing.T) {
+	want := Database{Notes: &Notes{Note: []Note{{Handle: "_n1", Type: "General", Text: "value"}}}}
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("unexpected marshal error: %v", err)
+	}
+	var got Database
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unexpected unmarshal error: %v\njson: %s", err, data)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestEventJSON(t *testing.T) {
+	ev := Event{
+		ID:      new("E0001"),
+		Handle:  "_e1",
+		Priv:    new(true),
+		Change:  "1700000000",
+		Type:    new("Birth"),
+		Dateval: &Dateval{Val: "1850-03", Type: new("about")},
+		Place:   &Place{Hlink: "_p1"},
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		t.Fatalf("unexpected marshal error: %v", err)
+	}
+	want := `{"id":"E0001","handle":"_e1","private":true,"change":"1700000000","type":"Birth","place":{"ref":"_p1"},"date":{"kind":"val","val":"1850-03","type":"about","text":"about 1850-03"}}`
+	if diff := cmp.Diff(want, string(data)); diff != "" {
+		t.Errorf("json mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDatabaseJSONVersion(t *testing.T) {
+	testCases := []struct {
+		input   string
+		wantErr bool
+	}{
+		{input: `{"version":1,"header":{"created":{"date":"2024-01-01","version":"5.2.0"}}}`},
+		{input: `{"header":{"created":{"date":"2024-01-01","version":"5.2.0"}}}`, wantErr: true},
+		{input: `{"version":2}`, wantErr: true},
+		{input: `{"version":1,"events":[{"handle":"_e1","date":{"kind":"bogus"}}]}`, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		var db Database
+		err := json.Unmarshal([]byte(tc.input), &db)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, want error %v", tc.input, err, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+//go:build gramps_schema180
+
+package grampsxml
+
+import "encoding/json"
+
+// jsonDatabase is the JSON form of a Database.
+type jsonDatabase struct {
+	Version       int           `json:"version"`
+	Header        Header        `json:"header"`
+	NameFormats   []NameFormat  `json:"name_formats,omitempty"`
+	Tags          *[]Tag        `json:"tags,omitempty"`
+	Events        *[]Event      `json:"events,omitempty"`
+	People        *[]Person     `json:"people,omitempty"`
+	DefaultPerson *string       `json:"default_person,omitempty"`
+	HomePerson    *string       `json:"home_person,omitempty"`
+	Families      *[]Family     `json:"families,omitempty"`
+	Citations     *[]Citation   `json:"citations,omitempty"`
+	Sources       *[]Source     `json:"sources,omitempty"`
+	Places        *[]Placeobj   `json:"places,omitempty"`
+	Objects       *[]Object     `json:"objects,omitempty"`
+	Repositories  *[]Repository `json:"repositories,omitempty"`
+	Notes         *[]Note       `json:"notes,omitempty"`
+	DNATests      *[]DNATest    `json:"dnatests,omitempty"`
+	DNAMatches    *[]DNAMatch   `json:"dnamatches,omitempty"`
+	Bookmarks     *[]Bookmark   `json:"bookmarks,omitempty"`
+	Namemaps      *[]Map        `json:"namemaps,omitempty"`
+}
+
+func newJSONDatabase(db *Database) *jsonDatabase {
+	jdb := &jsonDatabase{
+		Version:      JSONVersion,
+		Header:       db.Header,
+		NameFormats:  db.NameFormats,
+		Tags:         jsonList(db.Tags != nil, db.Tags.list()),
+		Events:       jsonList(db.Events != nil, db.Events.list()),
+		People:       jsonList(db.People != nil, db.People.list()),
+		Families:     jsonList(db.Families != nil, db.Families.list()),
+		Citations:    jsonList(db.Citations != nil, db.Citations.list()),
+		Sources:      jsonList(db.Sources != nil, db.Sources.list()),
+		Places:       jsonList(db.Places != nil, db.Places.list()),
+		Objects:      jsonList(db.Objects != nil, db.Objects.list()),
+		Repositories: jsonList(db.Repositories != nil, db.Repositories.list()),
+		Notes:        jsonList(db.Notes != nil, db.Notes.list()),
+		DNATests:     jsonList(db.DNATests != nil, db.DNATests.list()),
+		DNAMatches:   jsonList(db.DNAMatches != nil, db.DNAMatches.list()),
+		Bookmarks:    jsonList(db.Bookmarks != nil, db.Bookmarks.list()),
+		Namemaps:     jsonList(db.Namemaps != nil, db.Namemaps.list()),
+	}
+	if db.People != nil {
+		jdb.DefaultPerson = db.People.Default
+		jdb.HomePerson = db.People.Home
+	}
+	return jdb
+}
+
+func (jdb *jsonDatabase) copyTo(db *Database) {
+	db.Header = jdb.Header
+	db.NameFormats = jdb.NameFormats
+	if s, ok := fromJSONList(jdb.Tags); ok {
+		db.Tags = &Tags{Tag: s}
+	}
+	if s, ok := fromJSONList(jdb.Events); ok {
+		db.Events = &Events{Event: s}
+	}
+	if s, ok := fromJSONList(jdb.People); ok || jdb.DefaultPerson != nil || jdb.HomePerson != nil {
+		db.People = &People{Person: s, Default: jdb.DefaultPerson, Home: jdb.HomePerson}
+	}
+	if s, ok := fromJSONList(jdb.Families); ok {
+		db.Families = &Families{Family: s}
+	}
+	if s, ok := fromJSONList(jdb.Citations); ok {
+		db.Citations = &Citations{Citation: s}
+	}
+	if s, ok := fromJSONList(jdb.Sources); ok {
+		db.Sources = &Sources{Source: s}
+	}
+	if s, ok := fromJSONList(jdb.Places); ok {
+		db.Places = &Places{Place: s}
+	}
+	if s, ok := fromJSONList(jdb.Objects); ok {
+		db.Objects = &Objects{Object: s}
+	}
+	if s, ok := fromJSONList(jdb.Repositories); ok {
+		db.Repositories = &Repositories{Repository: s}
+	}
+	if s, ok := fromJSONList(jdb.Notes); ok {
+		db.Notes = &Notes{Note: s}
+	}
+	if s, ok := fromJSONList(jdb.DNATests); ok {
+		db.DNATests = &DNATests{DNATest: s}
+	}
+	if s, ok := fromJSONList(jdb.DNAMatches); ok {
+		db.DNAMatches = &DNAMatches{DNAMatch: s}
+	}
+	if s, ok := fromJSONList(jdb.Bookmarks); ok {
+		db.Bookmarks = &Bookmarks{Bookmark: s}
+	}
+	if s, ok := fromJSONList(jdb.Namemaps); ok {
+		db.Namemaps = &Namemaps{Map: s}
+	}
+}
+
+// MarshalJSON encodes the DNA test with its date as a single date member.
+func (t DNATest) MarshalJSON() ([]byte, error) {
+	type plain DNATest
+	return json.Marshal(struct {
+		plain
+		Date Date `json:"date,omitzero"`
+	}{plain(t), t.Date()})
+}
+
+// UnmarshalJSON decodes a DNA test encoded by MarshalJSON.
+func (t *DNATest) UnmarshalJSON(b []byte) error {
+	type plain DNATest
+	v := struct {
+		*plain
+		Date Date `json:"date"`
+	}{plain: (*plain)(t)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	t.SetDate(v.Date)
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build gramps_schema180
+
+package grampsxml
+
+import "testing"
+
+func TestJSONRoundTrip180(t *testing.T) {
+	testJSONRoundTrip(t, wellFormedCases180)
+}
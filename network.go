@@ -0,0 +1,374 @@
+package grampsxml
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Kinds of edge in a network export.
+const (
+	edgeParent      = "parent"
+	edgeSpouse      = "spouse"
+	edgeAssociation = "association"
+)
+
+// NetworkOptions selects the people and families included in a network
+// export and how they are represented.
+type NetworkOptions struct {
+	GraphOptions
+
+	// FamilyNodes makes each family a node of its own, as in WriteDOT, so
+	// that the family's attributes are node data alongside those of people.
+	FamilyNodes bool
+}
+
+// Kinds of node in a network export with family nodes.
+const (
+	nodePerson = "person"
+	nodeFamily = "family"
+)
+
+// network is the graph of people written by the network exporters.
+type network struct {
+	nodes []networkNode
+	edges []networkEdge
+
+	// familyNodes is set when families are nodes rather than spouse edges.
+	familyNodes bool
+
+	// Attribute types found on the selected people and on the families of
+	// spouse edges, or on the selected people and families if families are
+	// nodes, in order of first appearance.
+	nodeAttrs []string
+	edgeAttrs []string
+}
+
+type networkNode struct {
+	handle string
+	values []string // id, label, gender, birth, death, [kind,] then nodeAttrs
+}
+
+type networkEdge struct {
+	source, target string
+	kind           string
+	label          string
+	family         *Family // family of parent and spouse edges
+}
+
+// networkNodeKeys are the names of the data columns written for every node,
+// before those for person attributes.
+var networkNodeKeys = []string{"gramps_id", "label", "gender", "birth", "death"}
+
+// networkEdgeKeys are the names of the data columns written for every edge,
+// before those for family attributes.
+var networkEdgeKeys = []string{"kind", "label", "family"}
+
+func newNetwork(db *Database, opts NetworkOptions) (*network, error) {
+	ix := NewIndex(db)
+	sel, err := opts.selection(ix, db)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &network{familyNodes: opts.FamilyNodes}
+	seenNodeAttr := make(map[string]bool)
+	seenEdgeAttr := make(map[string]bool)
+	addAttrs := func(attrs *[]string, seen map[string]bool, as []Attribute) {
+		for _, a := range as {
+			if !seen[a.Type] {
+				seen[a.Type] = true
+				*attrs = append(*attrs, a.Type)
+			}
+		}
+	}
+
+	var nodePeople []*Person
+	var nodeFamilies []*Family
+	people := db.People.list()
+	for i := range people {
+		p := &people[i]
+		if !sel.people[p.Handle] {
+			continue
+		}
+		addAttrs(&n.nodeAttrs, seenNodeAttr, p.Attribute)
+		n.nodes = append(n.nodes, networkNode{handle: p.Handle})
+		nodePeople = append(nodePeople, p)
+		for _, pr := range p.Personref {
+			if sel.people[pr.Hlink] {
+				n.edges = append(n.edges, networkEdge{source: p.Handle, target: pr.Hlink, kind: edgeAssociation, label: pr.Rel})
+			}
+		}
+	}
+
+	families := db.Families.list()
+	for i := range families {
+		f := &families[i]
+		if !sel.families[f.Handle] {
+			continue
+		}
+		if n.familyNodes {
+			addAttrs(&n.nodeAttrs, seenNodeAttr, f.Attribute)
+			n.nodes = append(n.nodes, networkNode{handle: f.Handle})
+			nodeFamilies = append(nodeFamilies, f)
+			n.addFamilyEdges(f, sel)
+			continue
+		}
+		if f.Father != nil && f.Mother != nil && sel.people[f.Father.Hlink] && sel.people[f.Mother.Hlink] {
+			var rel string
+			if f.Rel != nil {
+				rel = f.Rel.Type
+			}
+			n.edges = append(n.edges, networkEdge{source: f.Father.Hlink, target: f.Mother.Hlink, kind: edgeSpouse, label: rel, family: f})
+			addAttrs(&n.edgeAttrs, seenEdgeAttr, f.Attribute)
+		}
+		for _, cr := range f.Childref {
+			if !sel.people[cr.Hlink] {
+				continue
+			}
+			if f.Father != nil && sel.people[f.Father.Hlink] {
+				n.edges = append(n.edges, networkEdge{source: f.Father.Hlink, target: cr.Hlink, kind: edgeParent, label: childRel(cr.Frel), family: f})
+			}
+			if f.Mother != nil && sel.people[f.Mother.Hlink] {
+				n.edges = append(n.edges, networkEdge{source: f.Mother.Hlink, target: cr.Hlink, kind: edgeParent, label: childRel(cr.Mrel), family: f})
+			}
+		}
+	}
+
+	for i, p := range nodePeople {
+		var birth, death string
+		if ev := personEvent(ix, p, "Birth"); ev != nil {
+			birth = ev.Date().String()
+		}
+		if ev := personEvent(ix, p, "Death"); ev != nil {
+			death = ev.Date().String()
+		}
+		vals := []string{strval(p.ID), fullName(primaryName(p)), csvGender(p.Gender), birth, death}
+		if n.familyNodes {
+			vals = append(vals, nodePerson)
+		}
+		n.nodes[i].values = append(vals, attributeValues(p.Attribute, n.nodeAttrs)...)
+	}
+	for i, f := range nodeFamilies {
+		var names []string
+		for _, h := range familyParents(f) {
+			if p := ix.Person(h); p != nil {
+				names = append(names, fullName(primaryName(p)))
+			}
+		}
+		vals := []string{strval(f.ID), strings.Join(names, " & "), "", "", "", nodeFamily}
+		n.nodes[len(nodePeople)+i].values = append(vals, attributeValues(f.Attribute, n.nodeAttrs)...)
+	}
+	return n, nil
+}
+
+// addFamilyEdges adds the edges of a family node: a spouse edge from each
+// selected parent to the family, labelled with the family's relationship
+// type, and a parent edge from the family to each selected child, labelled
+// with the child's relationship to its parents.
+func (n *network) addFamilyEdges(f *Family, sel *graphSelection) {
+	var rel string
+	if f.Rel != nil {
+		rel = f.Rel.Type
+	}
+	for _, h := range familyParents(f) {
+		if sel.people[h] {
+			n.edges = append(n.edges, networkEdge{source: h, target: f.Handle, kind: edgeSpouse, label: rel, family: f})
+		}
+	}
+	for _, cr := range f.Childref {
+		if !sel.people[cr.Hlink] {
+			continue
+		}
+		n.edges = append(n.edges, networkEdge{source: f.Handle, target: cr.Hlink, kind: edgeParent, label: familyChildRel(f, cr), family: f})
+	}
+}
+
+// familyChildRel returns the relationship of a child to the parents of f.
+// If the child is related differently to the father and the mother, both
+// are given, father first, separated by a slash.
+func familyChildRel(f *Family, cr Childref) string {
+	var rels []string
+	if f.Father != nil && f.Father.Hlink != "" {
+		rels = append(rels, childRel(cr.Frel))
+	}
+	if f.Mother != nil && f.Mother.Hlink != "" {
+		if r := childRel(cr.Mrel); len(rels) == 0 || rels[0] != r {
+			rels = append(rels, r)
+		}
+	}
+	if len(rels) == 0 {
+		return childRel(nil)
+	}
+	return strings.Join(rels, "/")
+}
+
+// nodeKeys returns the names of the data columns of the nodes.
+func (n *network) nodeKeys() []string {
+	keys := append([]string{}, networkNodeKeys...)
+	if n.familyNodes {
+		keys = append(keys, "kind")
+	}
+	return append(keys, n.nodeAttrs...)
+}
+
+// values returns the data columns of the edge, matching networkEdgeKeys
+// followed by attrs.
+func (e *networkEdge) values(attrs []string) []string {
+	var family string
+	if e.family != nil {
+		family = strval(e.family.ID)
+	}
+	vals := []string{e.kind, e.label, family}
+	if e.kind == edgeSpouse {
+		return append(vals, attributeValues(e.family.Attribute, attrs)...)
+	}
+	return append(vals, make([]string, len(attrs))...)
+}
+
+// childRel returns the relationship of a child to a parent, which defaults
+// to Birth.
+func childRel(rel *string) string {
+	if rel == nil || *rel == "" {
+		return "Birth"
+	}
+	return *rel
+}
+
+// attributeValues returns the value of the first attribute of each of the
+// given types, or "" for types with no attribute.
+func attributeValues(as []Attribute, types []string) []string {
+	vals := make([]string, len(types))
+	for i, t := range types {
+		for _, a := range as {
+			if a.Type == t {
+				vals[i] = a.Value
+				break
+			}
+		}
+	}
+	return vals
+}
+
+// WriteGraphML writes the people in db that are selected by opts to w as a
+// GraphML graph for use in network analysis tools such as Gephi and
+// NetworkX.
+//
+// Each person is a node identified by their handle, with data giving their
+// Gramps ID, name, gender, birth and death dates and the value of each of
+// their attributes, keyed by attribute type. Edges are directed and have a
+// kind, which is one of "parent", "spouse" or "association", and a label:
+//
+//   - parent edges run from each parent to each child of a family and are
+//     labelled with the child's relationship to that parent, such as Birth
+//     or Adopted
+//   - spouse edges run from the father to the mother of a family and are
+//     labelled with the family's relationship type; they also carry the
+//     family's attributes
+//   - association edges run from a person to each person they refer to and
+//     are labelled with the association, such as Godfather
+//
+// Parent and spouse edges also carry the Gramps ID of the family.
+//
+// If opts.FamilyNodes is set, each family is also a node, identified by its
+// handle, with data giving its Gramps ID, the names of its parents and the
+// value of each of its attributes. Nodes then have a kind, which is "person"
+// or "family". Spouse edges run from each parent to the family instead and
+// parent edges run from the family to each child, labelled with the child's
+// relationship to both parents, such as Birth or Birth/Stepchild. Edges do
+// not carry the family's attributes.
+func WriteGraphML(w io.Writer, db *Database, opts NetworkOptions) error {
+	n, err := newNetwork(db, opts)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(bw, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+
+	nodeKeys := n.nodeKeys()
+	edgeKeys := append(append([]string{}, networkEdgeKeys...), n.edgeAttrs...)
+	for i, k := range nodeKeys {
+		fmt.Fprintf(bw, "  <key id=\"n%d\" for=\"node\" attr.name=\"%s\" attr.type=\"string\"/>\n", i, xmlEscape(k))
+	}
+	for i, k := range edgeKeys {
+		fmt.Fprintf(bw, "  <key id=\"e%d\" for=\"edge\" attr.name=\"%s\" attr.type=\"string\"/>\n", i, xmlEscape(k))
+	}
+
+	fmt.Fprintln(bw, `  <graph id="G" edgedefault="directed">`)
+	for _, node := range n.nodes {
+		fmt.Fprintf(bw, "    <node id=\"%s\">\n", xmlEscape(node.handle))
+		writeGraphMLData(bw, "n", node.values)
+		fmt.Fprintln(bw, "    </node>")
+	}
+	for i := range n.edges {
+		e := &n.edges[i]
+		fmt.Fprintf(bw, "    <edge id=\"e%d\" source=\"%s\" target=\"%s\">\n", i, xmlEscape(e.source), xmlEscape(e.target))
+		writeGraphMLData(bw, "e", e.values(n.edgeAttrs))
+		fmt.Fprintln(bw, "    </edge>")
+	}
+	fmt.Fprintln(bw, "  </graph>")
+	fmt.Fprintln(bw, "</graphml>")
+	return bw.Flush()
+}
+
+func writeGraphMLData(w io.Writer, prefix string, values []string) {
+	for i, v := range values {
+		if v != "" {
+			fmt.Fprintf(w, "      <data key=\"%s%d\">%s</data>\n", prefix, i, xmlEscape(v))
+		}
+	}
+}
+
+func xmlEscape(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+// WriteNodeListCSV writes the people in db that are selected by opts to w
+// as a CSV node table in the form imported by Gephi, along with the
+// families if opts.FamilyNodes is set. The Id column holds the handle of
+// the person or family and the remaining columns hold the node data
+// described by [WriteGraphML].
+func WriteNodeListCSV(w io.Writer, db *Database, opts NetworkOptions) error {
+	n, err := newNetwork(db, opts)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	header := []string{"Id", "Gramps ID", "Label", "Gender", "Birth", "Death"}
+	if n.familyNodes {
+		header = append(header, "Kind")
+	}
+	cw.Write(append(header, n.nodeAttrs...))
+	for _, node := range n.nodes {
+		cw.Write(append([]string{node.handle}, node.values...))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteEdgeListCSV writes the relationships between the people in db that
+// are selected by opts to w as a CSV edge table in the form imported by
+// Gephi. The Source and Target columns hold the handles of the people, or
+// families if opts.FamilyNodes is set, connected by the edge and the
+// remaining columns hold the edge data described by [WriteGraphML].
+func WriteEdgeListCSV(w io.Writer, db *Database, opts NetworkOptions) error {
+	n, err := newNetwork(db, opts)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(append([]string{"Source", "Target", "Type", "Kind", "Label", "Family"}, n.edgeAttrs...))
+	for i := range n.edges {
+		e := &n.edges[i]
+		cw.Write(append([]string{e.source, e.target, "Directed"}, e.values(n.edgeAttrs)...))
+	}
+	cw.Flush()
+	return cw.Error()
+}
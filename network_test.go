@@ -0,0 +1,253 @@
+package grampsxml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var networkSample = Database{
+	Events: &Events{
+		Event: []Event{
+			{Handle: "_e1", Type: new("Birth"), Dateval: &Dateval{Val: "1855-06-21"}},
+		},
+	},
+	People: &People{
+		Person: []Person{
+			{
+				Handle:    "_i1",
+				ID:        new("I0001"),
+				Gender:    "M",
+				Name:      []Name{{First: new("Lewis"), Surname: []Surname{{Surname: "Garner"}}}},
+				Eventref:  []Eventref{{Hlink: "_e1"}},
+				Attribute: []Attribute{{Type: "Occupation", Value: "Miller"}},
+				Parentin:  []Parentin{{Hlink: "_f1"}},
+				Personref: []Personref{{Hlink: "_i4", Rel: "Godfather"}},
+			},
+			{
+				Handle:   "_i2",
+				ID:       new("I0002"),
+				Gender:   "F",
+				Name:     []Name{{First: new("Anna"), Surname: []Surname{{Surname: "Zieliński"}}}},
+				Parentin: []Parentin{{Hlink: "_f1"}},
+			},
+			{
+				Handle:    "_i3",
+				ID:        new("I0003"),
+				Gender:    "F",
+				Name:      []Name{{First: new("Eliza"), Surname: []Surname{{Surname: "Garner"}}}},
+				Attribute: []Attribute{{Type: "Nickname", Value: "Lizzie & Liz"}},
+				Childof:   []Childof{{Hlink: "_f1"}},
+			},
+			{
+				Handle: "_i4",
+				ID:     new("I0004"),
+				Gender: "M",
+				Name:   []Name{{First: new("Arthur"), Surname: []Surname{{Surname: "Hall"}}}},
+			},
+		},
+	},
+	Families: &Families{
+		Family: []Family{
+			{
+				Handle:    "_f1",
+				ID:        new("F0001"),
+				Rel:       &Rel{Type: "Married"},
+				Father:    &Father{Hlink: "_i1"},
+				Mother:    &Mother{Hlink: "_i2"},
+				Childref:  []Childref{{Hlink: "_i3", Mrel: new("Stepchild")}},
+				Attribute: []Attribute{{Type: "Number of Children", Value: "1"}},
+			},
+		},
+	},
+}
+
+func TestWriteGraphML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteGraphML(&buf, &networkSample, NetworkOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="n0" for="node" attr.name="gramps_id" attr.type="string"/>
+  <key id="n1" for="node" attr.name="label" attr.type="string"/>
+  <key id="n2" for="node" attr.name="gender" attr.type="string"/>
+  <key id="n3" for="node" attr.name="birth" attr.type="string"/>
+  <key id="n4" for="node" attr.name="death" attr.type="string"/>
+  <key id="n5" for="node" attr.name="Occupation" attr.type="string"/>
+  <key id="n6" for="node" attr.name="Nickname" attr.type="string"/>
+  <key id="e0" for="edge" attr.name="kind" attr.type="string"/>
+  <key id="e1" for="edge" attr.name="label" attr.type="string"/>
+  <key id="e2" for="edge" attr.name="family" attr.type="string"/>
+  <key id="e3" for="edge" attr.name="Number of Children" attr.type="string"/>
+  <graph id="G" edgedefault="directed">
+    <node id="_i1">
+      <data key="n0">I0001</data>
+      <data key="n1">Lewis Garner</data>
+      <data key="n2">male</data>
+      <data key="n3">1855-06-21</data>
+      <data key="n5">Miller</data>
+    </node>
+    <node id="_i2">
+      <data key="n0">I0002</data>
+      <data key="n1">Anna Zieliński</data>
+      <data key="n2">female</data>
+    </node>
+    <node id="_i3">
+      <data key="n0">I0003</data>
+      <data key="n1">Eliza Garner</data>
+      <data key="n2">female</data>
+      <data key="n6">Lizzie &amp; Liz</data>
+    </node>
+    <node id="_i4">
+      <data key="n0">I0004</data>
+      <data key="n1">Arthur Hall</data>
+      <data key="n2">male</data>
+    </node>
+    <edge id="e0" source="_i1" target="_i4">
+      <data key="e0">association</data>
+      <data key="e1">Godfather</data>
+    </edge>
+    <edge id="e1" source="_i1" target="_i2">
+      <data key="e0">spouse</data>
+      <data key="e1">Married</data>
+      <data key="e2">F0001</data>
+      <data key="e3">1</data>
+    </edge>
+    <edge id="e2" source="_i1" target="_i3">
+      <data key="e0">parent</data>
+      <data key="e1">Birth</data>
+      <data key="e2">F0001</data>
+    </edge>
+    <edge id="e3" source="_i2" target="_i3">
+      <data key="e0">parent</data>
+      <data key="e1">Stepchild</data>
+      <data key="e2">F0001</data>
+    </edge>
+  </graph>
+</graphml>
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteEdgeListCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteEdgeListCSV(&buf, &networkSample, NetworkOptions{GraphOptions: GraphOptions{Root: "_i3", Ancestors: true}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `Source,Target,Type,Kind,Label,Family,Number of Children
+_i1,_i2,Directed,spouse,Married,F0001,1
+_i1,_i3,Directed,parent,Birth,F0001,
+_i2,_i3,Directed,parent,Stepchild,F0001,
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteNodeListCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteNodeListCSV(&buf, &networkSample, NetworkOptions{GraphOptions: GraphOptions{Root: "_i1"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `Id,Gramps ID,Label,Gender,Birth,Death,Occupation
+_i1,I0001,Lewis Garner,male,1855-06-21,,Miller
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteGraphMLFamilyNodes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteGraphML(&buf, &networkSample, NetworkOptions{GraphOptions: GraphOptions{Root: "_i3", Ancestors: true}, FamilyNodes: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="n0" for="node" attr.name="gramps_id" attr.type="string"/>
+  <key id="n1" for="node" attr.name="label" attr.type="string"/>
+  <key id="n2" for="node" attr.name="gender" attr.type="string"/>
+  <key id="n3" for="node" attr.name="birth" attr.type="string"/>
+  <key id="n4" for="node" attr.name="death" attr.type="string"/>
+  <key id="n5" for="node" attr.name="kind" attr.type="string"/>
+  <key id="n6" for="node" attr.name="Occupation" attr.type="string"/>
+  <key id="n7" for="node" attr.name="Nickname" attr.type="string"/>
+  <key id="n8" for="node" attr.name="Number of Children" attr.type="string"/>
+  <key id="e0" for="edge" attr.name="kind" attr.type="string"/>
+  <key id="e1" for="edge" attr.name="label" attr.type="string"/>
+  <key id="e2" for="edge" attr.name="family" attr.type="string"/>
+  <graph id="G" edgedefault="directed">
+    <node id="_i1">
+      <data key="n0">I0001</data>
+      <data key="n1">Lewis Garner</data>
+      <data key="n2">male</data>
+      <data key="n3">1855-06-21</data>
+      <data key="n5">person</data>
+      <data key="n6">Miller</data>
+    </node>
+    <node id="_i2">
+      <data key="n0">I0002</data>
+      <data key="n1">Anna Zieliński</data>
+      <data key="n2">female</data>
+      <data key="n5">person</data>
+    </node>
+    <node id="_i3">
+      <data key="n0">I0003</data>
+      <data key="n1">Eliza Garner</data>
+      <data key="n2">female</data>
+      <data key="n5">person</data>
+      <data key="n7">Lizzie &amp; Liz</data>
+    </node>
+    <node id="_f1">
+      <data key="n0">F0001</data>
+      <data key="n1">Lewis Garner &amp; Anna Zieliński</data>
+      <data key="n5">family</data>
+      <data key="n8">1</data>
+    </node>
+    <edge id="e0" source="_i1" target="_f1">
+      <data key="e0">spouse</data>
+      <data key="e1">Married</data>
+      <data key="e2">F0001</data>
+    </edge>
+    <edge id="e1" source="_i2" target="_f1">
+      <data key="e0">spouse</data>
+      <data key="e1">Married</data>
+      <data key="e2">F0001</data>
+    </edge>
+    <edge id="e2" source="_f1" target="_i3">
+      <data key="e0">parent</data>
+      <data key="e1">Birth/Stepchild</data>
+      <data key="e2">F0001</data>
+    </edge>
+  </graph>
+</graphml>
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteNodeListCSVFamilyNodes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteNodeListCSV(&buf, &networkSample, NetworkOptions{GraphOptions: GraphOptions{Root: "_i1", Descendants: true}, FamilyNodes: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `Id,Gramps ID,Label,Gender,Birth,Death,Kind,Occupation,Nickname,Number of Children
+_i1,I0001,Lewis Garner,male,1855-06-21,,person,Miller,,
+_i2,I0002,Anna Zieliński,female,,,person,,,
+_i3,I0003,Eliza Garner,female,,,person,,Lizzie & Liz,
+_f1,F0001,Lewis Garner & Anna Zieliński,,,,family,,,1
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
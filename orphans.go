@@ -0,0 +1,73 @@
+package grampsxml
+
+// defaultOrphanTypes are the types of object that [FindOrphans] looks for
+// by default. People and families are meaningful without being referred
+// to, so they are not included.
+var defaultOrphanTypes = []string{
+	targetEvent,
+	targetPlace,
+	targetSource,
+	targetCitation,
+	targetMedia,
+	targetRepository,
+	targetNote,
+}
+
+// OrphanOptions controls [FindOrphans].
+type OrphanOptions struct {
+	// Types lists the types of object, named as in [ChangedObject], to look
+	// for. Nil means events, places, sources, citations, media objects,
+	// repositories and notes.
+	Types []string
+
+	// Prune removes the orphans from the database, and then any objects
+	// that were referred to only by them, until no orphans are left.
+	Prune bool
+}
+
+// FindOrphans returns the objects in db that nothing refers to, in the
+// order they appear in db. Bookmarks and the home and default people count
+// as references, but references from an object to itself do not. With
+// opts.Prune the orphans are removed and the result lists every object
+// removed.
+func FindOrphans(db *Database, opts OrphanOptions) []ObjectRef {
+	if opts.Types == nil {
+		opts.Types = defaultOrphanTypes
+	}
+	types := make(map[string]bool)
+	for _, t := range opts.Types {
+		types[t] = true
+	}
+
+	orphaned := make(map[string]bool)
+	for {
+		used := make(map[string]bool)
+		walkReferences(db, func(r reference) {
+			if r.owner != "" && (orphaned[r.ownerType+"\x00"+r.owner] || r.ownerType == r.target && r.owner == *r.hlink) {
+				return
+			}
+			used[r.target+"\x00"+*r.hlink] = true
+		})
+		n := len(orphaned)
+		walkObjects(db, func(o primaryObject) {
+			key := o.objType + "\x00" + o.handle
+			if types[o.objType] && !used[key] {
+				orphaned[key] = true
+			}
+		})
+		if !opts.Prune || len(orphaned) == n {
+			break
+		}
+	}
+
+	var orphans []ObjectRef
+	walkObjects(db, func(o primaryObject) {
+		if orphaned[o.objType+"\x00"+o.handle] {
+			orphans = append(orphans, objectRef(o))
+		}
+	})
+	if opts.Prune && len(orphans) > 0 {
+		removeObjects(db, func(objType, handle string) bool { return orphaned[objType+"\x00"+handle] })
+	}
+	return orphans
+}
@@ -0,0 +1,81 @@
+package grampsxml
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func orphanSample() *Database {
+	return &Database{
+		Events: &Events{Event: []Event{
+			{Handle: "_e1", Citationref: []Citationref{{Hlink: "_c1"}}},
+			{Handle: "_e2", Place: &Place{Hlink: "_p2"}, Citationref: []Citationref{{Hlink: "_c2"}}},
+		}},
+		People: &People{
+			Default: new("_i1"),
+			Person: []Person{
+				{Handle: "_i1", Eventref: []Eventref{{Hlink: "_e1"}}, Objref: []Objref{{Hlink: "_o1"}}},
+				{Handle: "_i2"},
+			},
+		},
+		Citations: &Citations{Citation: []Citation{
+			{Handle: "_c1", Sourceref: &Sourceref{Hlink: "_s1"}},
+			{Handle: "_c2", Sourceref: &Sourceref{Hlink: "_s2"}},
+		}},
+		Sources: &Sources{Source: []Source{
+			{Handle: "_s1", Reporef: []Reporef{{Hlink: "_r1"}}},
+			{Handle: "_s2", Reporef: []Reporef{{Hlink: "_r1"}}},
+		}},
+		Places: &Places{Place: []Placeobj{
+			{Handle: "_p1", Placeref: []Placeref{{Hlink: "_p1"}}},
+			{Handle: "_p2"},
+		}},
+		Objects:      &Objects{Object: []Object{{Handle: "_o1"}}},
+		Repositories: &Repositories{Repository: []Repository{{Handle: "_r1"}}},
+		Notes:        &Notes{Note: []Note{{Handle: "_n1"}, {Handle: "_n2"}}},
+		Bookmarks:    &Bookmarks{Bookmark: []Bookmark{{Target: "note", Hlink: "_n2"}}},
+	}
+}
+
+func TestFindOrphans(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     OrphanOptions
+		want     []string
+		wantLeft []string
+	}{
+		{
+			name:     "default",
+			want:     []string{"_e2", "_p1", "_n1"},
+			wantLeft: []string{"_e1", "_e2", "_i1", "_i2", "_c1", "_c2", "_s1", "_s2", "_p1", "_p2", "_o1", "_r1", "_n1", "_n2"},
+		},
+		{
+			name:     "prune",
+			opts:     OrphanOptions{Prune: true},
+			want:     []string{"_e2", "_c2", "_s2", "_p1", "_p2", "_n1"},
+			wantLeft: []string{"_e1", "_i1", "_i2", "_c1", "_s1", "_o1", "_r1", "_n2"},
+		},
+		{
+			name:     "types",
+			opts:     OrphanOptions{Types: []string{"person", "note"}, Prune: true},
+			want:     []string{"_i2", "_n1"},
+			wantLeft: []string{"_e1", "_e2", "_i1", "_c1", "_c2", "_s1", "_s2", "_p1", "_p2", "_o1", "_r1", "_n2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := orphanSample()
+			var got []string
+			for _, o := range FindOrphans(db, tc.opts) {
+				got = append(got, o.Handle)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("orphans mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantLeft, objectHandles(db)); diff != "" {
+				t.Errorf("remaining mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,604 @@
+package grampsxml
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PersonMatch is a pair of people who may be the same person, as found by
+// [FindDuplicatePeople].
+type PersonMatch struct {
+	// A and B are the handles of the two people, in the order they appear
+	// in the database.
+	A, B string
+
+	// Score is the overall likelihood that the people are the same, between
+	// 0 and 1.
+	Score float64
+
+	// Breakdown lists the parts of the score, in the order they were
+	// compared. Parts that could not be compared because either person
+	// lacks the information are left out.
+	Breakdown []MatchFactor
+}
+
+// MatchFactor is one of the parts of the score of a [PersonMatch].
+type MatchFactor struct {
+	// Factor names what was compared: "surname", "given name", "gender",
+	// "birth date", "death date", "birth place", "death place", "parents" or
+	// "spouses".
+	Factor string
+
+	// Score is how well the two people agree, between 0 and 1.
+	Score float64
+
+	// Weight is the weight given to the factor in the overall score.
+	Weight float64
+
+	// Reason explains the score, such as `surnames "smith" and "smyth"
+	// sound alike`.
+	Reason string
+}
+
+// PersonMatchOptions controls the pairs of people reported by
+// [FindDuplicatePeople].
+type PersonMatchOptions struct {
+	// MinScore is the lowest score of the pairs reported. Zero means 0.8.
+	MinScore float64
+}
+
+// Weights of the factors of a person match score.
+const (
+	personSurnameWeight    = 0.25
+	personGivenWeight      = 0.2
+	personGenderWeight     = 0.05
+	personBirthDateWeight  = 0.15
+	personDeathDateWeight  = 0.1
+	personBirthPlaceWeight = 0.05
+	personDeathPlaceWeight = 0.05
+	personParentsWeight    = 0.1
+	personSpousesWeight    = 0.1
+)
+
+// FindDuplicatePeople returns the pairs of people in db who may be the same
+// person, ordered by decreasing score, in the manner of Gramps' Find
+// Possible Duplicate People tool.
+//
+// Surnames and given names are compared by spelling and by their Soundex
+// codes, so that "Smith" and "Smyth" match, using every name of each person.
+// Birth and death dates are compared by the ranges of days they cover, and
+// birth and death places by handle or by name, using the birth and death
+// events of the people or, failing those, their baptisms and burials. People
+// who share a parent or spouse score higher, and those with different
+// parents or spouses score lower. People of different known genders are
+// never paired. Factors that cannot be compared because either person lacks
+// the information are left out of the score.
+//
+// To avoid comparing every person with every other, which is impractical
+// for large databases, only people whose surnames have the same Soundex code
+// and whose given names start with the same letter, or whose given names
+// have the same Soundex code and who were born in the same year, are
+// compared.
+func FindDuplicatePeople(db *Database, opts PersonMatchOptions) []PersonMatch {
+	if opts.MinScore == 0 {
+		opts.MinScore = 0.8
+	}
+	ix := NewIndex(db)
+	people := db.People.list()
+	cands := make([]*personCandidate, len(people))
+	blocks := make(map[string][]int)
+	for i := range people {
+		c := newPersonCandidate(ix, &people[i])
+		cands[i] = c
+		for _, key := range c.blockKeys() {
+			if b := blocks[key]; len(b) == 0 || b[len(b)-1] != i {
+				blocks[key] = append(b, i)
+			}
+		}
+	}
+
+	var matches []PersonMatch
+	for i, c := range cands {
+		seen := make(map[int]bool)
+		for _, key := range c.blockKeys() {
+			for _, j := range blocks[key] {
+				if j <= i || seen[j] {
+					continue
+				}
+				seen[j] = true
+				if m, ok := c.match(cands[j]); ok && m.Score >= opts.MinScore {
+					matches = append(matches, m)
+				}
+			}
+		}
+	}
+
+	order := make(map[string]int, len(people))
+	for i := range people {
+		order[people[i].Handle] = i
+	}
+	slices.SortStableFunc(matches, func(a, b PersonMatch) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(order[a.A], order[b.A]),
+			cmp.Compare(order[a.B], order[b.B]),
+		)
+	})
+	return matches
+}
+
+// personCandidate holds the parts of a person that are compared when
+// looking for duplicates.
+type personCandidate struct {
+	handle     string
+	gender     string
+	surnames   []string // normalised, from every name
+	given      []string // first given name of every name, normalised
+	birth      Date
+	death      Date
+	birthPlace personPlace
+	deathPlace personPlace
+	families   []string // families the person is a child of
+	parents    []string
+	spouses    []string
+}
+
+// personPlace identifies the place of an event by handle and by name.
+type personPlace struct {
+	handle string
+	name   string
+}
+
+func newPersonCandidate(ix *Index, p *Person) *personCandidate {
+	c := &personCandidate{handle: p.Handle, gender: p.Gender}
+	for i := range p.Name {
+		n := &p.Name[i]
+		for _, s := range n.Surname {
+			if v := normalizeText(s.Surname); v != "" && !slices.Contains(c.surnames, v) {
+				c.surnames = append(c.surnames, v)
+			}
+		}
+		if f := strings.Fields(normalizeText(strval(n.First))); len(f) > 0 && !slices.Contains(c.given, f[0]) {
+			c.given = append(c.given, f[0])
+		}
+	}
+
+	event := func(types ...string) *Event {
+		for _, t := range types {
+			if ev := personEvent(ix, p, t); ev != nil {
+				return ev
+			}
+		}
+		return nil
+	}
+	place := func(ev *Event) personPlace {
+		if ev == nil || ev.Place == nil {
+			return personPlace{}
+		}
+		pp := personPlace{handle: ev.Place.Hlink}
+		if pl := ix.Place(ev.Place.Hlink); pl != nil {
+			pp.name = normalizeText(pl.NameAt(ev.Date(), ""))
+		}
+		return pp
+	}
+	if ev := event("Birth", "Baptism", "Christening"); ev != nil {
+		c.birth, c.birthPlace = ev.Date(), place(ev)
+	}
+	if ev := event("Death", "Burial", "Cremation"); ev != nil {
+		c.death, c.deathPlace = ev.Date(), place(ev)
+	}
+
+	for _, co := range p.Childof {
+		c.families = append(c.families, co.Hlink)
+		if f := ix.Family(co.Hlink); f != nil {
+			c.parents = append(c.parents, familyParents(f)...)
+		}
+	}
+	for _, pi := range p.Parentin {
+		if f := ix.Family(pi.Hlink); f != nil {
+			for _, h := range familyParents(f) {
+				if h != p.Handle {
+					c.spouses = append(c.spouses, h)
+				}
+			}
+		}
+	}
+	return c
+}
+
+// blockKeys returns the keys of the blocks of people the person is compared
+// with.
+func (c *personCandidate) blockKeys() []string {
+	var keys []string
+	for _, s := range c.surnames {
+		for _, g := range c.given {
+			keys = append(keys, "s:"+soundex(s)+":"+initial(g))
+		}
+		if len(c.given) == 0 {
+			keys = append(keys, "s:"+soundex(s)+":")
+		}
+	}
+	if lo, hi, ok := c.birth.bounds(); ok && lo != math.MinInt && hi != math.MaxInt && hi-lo < 366 {
+		y := strconv.Itoa(julianDayYear(lo))
+		for _, g := range c.given {
+			keys = append(keys, "g:"+soundex(g)+":"+y)
+		}
+	}
+	return keys
+}
+
+// match scores the pair of people c and d. It reports false if they have
+// different known genders.
+func (c *personCandidate) match(d *personCandidate) (PersonMatch, bool) {
+	m := PersonMatch{A: c.handle, B: d.handle}
+	known := func(g string) bool { return g == "M" || g == "F" }
+	if known(c.gender) && known(d.gender) && c.gender != d.gender {
+		return m, false
+	}
+
+	add := func(factor string, weight, score float64, reason string) {
+		m.Breakdown = append(m.Breakdown, MatchFactor{Factor: factor, Score: score, Weight: weight, Reason: reason})
+	}
+	if f, ok := compareNames("surname", c.surnames, d.surnames, false); ok {
+		add("surname", personSurnameWeight, f.Score, f.Reason)
+	}
+	if f, ok := compareNames("given name", c.given, d.given, true); ok {
+		add("given name", personGivenWeight, f.Score, f.Reason)
+	}
+	if known(c.gender) && c.gender == d.gender {
+		add("gender", personGenderWeight, 1, "same gender")
+	}
+	if score, reason, ok := compareDates(c.birth, d.birth); ok {
+		add("birth date", personBirthDateWeight, score, "birth "+reason)
+	}
+	if score, reason, ok := compareDates(c.death, d.death); ok {
+		add("death date", personDeathDateWeight, score, "death "+reason)
+	}
+	if score, reason, ok := comparePlaces(c.birthPlace, d.birthPlace); ok {
+		add("birth place", personBirthPlaceWeight, score, "birth "+reason)
+	}
+	if score, reason, ok := comparePlaces(c.deathPlace, d.deathPlace); ok {
+		add("death place", personDeathPlaceWeight, score, "death "+reason)
+	}
+	if len(c.families) > 0 && len(d.families) > 0 {
+		switch {
+		case sharesAny(c.families, d.families) || sharesAny(c.parents, d.parents):
+			add("parents", personParentsWeight, 1, "share a parent")
+		default:
+			add("parents", personParentsWeight, 0, "have different parents")
+		}
+	}
+	if len(c.spouses) > 0 && len(d.spouses) > 0 {
+		switch {
+		case sharesAny(c.spouses, d.spouses):
+			add("spouses", personSpousesWeight, 1, "share a spouse")
+		default:
+			add("spouses", personSpousesWeight, 0, "have different spouses")
+		}
+	}
+
+	var score, weight float64
+	for _, f := range m.Breakdown {
+		score += f.Score * f.Weight
+		weight += f.Weight
+	}
+	if weight > 0 {
+		m.Score = score / weight
+	}
+	return m, true
+}
+
+// compareNames returns the best match between any of the names in a and
+// any in b. With initials, a name that is a single letter matches any name
+// starting with that letter.
+func compareNames(kind string, a, b []string, initials bool) (MatchFactor, bool) {
+	if len(a) == 0 || len(b) == 0 {
+		return MatchFactor{}, false
+	}
+	best := MatchFactor{Reason: fmt.Sprintf("%ss %q and %q differ", kind, a[0], b[0])}
+	for _, x := range a {
+		for _, y := range b {
+			var f MatchFactor
+			switch {
+			case x == y:
+				f = MatchFactor{Score: 1, Reason: fmt.Sprintf("same %s %q", kind, x)}
+			case soundex(x) == soundex(y):
+				f = MatchFactor{Score: 0.8, Reason: fmt.Sprintf("%ss %q and %q sound alike", kind, x, y)}
+			case initials && (x == initial(x) || y == initial(y)) && initial(x) == initial(y):
+				f = MatchFactor{Score: 0.5, Reason: fmt.Sprintf("%ss %q and %q have the same initial", kind, x, y)}
+			default:
+				continue
+			}
+			if f.Score > best.Score {
+				best = f
+			}
+		}
+	}
+	return best, true
+}
+
+// compareDates scores how well two dates agree. Dates covering the same
+// days score 1, overlapping dates 0.8, and dates less than two years apart
+// score up to 0.5 depending on how close they are.
+func compareDates(a, b Date) (float64, string, bool) {
+	alo, ahi, aok := a.bounds()
+	blo, bhi, bok := b.bounds()
+	if !aok || !bok {
+		return 0, "", false
+	}
+	as, bs := a.String(), b.String()
+	switch {
+	case alo == blo && ahi == bhi:
+		return 1, fmt.Sprintf("dates %s and %s agree", as, bs), true
+	case a.overlaps(b):
+		return 0.8, fmt.Sprintf("dates %s and %s overlap", as, bs), true
+	}
+	gap := float64(max(alo, blo) - min(ahi, bhi))
+	const limit = 2 * 365
+	if gap < limit {
+		return 0.5 * (1 - gap/limit), fmt.Sprintf("dates %s and %s are close", as, bs), true
+	}
+	return 0, fmt.Sprintf("dates %s and %s are far apart", as, bs), true
+}
+
+// comparePlaces scores how well two places agree. The same place scores 1
+// and places with the same name 0.8.
+func comparePlaces(a, b personPlace) (float64, string, bool) {
+	if a.handle == "" || b.handle == "" {
+		return 0, "", false
+	}
+	switch {
+	case a.handle == b.handle:
+		return 1, "places are the same", true
+	case a.name != "" && a.name == b.name:
+		return 0.8, fmt.Sprintf("places have the same name %q", a.name), true
+	}
+	return 0, "places differ", true
+}
+
+// initial returns the first letter of s.
+func initial(s string) string {
+	for _, r := range s {
+		return string(r)
+	}
+	return ""
+}
+
+func sharesAny(a, b []string) bool {
+	for _, s := range a {
+		if slices.Contains(b, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// julianDayYear returns the year of the Gregorian calendar in which the day
+// with Julian day number jd falls.
+func julianDayYear(jd int) int {
+	a := jd + 32044
+	b := (4*a + 3) / 146097
+	c := a - 146097*b/4
+	d := (4*c + 3) / 1461
+	e := c - 1461*d/4
+	m := (5*e + 2) / 153
+	return 100*b + d - 4800 + m/10
+}
+
+// MergePeople merges the person with the handle duplicate into the person
+// with the handle survivor, as Gramps does when merging people. The names of
+// the duplicate are added to the survivor as alternate names, and its event
+// references, LDS ordinances, attributes, URLs, addresses, media, notes,
+// citations, tags, families and associations are added to those of the
+// survivor, leaving out any the survivor already has. The survivor keeps its
+// own gender unless it is unknown, and is private if either person was.
+//
+// Every reference to the duplicate, including those from families,
+// associations, DNA tests, bookmarks and the home and default people, is
+// rewritten to refer to the survivor, and the duplicate is removed. Families
+// of the duplicate that have the same parents as another family of the
+// survivor once merged are merged into that family, in the same way. The
+// change time of the survivor and of every object whose references were
+// rewritten is set to the current time.
+//
+// People who are spouses in the same family, or parent and child, cannot be
+// merged.
+func MergePeople(db *Database, survivor, duplicate string) error {
+	if survivor == duplicate {
+		return fmt.Errorf("grampsxml: cannot merge person %s with itself", survivor)
+	}
+	people := db.People.list()
+	si := slices.IndexFunc(people, func(p Person) bool { return p.Handle == survivor })
+	if si < 0 {
+		return fmt.Errorf("grampsxml: person %s not found", survivor)
+	}
+	di := slices.IndexFunc(people, func(p Person) bool { return p.Handle == duplicate })
+	if di < 0 {
+		return fmt.Errorf("grampsxml: person %s not found", duplicate)
+	}
+
+	keep, dup := &people[si], people[di]
+	keepParentin, dupParentin := parentinLinks(keep), parentinLinks(&dup)
+	keepChildof, dupChildof := childofLinks(keep), childofLinks(&dup)
+	if sharesAny(keepParentin, dupParentin) {
+		return fmt.Errorf("grampsxml: cannot merge %s with %s as they are spouses", survivor, duplicate)
+	}
+	if sharesAny(slices.Concat(keepParentin, dupParentin), slices.Concat(keepChildof, dupChildof)) {
+		return fmt.Errorf("grampsxml: cannot merge %s with %s as one is a parent of the other", survivor, duplicate)
+	}
+
+	if dup.Priv != nil && *dup.Priv {
+		keep.Priv = dup.Priv
+	}
+	if keep.Gender == "" || keep.Gender == "U" {
+		keep.Gender = dup.Gender
+	}
+	for _, n := range dup.Name {
+		n.Alt = new(true)
+		keep.Name = mergeList(keep.Name, []Name{n}, func(n Name) string {
+			n.Alt = nil
+			return valueKey(n)
+		})
+	}
+	keep.Eventref = mergeEventrefs(keep.Eventref, dup.Eventref)
+	keep.LdsOrd = mergeList(keep.LdsOrd, dup.LdsOrd, valueKey)
+	keep.Objref = mergeList(keep.Objref, dup.Objref, func(r Objref) string { return r.Hlink })
+	keep.Address = mergeList(keep.Address, dup.Address, valueKey)
+	keep.Attribute = mergeList(keep.Attribute, dup.Attribute, attributeKey)
+	keep.Url = mergeList(keep.Url, dup.Url, func(u Url) string { return u.Href })
+	keep.Childof = mergeList(keep.Childof, dup.Childof, func(r Childof) string { return r.Hlink })
+	keep.Parentin = mergeList(keep.Parentin, dup.Parentin, func(r Parentin) string { return r.Hlink })
+	keep.Personref = mergeList(keep.Personref, dup.Personref, func(r Personref) string { return r.Hlink + "\x00" + r.Rel })
+	keep.Noteref = mergeList(keep.Noteref, dup.Noteref, func(r Noteref) string { return r.Hlink })
+	keep.Citationref = mergeList(keep.Citationref, dup.Citationref, func(r Citationref) string { return r.Hlink })
+	keep.Tagref = mergeList(keep.Tagref, dup.Tagref, func(r Tagref) string { return r.Hlink })
+	db.People.Person = slices.Delete(people, di, di+1)
+
+	changed := make(map[string]bool)
+	changed[targetPerson+"\x00"+survivor] = true
+	walkReferences(db, func(r reference) {
+		if r.target == targetPerson && *r.hlink == duplicate {
+			*r.hlink = survivor
+			changed[r.ownerType+"\x00"+r.owner] = true
+		}
+	})
+
+	ix := NewIndex(db)
+	keep = ix.Person(survivor)
+	keep.Personref = slices.DeleteFunc(keep.Personref, func(r Personref) bool { return r.Hlink == survivor })
+
+	// Families in which both people were children now list the survivor
+	// twice.
+	for _, h := range childofLinks(keep) {
+		if f := ix.Family(h); f != nil {
+			f.Childref = mergeList(nil, f.Childref, func(r Childref) string { return r.Hlink })
+		}
+	}
+
+	// Families of the duplicate may now have the same parents as another
+	// family of the survivor.
+	var merges [][2]string
+	first := make(map[[2]string]string)
+	for _, h := range parentinLinks(keep) {
+		f := ix.Family(h)
+		if f == nil {
+			continue
+		}
+		var parents [2]string
+		if f.Father != nil {
+			parents[0] = f.Father.Hlink
+		}
+		if f.Mother != nil {
+			parents[1] = f.Mother.Hlink
+		}
+		if other, ok := first[parents]; ok && slices.Contains(dupParentin, h) {
+			merges = append(merges, [2]string{other, h})
+			changed[targetFamily+"\x00"+other] = true
+			continue
+		}
+		first[parents] = h
+	}
+	for _, m := range merges {
+		mergeFamilies(db, m[0], m[1], changed)
+	}
+
+	stamp := changeStamp(time.Now())
+	walkObjects(db, func(o primaryObject) {
+		if changed[o.objType+"\x00"+o.handle] {
+			*o.change = stamp
+		}
+	})
+	return nil
+}
+
+// mergeFamilies merges the family with the handle duplicate, which must
+// have the same parents, into the family with the handle survivor, as
+// Gramps does when merging families. The keys of the objects whose
+// references were rewritten are added to changed.
+func mergeFamilies(db *Database, survivor, duplicate string, changed map[string]bool) {
+	families := db.Families.list()
+	si := slices.IndexFunc(families, func(f Family) bool { return f.Handle == survivor })
+	di := slices.IndexFunc(families, func(f Family) bool { return f.Handle == duplicate })
+	if si < 0 || di < 0 {
+		return
+	}
+	keep, dup := &families[si], families[di]
+	if dup.Priv != nil && *dup.Priv {
+		keep.Priv = dup.Priv
+	}
+	if (keep.Rel == nil || keep.Rel.Type == "Unknown") && dup.Rel != nil {
+		keep.Rel = dup.Rel
+	}
+	keep.Eventref = mergeEventrefs(keep.Eventref, dup.Eventref)
+	keep.LdsOrd = mergeList(keep.LdsOrd, dup.LdsOrd, valueKey)
+	keep.Objref = mergeList(keep.Objref, dup.Objref, func(r Objref) string { return r.Hlink })
+	keep.Childref = mergeList(keep.Childref, dup.Childref, func(r Childref) string { return r.Hlink })
+	keep.Attribute = mergeList(keep.Attribute, dup.Attribute, attributeKey)
+	keep.Noteref = mergeList(keep.Noteref, dup.Noteref, func(r Noteref) string { return r.Hlink })
+	keep.Citationref = mergeList(keep.Citationref, dup.Citationref, func(r Citationref) string { return r.Hlink })
+	keep.Tagref = mergeList(keep.Tagref, dup.Tagref, func(r Tagref) string { return r.Hlink })
+	db.Families.Family = slices.Delete(families, di, di+1)
+
+	walkReferences(db, func(r reference) {
+		if r.target == targetFamily && *r.hlink == duplicate {
+			*r.hlink = survivor
+			changed[r.ownerType+"\x00"+r.owner] = true
+		}
+	})
+	people := db.People.list()
+	for i := range people {
+		p := &people[i]
+		p.Childof = mergeList(nil, p.Childof, func(r Childof) string { return r.Hlink })
+		p.Parentin = mergeList(nil, p.Parentin, func(r Parentin) string { return r.Hlink })
+	}
+}
+
+// mergeEventrefs returns dst with the event references in src added. A
+// reference to an event that dst already refers to in the same role is
+// merged with that reference instead.
+func mergeEventrefs(dst, src []Eventref) []Eventref {
+	for _, r := range src {
+		i := slices.IndexFunc(dst, func(d Eventref) bool {
+			return d.Hlink == r.Hlink && strval(d.Role) == strval(r.Role)
+		})
+		if i < 0 {
+			dst = append(dst, r)
+			continue
+		}
+		dst[i].Attribute = mergeList(dst[i].Attribute, r.Attribute, attributeKey)
+		dst[i].Noteref = mergeList(dst[i].Noteref, r.Noteref, func(r Noteref) string { return r.Hlink })
+	}
+	return dst
+}
+
+func attributeKey(a Attribute) string {
+	return a.Type + "\x00" + a.Value
+}
+
+func childofLinks(p *Person) []string {
+	hs := make([]string, len(p.Childof))
+	for i, r := range p.Childof {
+		hs[i] = r.Hlink
+	}
+	return hs
+}
+
+func parentinLinks(p *Person) []string {
+	hs := make([]string, len(p.Parentin))
+	for i, r := range p.Parentin {
+		hs[i] = r.Hlink
+	}
+	return hs
+}
+
+// valueKey returns a key for v that is the same for equal values.
+func valueKey[T any](v T) string {
+	b, _ := xml.Marshal(v)
+	return string(b)
+}
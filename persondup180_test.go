@@ -0,0 +1,20 @@
+//go:build gramps_schema180
+
+package grampsxml
+
+import "testing"
+
+func TestMergePeopleDNATests(t *testing.T) {
+	db := personMergeSample()
+	db.DNATests = &DNATests{DNATest: []DNATest{{Handle: "_d1", Person: &PersonLink{Hlink: "_i2"}}}}
+	if err := MergePeople(db, "_i1", "_i2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d := db.DNATests.DNATest[0]
+	if got := d.Person.Hlink; got != "_i1" {
+		t.Errorf("dna test person: got %s, want _i1", got)
+	}
+	if d.Change == "" {
+		t.Errorf("change time of dna test was not set")
+	}
+}
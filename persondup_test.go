@@ -0,0 +1,287 @@
+package grampsxml
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+var personDupSample = Database{
+	Events: &Events{
+		Event: []Event{
+			{Handle: "_e1", Type: new("Birth"), Dateval: &Dateval{Val: "1850-03-01"}, Place: &Place{Hlink: "_p1"}},
+			{Handle: "_e2", Type: new("Baptism"), Dateval: &Dateval{Val: "1850"}, Place: &Place{Hlink: "_p1"}},
+			{Handle: "_e3", Type: new("Birth"), Dateval: &Dateval{Val: "1900"}},
+			{Handle: "_e4", Type: new("Birth"), Dateval: &Dateval{Val: "1850-03-01"}},
+		},
+	},
+	People: &People{
+		Person: []Person{
+			{
+				Handle: "_i1", Gender: "M",
+				Name:     []Name{{First: new("John"), Surname: []Surname{{Surname: "Smith"}}}},
+				Eventref: []Eventref{{Hlink: "_e1"}},
+				Childof:  []Childof{{Hlink: "_f1"}},
+			},
+			{
+				Handle: "_i2", Gender: "M",
+				Name:     []Name{{First: new("Jon"), Surname: []Surname{{Surname: "Smyth"}}}},
+				Eventref: []Eventref{{Hlink: "_e2"}},
+				Childof:  []Childof{{Hlink: "_f1"}},
+			},
+			{
+				Handle: "_i3", Gender: "F",
+				Name: []Name{{First: new("John"), Surname: []Surname{{Surname: "Smith"}}}},
+			},
+			{
+				Handle: "_i4", Gender: "M",
+				Name:     []Name{{First: new("John"), Surname: []Surname{{Surname: "Smith"}}}},
+				Eventref: []Eventref{{Hlink: "_e3"}},
+			},
+			{
+				Handle: "_i5", Gender: "U",
+				Name:     []Name{{First: new("Jonathan"), Surname: []Surname{{Surname: "Brown"}}}, {Alt: new(true), First: new("J."), Surname: []Surname{{Surname: "Smith"}}}},
+				Eventref: []Eventref{{Hlink: "_e4"}},
+			},
+			{Handle: "_i6", Gender: "M", Name: []Name{{First: new("Mary"), Surname: []Surname{{Surname: "Smith"}}}}},
+			{Handle: "_i7", Gender: "M", Name: []Name{{First: new("William"), Surname: []Surname{{Surname: "Jones"}}}}, Parentin: []Parentin{{Hlink: "_f1"}}},
+		},
+	},
+	Families: &Families{
+		Family: []Family{{Handle: "_f1", Father: &Father{Hlink: "_i7"}, Childref: []Childref{{Hlink: "_i1"}, {Hlink: "_i2"}}}},
+	},
+	Places: &Places{Place: []Placeobj{{Handle: "_p1", Pname: []Pname{{Value: "Leeds"}}}}},
+}
+
+func TestFindDuplicatePeople(t *testing.T) {
+	got := FindDuplicatePeople(&personDupSample, PersonMatchOptions{})
+	want := []PersonMatch{
+		{
+			A: "_i1", B: "_i2", Score: 0.85,
+			Breakdown: []MatchFactor{
+				{Factor: "surname", Score: 0.8, Weight: 0.25, Reason: `surnames "smith" and "smyth" sound alike`},
+				{Factor: "given name", Score: 0.8, Weight: 0.2, Reason: `given names "john" and "jon" sound alike`},
+				{Factor: "gender", Score: 1, Weight: 0.05, Reason: "same gender"},
+				{Factor: "birth date", Score: 0.8, Weight: 0.15, Reason: "birth dates 1850-03-01 and 1850 overlap"},
+				{Factor: "birth place", Score: 1, Weight: 0.05, Reason: "birth places are the same"},
+				{Factor: "parents", Score: 1, Weight: 0.1, Reason: "share a parent"},
+			},
+		},
+		{
+			A: "_i1", B: "_i5", Score: 0.8333,
+			Breakdown: []MatchFactor{
+				{Factor: "surname", Score: 1, Weight: 0.25, Reason: `same surname "smith"`},
+				{Factor: "given name", Score: 0.5, Weight: 0.2, Reason: `given names "john" and "j" have the same initial`},
+				{Factor: "birth date", Score: 1, Weight: 0.15, Reason: "birth dates 1850-03-01 and 1850-03-01 agree"},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.EquateApprox(0, 0.0001)); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPersonCandidateBlockKeys(t *testing.T) {
+	testCases := []struct {
+		birth string
+		want  []string
+	}{
+		{"", []string{"s:S530:J"}},
+		{"1850-03-01", []string{"s:S530:J", "g:J500:1850"}},
+		{"1850", []string{"s:S530:J", "g:J500:1850"}},
+		{"before 1900", []string{"s:S530:J"}},
+		{"after 1900", []string{"s:S530:J"}},
+		{"between 1850 and 1860", []string{"s:S530:J"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.birth, func(t *testing.T) {
+			c := &personCandidate{surnames: []string{"Smith"}, given: []string{"John"}, birth: ParseDate(tc.birth)}
+			if diff := cmp.Diff(tc.want, c.blockKeys()); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFindDuplicatePeopleMinScore(t *testing.T) {
+	got := FindDuplicatePeople(&personDupSample, PersonMatchOptions{MinScore: 0.01})
+	var pairs [][2]string
+	for _, m := range got {
+		pairs = append(pairs, [2]string{m.A, m.B})
+	}
+	want := [][2]string{
+		{"_i1", "_i2"},
+		{"_i1", "_i5"},
+		{"_i3", "_i5"},
+		{"_i1", "_i4"},
+		{"_i2", "_i5"},
+		{"_i2", "_i4"},
+		{"_i4", "_i5"},
+	}
+	if diff := cmp.Diff(want, pairs); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func personMergeSample() *Database {
+	return &Database{
+		Events: &Events{
+			Event: []Event{
+				{Handle: "_e1", Type: new("Birth")},
+				{Handle: "_e2", Type: new("Death")},
+				{Handle: "_e3", Type: new("Marriage")},
+			},
+		},
+		People: &People{
+			Home: new("_i2"),
+			Person: []Person{
+				{
+					Handle: "_i1", Gender: "U",
+					Name:     []Name{{First: new("John"), Surname: []Surname{{Surname: "Smith"}}}},
+					Eventref: []Eventref{{Hlink: "_e1", Role: new("Primary")}},
+					Url:      []Url{{Href: "https://example.com/john"}},
+					Childof:  []Childof{{Hlink: "_f0"}},
+					Parentin: []Parentin{{Hlink: "_f1"}},
+				},
+				{
+					Handle: "_i2", Gender: "M", Priv: new(true),
+					Name: []Name{
+						{First: new("Jon"), Surname: []Surname{{Surname: "Smyth"}}},
+						{Alt: new(true), First: new("John"), Surname: []Surname{{Surname: "Smith"}}},
+					},
+					Eventref: []Eventref{
+						{Hlink: "_e1", Role: new("Primary"), Noteref: []Noteref{{Hlink: "_n1"}}},
+						{Hlink: "_e2", Role: new("Primary")},
+					},
+					Attribute: []Attribute{{Type: "Occupation", Value: "Miner"}},
+					Url:       []Url{{Href: "https://example.com/john"}},
+					Childof:   []Childof{{Hlink: "_f0"}},
+					Parentin:  []Parentin{{Hlink: "_f2"}},
+					Personref: []Personref{{Hlink: "_i1", Rel: "Godfather"}, {Hlink: "_i4", Rel: "Friend"}},
+				},
+				{Handle: "_i3", Gender: "F", Parentin: []Parentin{{Hlink: "_f1"}, {Hlink: "_f2"}}},
+				{Handle: "_i4", Gender: "F", Personref: []Personref{{Hlink: "_i2", Rel: "Friend"}}},
+				{Handle: "_i5", Gender: "F", Childof: []Childof{{Hlink: "_f2"}}},
+				{Handle: "_i6", Gender: "M", Parentin: []Parentin{{Hlink: "_f0"}}},
+			},
+		},
+		Families: &Families{
+			Family: []Family{
+				{Handle: "_f0", Father: &Father{Hlink: "_i6"}, Childref: []Childref{{Hlink: "_i1"}, {Hlink: "_i2"}}},
+				{Handle: "_f1", Father: &Father{Hlink: "_i1"}, Mother: &Mother{Hlink: "_i3"}},
+				{
+					Handle: "_f2", Rel: &Rel{Type: "Married"},
+					Father: &Father{Hlink: "_i2"}, Mother: &Mother{Hlink: "_i3"},
+					Eventref: []Eventref{{Hlink: "_e3", Role: new("Family")}},
+					Childref: []Childref{{Hlink: "_i5"}},
+				},
+			},
+		},
+		Bookmarks: &Bookmarks{
+			Bookmark: []Bookmark{{Target: "person", Hlink: "_i2"}, {Target: "family", Hlink: "_f2"}},
+		},
+	}
+}
+
+func TestMergePeople(t *testing.T) {
+	db := personMergeSample()
+	if err := MergePeople(db, "_i1", "_i2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ix := NewIndex(db)
+	if ix.Person("_i2") != nil {
+		t.Errorf("duplicate person was not removed")
+	}
+	if ix.Family("_f2") != nil {
+		t.Errorf("duplicate family was not removed")
+	}
+	wantPerson := Person{
+		Handle: "_i1", Gender: "M", Priv: new(true),
+		Name: []Name{
+			{First: new("John"), Surname: []Surname{{Surname: "Smith"}}},
+			{Alt: new(true), First: new("Jon"), Surname: []Surname{{Surname: "Smyth"}}},
+		},
+		Eventref: []Eventref{
+			{Hlink: "_e1", Role: new("Primary"), Noteref: []Noteref{{Hlink: "_n1"}}},
+			{Hlink: "_e2", Role: new("Primary")},
+		},
+		Attribute: []Attribute{{Type: "Occupation", Value: "Miner"}},
+		Url:       []Url{{Href: "https://example.com/john"}},
+		Childof:   []Childof{{Hlink: "_f0"}},
+		Parentin:  []Parentin{{Hlink: "_f1"}},
+		Personref: []Personref{{Hlink: "_i4", Rel: "Friend"}},
+	}
+	if diff := cmp.Diff(wantPerson, *ix.Person("_i1"), cmpopts.IgnoreFields(Person{}, "Change")); diff != "" {
+		t.Errorf("survivor mismatch (-want +got):\n%s", diff)
+	}
+	wantFamily := Family{
+		Handle: "_f1", Rel: &Rel{Type: "Married"},
+		Father: &Father{Hlink: "_i1"}, Mother: &Mother{Hlink: "_i3"},
+		Eventref: []Eventref{{Hlink: "_e3", Role: new("Family")}},
+		Childref: []Childref{{Hlink: "_i5"}},
+	}
+	if diff := cmp.Diff(wantFamily, *ix.Family("_f1"), cmpopts.IgnoreFields(Family{}, "Change")); diff != "" {
+		t.Errorf("merged family mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]Childref{{Hlink: "_i1"}}, ix.Family("_f0").Childref); diff != "" {
+		t.Errorf("parent family children mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]Parentin{{Hlink: "_f1"}}, ix.Person("_i3").Parentin); diff != "" {
+		t.Errorf("spouse families mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]Childof{{Hlink: "_f1"}}, ix.Person("_i5").Childof); diff != "" {
+		t.Errorf("child families mismatch (-want +got):\n%s", diff)
+	}
+	if got := ix.Person("_i4").Personref[0].Hlink; got != "_i1" {
+		t.Errorf("association: got %s, want _i1", got)
+	}
+	wantBookmarks := []Bookmark{{Target: "person", Hlink: "_i1"}, {Target: "family", Hlink: "_f1"}}
+	if diff := cmp.Diff(wantBookmarks, db.Bookmarks.Bookmark); diff != "" {
+		t.Errorf("bookmarks mismatch (-want +got):\n%s", diff)
+	}
+	if got := strval(db.People.Home); got != "_i1" {
+		t.Errorf("home person: got %s, want _i1", got)
+	}
+
+	for _, h := range []string{"_i1", "_i3", "_i4", "_i5"} {
+		if ix.Person(h).Change == "" {
+			t.Errorf("change time of %s was not set", h)
+		}
+	}
+	if ix.Person("_i6").Change != "" {
+		t.Errorf("change time of _i6 was set")
+	}
+	for _, h := range []string{"_f0", "_f1"} {
+		if ix.Family(h).Change == "" {
+			t.Errorf("change time of %s was not set", h)
+		}
+	}
+}
+
+func TestMergePeopleErrors(t *testing.T) {
+	testCases := []struct {
+		name                string
+		survivor, duplicate string
+	}{
+		{name: "same", survivor: "_i1", duplicate: "_i1"},
+		{name: "missing survivor", survivor: "_ix", duplicate: "_i1"},
+		{name: "missing duplicate", survivor: "_i1", duplicate: "_ix"},
+		{name: "spouses", survivor: "_i1", duplicate: "_i3"},
+		{name: "parent and child", survivor: "_i6", duplicate: "_i1"},
+		{name: "child and parent", survivor: "_i2", duplicate: "_i5"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := personMergeSample()
+			if err := MergePeople(db, tc.survivor, tc.duplicate); err == nil {
+				t.Errorf("expected error")
+			}
+			if diff := cmp.Diff(personMergeSample(), db); diff != "" {
+				t.Errorf("database was modified (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package grampsxml
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PlaceFormat controls the place titles generated by [Index.PlaceTitle]. It
+// corresponds to the place format options offered by Gramps.
+type PlaceFormat struct {
+	// Language is the preferred language of place names, such as "fr". Names
+	// in other languages are used when there is no name in this language.
+	Language string
+
+	// Reverse lists the places from the largest to the smallest, so that
+	// the country comes first.
+	Reverse bool
+
+	// Levels limits the number of places included in the title. A positive
+	// value keeps the given number of the smallest places and a negative
+	// value keeps the given number of the largest places. Zero includes
+	// every place in the hierarchy.
+	Levels int
+
+	// SuppressTypes lists place types, such as "Parish", that are left out
+	// of the title. The place the title is generated for is always included.
+	SuppressTypes []string
+}
+
+// EnclosedBy returns the place that enclosed p on the given date, or nil if
+// there is none. As in Gramps, the first place reference that has no date
+// or whose date overlaps the given date is used, and a zero date is taken to
+// be today.
+func (ix *Index) EnclosedBy(p *Placeobj, date Date) *Placeobj {
+	if date.IsZero() {
+		date = today()
+	}
+	for _, pr := range p.Placeref {
+		if rd := pr.Date(); !rd.IsZero() && !rd.overlaps(date) {
+			continue
+		}
+		if enc := ix.Place(pr.Hlink); enc != nil {
+			return enc
+		}
+	}
+	return nil
+}
+
+// PlaceHierarchy returns p followed by the places that enclosed it on the
+// given date, as found by [Index.EnclosedBy], from the smallest to the
+// largest. An error is returned if the hierarchy contains a cycle.
+func (ix *Index) PlaceHierarchy(p *Placeobj, date Date) ([]*Placeobj, error) {
+	if date.IsZero() {
+		date = today()
+	}
+	places := []*Placeobj{p}
+	visited := map[string]bool{p.Handle: true}
+	for {
+		next := ix.EnclosedBy(p, date)
+		if next == nil {
+			return places, nil
+		}
+		if visited[next.Handle] {
+			return places, fmt.Errorf("grampsxml: place %s is enclosed by itself", placeLabel(next))
+		}
+		visited[next.Handle] = true
+		places = append(places, next)
+		p = next
+	}
+}
+
+// Jurisdiction returns the place of the given type, such as "County" or
+// "Country", that enclosed p on the given date, or p itself if it has that
+// type. It returns nil if there is no such place in the hierarchy, and an
+// error if the hierarchy contains a cycle.
+func (ix *Index) Jurisdiction(p *Placeobj, date Date, placeType string) (*Placeobj, error) {
+	places, err := ix.PlaceHierarchy(p, date)
+	if err != nil {
+		return nil, err
+	}
+	for _, pl := range places {
+		if pl.Type == placeType {
+			return pl, nil
+		}
+	}
+	return nil, nil
+}
+
+// PlaceTitle returns the title of p on the given date in the form generated
+// by Gramps, which is the names of p and the places enclosing it separated
+// by commas, such as "Paris, Île-de-France, France". The name of each place
+// is chosen by [Placeobj.NameAt]; places with no name valid on the date are
+// shown as "?". A zero date is taken to be today. An error is returned if
+// the place hierarchy contains a cycle.
+func (ix *Index) PlaceTitle(p *Placeobj, date Date, f PlaceFormat) (string, error) {
+	if date.IsZero() {
+		date = today()
+	}
+	places, err := ix.PlaceHierarchy(p, date)
+	if err != nil {
+		return "", err
+	}
+
+	var names []string
+	for i, pl := range places {
+		if i > 0 && slices.Contains(f.SuppressTypes, pl.Type) {
+			continue
+		}
+		name := pl.NameAt(date, f.Language)
+		if name == "" {
+			name = "?"
+		}
+		names = append(names, name)
+	}
+	switch {
+	case f.Levels > 0 && f.Levels < len(names):
+		names = names[:f.Levels]
+	case f.Levels < 0 && -f.Levels < len(names):
+		names = names[len(names)+f.Levels:]
+	}
+	if f.Reverse {
+		slices.Reverse(names)
+	}
+	return strings.Join(names, ", "), nil
+}
+
+// NameAt returns the name of the place that was in use on the given date,
+// preferring a name in the given language. Names without a date are valid
+// on every date. If no name in the language is valid on the date, the first
+// valid name in any language is returned, and if no name is valid at all,
+// the empty string is returned.
+func (p *Placeobj) NameAt(date Date, lang string) string {
+	var fallback *Pname
+	for i := range p.Pname {
+		n := &p.Pname[i]
+		if nd := n.Date(); !nd.IsZero() && !nd.overlaps(date) {
+			continue
+		}
+		if strval(n.Lang) == lang {
+			return n.Value
+		}
+		if fallback == nil {
+			fallback = n
+		}
+	}
+	if fallback == nil {
+		return ""
+	}
+	return fallback.Value
+}
+
+// today returns the current date.
+func today() Date {
+	y, m, d := time.Now().Date()
+	return Date{Dateval: &Dateval{Val: formatDateValue(y, int(m), d)}}
+}
+
+// placeLabel identifies p in error messages by its ID, or its handle if it
+// has no ID.
+func placeLabel(p *Placeobj) string {
+	if p.ID != nil && *p.ID != "" {
+		return *p.ID
+	}
+	return p.Handle
+}
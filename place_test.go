@@ -0,0 +1,184 @@
+package grampsxml
+
+import (
+	"testing"
+)
+
+var placeSample = Database{
+	Places: &Places{
+		Place: []Placeobj{
+			{
+				Handle:   "_p1",
+				ID:       new("P0001"),
+				Type:     "City",
+				Pname:    []Pname{{Value: "Paris"}},
+				Placeref: []Placeref{{Hlink: "_p2"}},
+			},
+			{
+				Handle:   "_p2",
+				ID:       new("P0002"),
+				Type:     "Region",
+				Pname:    []Pname{{Value: "Île-de-France", Lang: new("fr")}, {Value: "Paris Region", Lang: new("en")}},
+				Placeref: []Placeref{{Hlink: "_p3"}},
+			},
+			{
+				Handle: "_p3",
+				ID:     new("P0003"),
+				Type:   "Country",
+				Pname: []Pname{
+					{Value: "Royaume de France", Lang: new("fr"), Datespan: &Datespan{Start: "987", Stop: "1792"}},
+					{Value: "Kingdom of France", Lang: new("en"), Datespan: &Datespan{Start: "987", Stop: "1792"}},
+					{Value: "France", Lang: new("fr"), Dateval: &Dateval{Val: "1792", Type: new("after")}},
+				},
+			},
+			{
+				Handle:   "_p4",
+				ID:       new("P0004"),
+				Type:     "Parish",
+				Pname:    []Pname{{Value: "Loop"}},
+				Placeref: []Placeref{{Hlink: "_p5"}},
+			},
+			{
+				Handle:   "_p5",
+				ID:       new("P0005"),
+				Type:     "County",
+				Pname:    []Pname{{Value: "Circle"}},
+				Placeref: []Placeref{{Hlink: "_p4"}},
+			},
+		},
+	},
+}
+
+func TestPlaceTitle(t *testing.T) {
+	testCases := []struct {
+		name   string
+		date   string
+		format PlaceFormat
+		want   string
+	}{
+		{
+			name: "modern",
+			date: "1900",
+			want: "Paris, Île-de-France, France",
+		},
+		{
+			name:   "modern english",
+			date:   "1900",
+			format: PlaceFormat{Language: "en"},
+			want:   "Paris, Paris Region, France",
+		},
+		{
+			name:   "historical english",
+			date:   "1750-06-01",
+			format: PlaceFormat{Language: "en"},
+			want:   "Paris, Paris Region, Kingdom of France",
+		},
+		{
+			name: "no valid name",
+			date: "800",
+			want: "Paris, Île-de-France, ?",
+		},
+		{
+			name:   "reverse",
+			date:   "1900",
+			format: PlaceFormat{Reverse: true},
+			want:   "France, Île-de-France, Paris",
+		},
+		{
+			name:   "smallest two",
+			date:   "1900",
+			format: PlaceFormat{Levels: 2},
+			want:   "Paris, Île-de-France",
+		},
+		{
+			name:   "largest one",
+			date:   "1900",
+			format: PlaceFormat{Levels: -1},
+			want:   "France",
+		},
+		{
+			name:   "suppress regions",
+			date:   "1900",
+			format: PlaceFormat{SuppressTypes: []string{"Region"}},
+			want:   "Paris, France",
+		},
+	}
+
+	ix := NewIndex(&placeSample)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ix.PlaceTitle(ix.Place("_p1"), ParseDate(tc.date), tc.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("got %q, wanted %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPlaceTitleCycle(t *testing.T) {
+	ix := NewIndex(&placeSample)
+	if _, err := ix.PlaceTitle(ix.Place("_p4"), ParseDate("1900"), PlaceFormat{}); err == nil {
+		t.Errorf("got no error for cyclic place hierarchy")
+	}
+}
+
+func TestEnclosedBy(t *testing.T) {
+	db := Database{
+		Places: &Places{
+			Place: []Placeobj{
+				{
+					Handle: "_lviv",
+					Type:   "City",
+					Pname:  []Pname{{Value: "Lviv"}},
+					Placeref: []Placeref{
+						{Hlink: "_austria", Datespan: &Datespan{Start: "1772", Stop: "1918"}},
+						{Hlink: "_poland", Datespan: &Datespan{Start: "1919", Stop: "1939"}},
+						{Hlink: "_ukraine", Dateval: &Dateval{Val: "1991-08-24", Type: new("after")}},
+					},
+				},
+				{Handle: "_austria", Type: "Country", Pname: []Pname{{Value: "Austria"}}},
+				{Handle: "_poland", Type: "Country", Pname: []Pname{{Value: "Poland"}}},
+				{Handle: "_ukraine", Type: "Country", Pname: []Pname{{Value: "Ukraine"}}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		date string
+		want string
+	}{
+		{date: "1850", want: "_austria"},
+		{date: "1925-05-01", want: "_poland"},
+		{date: "2001", want: "_ukraine"},
+		{date: "1960", want: ""},
+	}
+
+	ix := NewIndex(&db)
+	lviv := ix.Place("_lviv")
+	for _, tc := range testCases {
+		t.Run(tc.date, func(t *testing.T) {
+			var got string
+			if p := ix.EnclosedBy(lviv, ParseDate(tc.date)); p != nil {
+				got = p.Handle
+			}
+			if got != tc.want {
+				t.Errorf("EnclosedBy got %q, wanted %q", got, tc.want)
+			}
+
+			got = ""
+			p, err := ix.Jurisdiction(lviv, ParseDate(tc.date), "Country")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p != nil {
+				got = p.Handle
+			}
+			if got != tc.want {
+				t.Errorf("Jurisdiction got %q, wanted %q", got, tc.want)
+			}
+		})
+	}
+}
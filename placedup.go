@@ -0,0 +1,332 @@
+package grampsxml
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// PlaceMatch is a pair of places that may be duplicates of each other, as
+// found by [FindDuplicatePlaces].
+type PlaceMatch struct {
+	// A and B are the handles of the two places, in the order they appear
+	// in the database.
+	A, B string
+
+	// Score is the overall likelihood that the places are the same, between
+	// 0 and 1.
+	Score float64
+
+	// Name is the similarity of the closest pair of names of the places,
+	// between 0 and 1.
+	Name float64
+
+	// SameType reports whether the places have the same type.
+	SameType bool
+
+	// SameEnclosure reports whether the places are enclosed by a common
+	// place.
+	SameEnclosure bool
+
+	// Distance is the distance between the places in kilometres, or -1 if
+	// either place lacks valid coordinates.
+	Distance float64
+}
+
+// PlaceMatchOptions controls the pairs of places reported by
+// [FindDuplicatePlaces].
+type PlaceMatchOptions struct {
+	// MinScore is the lowest score of the pairs reported. Zero means 0.8.
+	MinScore float64
+
+	// MaxDistance is the distance in kilometres beyond which places are never
+	// considered duplicates. Zero means 25.
+	MaxDistance float64
+}
+
+// Weights of the parts of a place match score.
+const (
+	placeNameWeight      = 0.7
+	placeTypeWeight      = 0.1
+	placeEnclosureWeight = 0.1
+	placeDistanceWeight  = 0.1
+)
+
+// FindDuplicatePlaces returns the pairs of places in db that may be
+// duplicates, ordered by decreasing score. Each pair is scored on the
+// similarity of the names of the places after case and diacritics are
+// folded, whether they have the same type, whether they are enclosed by a
+// common place and how close together they are. Types that are empty or
+// "Unknown", missing enclosing places and missing coordinates are left out
+// of the score rather than counted against the pair.
+//
+// To avoid comparing every place with every other, only places whose names
+// share their first three letters, or whose coordinates are within about
+// ten kilometres of each other, are compared.
+func FindDuplicatePlaces(db *Database, opts PlaceMatchOptions) []PlaceMatch {
+	if opts.MinScore == 0 {
+		opts.MinScore = 0.8
+	}
+	if opts.MaxDistance == 0 {
+		opts.MaxDistance = 25
+	}
+
+	places := db.Places.list()
+	cands := make([]placeCandidate, len(places))
+	blocks := make(map[string][]int)
+	for i := range places {
+		c := newPlaceCandidate(&places[i])
+		cands[i] = c
+		for _, n := range c.names {
+			blocks[namePrefix(n)] = append(blocks[namePrefix(n)], i)
+		}
+		if c.hasCoord {
+			blocks[c.cell(0, 0)] = append(blocks[c.cell(0, 0)], i)
+		}
+	}
+
+	var matches []PlaceMatch
+	for i := range cands {
+		seen := make(map[int]bool)
+		var keys []string
+		for _, n := range cands[i].names {
+			keys = append(keys, namePrefix(n))
+		}
+		if cands[i].hasCoord {
+			for dlat := -1; dlat <= 1; dlat++ {
+				for dlong := -1; dlong <= 1; dlong++ {
+					keys = append(keys, cands[i].cell(dlat, dlong))
+				}
+			}
+		}
+		for _, key := range keys {
+			for _, j := range blocks[key] {
+				if j <= i || seen[j] {
+					continue
+				}
+				seen[j] = true
+				if m, ok := cands[i].match(&cands[j], opts.MaxDistance); ok && m.Score >= opts.MinScore {
+					matches = append(matches, m)
+				}
+			}
+		}
+	}
+
+	order := make(map[string]int, len(places))
+	for i := range places {
+		order[places[i].Handle] = i
+	}
+	slices.SortStableFunc(matches, func(a, b PlaceMatch) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(order[a.A], order[b.A]),
+			cmp.Compare(order[a.B], order[b.B]),
+		)
+	})
+	return matches
+}
+
+// placeCandidate holds the parts of a place that are compared when looking
+// for duplicates.
+type placeCandidate struct {
+	handle    string
+	names     []string
+	placeType string
+	enclosing []string
+	hasCoord  bool
+	lat, long float64
+}
+
+func newPlaceCandidate(p *Placeobj) placeCandidate {
+	c := placeCandidate{handle: p.Handle}
+	for _, n := range p.Pname {
+		if s := normalizeText(n.Value); s != "" && !slices.Contains(c.names, s) {
+			c.names = append(c.names, s)
+		}
+	}
+	if len(c.names) == 0 && p.Ptitle != nil {
+		if s := normalizeText(*p.Ptitle); s != "" {
+			c.names = append(c.names, s)
+		}
+	}
+	if p.Type != "" && p.Type != "Unknown" {
+		c.placeType = p.Type
+	}
+	for _, pr := range p.Placeref {
+		c.enclosing = append(c.enclosing, pr.Hlink)
+	}
+	if p.Coord != nil {
+		if lat, long, err := p.Coord.Degrees(); err == nil {
+			c.hasCoord, c.lat, c.long = true, lat, long
+		}
+	}
+	return c
+}
+
+// namePrefix returns the blocking key of a normalised place name, which is
+// its first three letters.
+func namePrefix(name string) string {
+	r := []rune(name)
+	return "n:" + string(r[:min(3, len(r))])
+}
+
+// cell returns the blocking key of the grid cell of about ten kilometres
+// that is offset from the place's own cell by the given number of cells.
+func (c *placeCandidate) cell(dlat, dlong int) string {
+	return fmt.Sprintf("c:%d:%d", int(math.Floor(c.lat*10))+dlat, int(math.Floor(c.long*10))+dlong)
+}
+
+// match scores the pair of places c and d. It reports false if the places
+// are further apart than maxDistance.
+func (c *placeCandidate) match(d *placeCandidate, maxDistance float64) (PlaceMatch, bool) {
+	m := PlaceMatch{A: c.handle, B: d.handle, Distance: -1}
+	for _, a := range c.names {
+		for _, b := range d.names {
+			m.Name = max(m.Name, similarity(a, b))
+		}
+	}
+	score, weight := placeNameWeight*m.Name, placeNameWeight
+
+	if c.placeType != "" && d.placeType != "" {
+		weight += placeTypeWeight
+		if c.placeType == d.placeType {
+			m.SameType = true
+			score += placeTypeWeight
+		}
+	}
+	if len(c.enclosing) > 0 && len(d.enclosing) > 0 {
+		weight += placeEnclosureWeight
+		for _, h := range c.enclosing {
+			if slices.Contains(d.enclosing, h) {
+				m.SameEnclosure = true
+				score += placeEnclosureWeight
+				break
+			}
+		}
+	}
+	if c.hasCoord && d.hasCoord {
+		m.Distance = coordDistance(c.lat, c.long, d.lat, d.long)
+		if m.Distance > maxDistance {
+			return m, false
+		}
+		weight += placeDistanceWeight
+		score += placeDistanceWeight * (1 - m.Distance/maxDistance)
+	}
+	m.Score = score / weight
+	return m, true
+}
+
+// coordDistance returns the great circle distance in kilometres between
+// two points given in decimal degrees.
+func coordDistance(lat1, long1, lat2, long2 float64) float64 {
+	const earthRadius = 6371.0
+	rad := func(v float64) float64 { return v * math.Pi / 180 }
+	dlat, dlong := rad(lat2-lat1), rad(long2-long1)
+	h := math.Pow(math.Sin(dlat/2), 2) + math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Pow(math.Sin(dlong/2), 2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// MergePlaces merges the place with the handle duplicate into the place with
+// the handle survivor, as Gramps does when merging places. The names,
+// enclosing places, URLs, media, notes, citations and tags of the duplicate
+// are added to the survivor, along with its code, coordinates and type if
+// the survivor has none. Every reference to the duplicate, including those
+// from events, LDS ordinances, enclosed places and bookmarks, is rewritten
+// to refer to the survivor, and the duplicate is removed. The change time
+// of the survivor and of every object whose references were rewritten is
+// set to the current time.
+func MergePlaces(db *Database, survivor, duplicate string) error {
+	if survivor == duplicate {
+		return fmt.Errorf("grampsxml: cannot merge place %s with itself", survivor)
+	}
+	places := db.Places.list()
+	si := slices.IndexFunc(places, func(p Placeobj) bool { return p.Handle == survivor })
+	if si < 0 {
+		return fmt.Errorf("grampsxml: place %s not found", survivor)
+	}
+	di := slices.IndexFunc(places, func(p Placeobj) bool { return p.Handle == duplicate })
+	if di < 0 {
+		return fmt.Errorf("grampsxml: place %s not found", duplicate)
+	}
+
+	keep, dup := &places[si], places[di]
+	if dup.Priv != nil && *dup.Priv {
+		keep.Priv = dup.Priv
+	}
+	if keep.Type == "" || keep.Type == "Unknown" {
+		keep.Type = dup.Type
+	}
+	if keep.Ptitle == nil {
+		keep.Ptitle = dup.Ptitle
+	}
+	if keep.Code == nil {
+		keep.Code = dup.Code
+	}
+	if keep.Coord == nil {
+		keep.Coord = dup.Coord
+	}
+	keep.Pname = mergeList(keep.Pname, dup.Pname, func(n Pname) string {
+		return strval(n.Lang) + "\x00" + n.Value + "\x00" + n.Date().String()
+	})
+	keep.Placeref = mergeList(keep.Placeref, dup.Placeref, placerefKey)
+	keep.Location = mergeList(keep.Location, dup.Location, func(l Location) string {
+		return fmt.Sprint(strval(l.Street), strval(l.Locality), strval(l.City), strval(l.Parish),
+			strval(l.County), strval(l.State), strval(l.Country), strval(l.Postal), strval(l.Phone))
+	})
+	keep.Url = mergeList(keep.Url, dup.Url, func(u Url) string { return u.Href })
+	keep.Objref = mergeList(keep.Objref, dup.Objref, func(r Objref) string { return r.Hlink })
+	keep.Noteref = mergeList(keep.Noteref, dup.Noteref, func(r Noteref) string { return r.Hlink })
+	keep.Citationref = mergeList(keep.Citationref, dup.Citationref, func(r Citationref) string { return r.Hlink })
+	keep.Tagref = mergeList(keep.Tagref, dup.Tagref, func(r Tagref) string { return r.Hlink })
+	db.Places.Place = slices.Delete(places, di, di+1)
+
+	ix := NewIndex(db)
+	stamp := changeStamp(time.Now())
+	ix.Place(survivor).Change = stamp
+	rewritten := map[string]bool{survivor: true}
+	walkReferences(db, func(r reference) {
+		if r.target == targetPlace && *r.hlink == duplicate {
+			*r.hlink = survivor
+			ix.setChange(r.ownerType, r.owner, stamp)
+			if r.ownerType == targetPlace {
+				rewritten[r.owner] = true
+			}
+		}
+	})
+
+	// Places enclosed by both of the merged places now have two references
+	// to the survivor, and the survivor may refer to itself.
+	places = db.Places.list()
+	for i := range places {
+		p := &places[i]
+		if !rewritten[p.Handle] {
+			continue
+		}
+		p.Placeref = slices.DeleteFunc(mergeList(nil, p.Placeref, placerefKey), func(pr Placeref) bool {
+			return pr.Hlink == p.Handle
+		})
+	}
+	return nil
+}
+
+func placerefKey(pr Placeref) string {
+	return pr.Hlink + "\x00" + pr.Date().String()
+}
+
+// mergeList returns dst with the items of src appended, leaving out those
+// with the same key as an item already in the list.
+func mergeList[T any](dst, src []T, key func(T) string) []T {
+	seen := make(map[string]bool, len(dst)+len(src))
+	for _, v := range dst {
+		seen[key(v)] = true
+	}
+	for _, v := range src {
+		if k := key(v); !seen[k] {
+			seen[k] = true
+			dst = append(dst, v)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,228 @@
+package grampsxml
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func placeDupSample() *Database {
+	return &Database{
+		Events: &Events{
+			Event: []Event{
+				{Handle: "_e1", Type: new("Birth"), Place: &Place{Hlink: "_p2"}},
+				{Handle: "_e2", Type: new("Death"), Place: &Place{Hlink: "_p3"}},
+			},
+		},
+		People: &People{
+			Person: []Person{
+				{Handle: "_i1", LdsOrd: []LdsOrd{{Type: "baptism", Place: &Place{Hlink: "_p2"}}}},
+			},
+		},
+		Places: &Places{
+			Place: []Placeobj{
+				{
+					Handle:   "_p1",
+					ID:       new("P0001"),
+					Type:     "City",
+					Pname:    []Pname{{Value: "Saint-Denis"}},
+					Coord:    &Coord{Lat: "48.9362", Long: "2.3574"},
+					Placeref: []Placeref{{Hlink: "_p5"}},
+				},
+				{
+					Handle:      "_p2",
+					ID:          new("P0002"),
+					Type:        "City",
+					Pname:       []Pname{{Value: "SAINT DÉNIS"}, {Value: "Saint-Denis"}},
+					Code:        new("93066"),
+					Coord:       &Coord{Lat: "N48°56'10\"", Long: "E2°21'27\""},
+					Placeref:    []Placeref{{Hlink: "_p5"}},
+					Citationref: []Citationref{{Hlink: "_c1"}},
+				},
+				{
+					Handle:   "_p3",
+					ID:       new("P0003"),
+					Type:     "City",
+					Pname:    []Pname{{Value: "Saint-Ouen"}},
+					Coord:    &Coord{Lat: "48.9119", Long: "2.3338"},
+					Placeref: []Placeref{{Hlink: "_p5"}},
+				},
+				{
+					Handle: "_p4",
+					ID:     new("P0004"),
+					Type:   "Parish",
+					Pname:  []Pname{{Value: "St Denis"}},
+					Coord:  &Coord{Lat: "51.5", Long: "-0.1"},
+				},
+				{
+					Handle: "_p5",
+					ID:     new("P0005"),
+					Type:   "Country",
+					Pname:  []Pname{{Value: "France"}},
+				},
+				{
+					Handle:   "_p6",
+					ID:       new("P0006"),
+					Type:     "Street",
+					Pname:    []Pname{{Value: "Rue de la République"}},
+					Placeref: []Placeref{{Hlink: "_p1"}, {Hlink: "_p2"}},
+				},
+			},
+		},
+		Bookmarks: &Bookmarks{
+			Bookmark: []Bookmark{{Target: "place", Hlink: "_p2"}},
+		},
+	}
+}
+
+func TestFindDuplicatePlaces(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts PlaceMatchOptions
+		want []PlaceMatch
+	}{
+		{
+			name: "default",
+			want: []PlaceMatch{
+				{A: "_p1", B: "_p2", Score: 0.9999, Name: 1, SameType: true, SameEnclosure: true, Distance: 0.0123},
+			},
+		},
+		{
+			name: "low score",
+			opts: PlaceMatchOptions{MinScore: 0.5},
+			want: []PlaceMatch{
+				{A: "_p1", B: "_p2", Score: 0.9999, Name: 1, SameType: true, SameEnclosure: true, Distance: 0.0123},
+				{A: "_p2", B: "_p3", Score: 0.7327, Name: 0.6364, SameType: true, SameEnclosure: true, Distance: 3.2009},
+				{A: "_p1", B: "_p3", Score: 0.7326, Name: 0.6364, SameType: true, SameEnclosure: true, Distance: 3.2053},
+			},
+		},
+		{
+			name: "near",
+			opts: PlaceMatchOptions{MinScore: 0.5, MaxDistance: 1},
+			want: []PlaceMatch{
+				{A: "_p1", B: "_p2", Score: 0.9988, Name: 1, SameType: true, SameEnclosure: true, Distance: 0.0123},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := FindDuplicatePlaces(placeDupSample(), tc.opts)
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateApprox(0, 0.0001)); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMergePlaces(t *testing.T) {
+	db := placeDupSample()
+	if err := MergePlaces(db, "_p1", "_p2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ix := NewIndex(db)
+	if ix.Place("_p2") != nil {
+		t.Errorf("duplicate place was not removed")
+	}
+	p1 := ix.Place("_p1")
+	if p1.Change == "" {
+		t.Errorf("survivor change time was not set")
+	}
+	wantPlace := Placeobj{
+		Handle:      "_p1",
+		ID:          new("P0001"),
+		Type:        "City",
+		Pname:       []Pname{{Value: "Saint-Denis"}, {Value: "SAINT DÉNIS"}},
+		Code:        new("93066"),
+		Coord:       &Coord{Lat: "48.9362", Long: "2.3574"},
+		Placeref:    []Placeref{{Hlink: "_p5"}},
+		Citationref: []Citationref{{Hlink: "_c1"}},
+	}
+	if diff := cmp.Diff(wantPlace, *p1, cmpopts.IgnoreFields(Placeobj{}, "Change")); diff != "" {
+		t.Errorf("survivor mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := ix.Event("_e1").Place.Hlink; got != "_p1" {
+		t.Errorf("event place: got %s, want _p1", got)
+	}
+	if ix.Event("_e1").Change == "" || ix.Event("_e2").Change != "" {
+		t.Errorf("change times of events not set as expected")
+	}
+	if got := ix.Person("_i1").LdsOrd[0].Place.Hlink; got != "_p1" {
+		t.Errorf("lds ordinance place: got %s, want _p1", got)
+	}
+	if diff := cmp.Diff([]Placeref{{Hlink: "_p1"}}, ix.Place("_p6").Placeref); diff != "" {
+		t.Errorf("enclosed place mismatch (-want +got):\n%s", diff)
+	}
+	if got := db.Bookmarks.Bookmark[0].Hlink; got != "_p1" {
+		t.Errorf("bookmark: got %s, want _p1", got)
+	}
+}
+
+func TestMergePlacesPrivacy(t *testing.T) {
+	testCases := []struct {
+		name        string
+		keep, dup   *bool
+		wantPrivate bool
+	}{
+		{name: "both public"},
+		{name: "survivor private", keep: new(true), wantPrivate: true},
+		{name: "duplicate private", dup: new(true), wantPrivate: true},
+		{name: "both private", keep: new(true), dup: new(true), wantPrivate: true},
+		{name: "explicitly public", keep: new(false), dup: new(false)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := placeDupSample()
+			db.Places.Place[0].Priv = tc.keep
+			db.Places.Place[1].Priv = tc.dup
+			if err := MergePlaces(db, "_p1", "_p2"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			p := NewIndex(db).Place("_p1")
+			if got := p.Priv != nil && *p.Priv; got != tc.wantPrivate {
+				t.Errorf("got private %v, want %v", got, tc.wantPrivate)
+			}
+		})
+	}
+}
+
+func TestMergePlacesLeavesOtherPlaces(t *testing.T) {
+	db := placeDupSample()
+	// A place that does not refer to either of the merged places keeps its
+	// own duplicate references.
+	db.Places.Place[3].Placeref = []Placeref{{Hlink: "_p5"}, {Hlink: "_p5"}}
+	if err := MergePlaces(db, "_p1", "_p2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p4 := NewIndex(db).Place("_p4")
+	if diff := cmp.Diff([]Placeref{{Hlink: "_p5"}, {Hlink: "_p5"}}, p4.Placeref); diff != "" {
+		t.Errorf("unrelated place mismatch (-want +got):\n%s", diff)
+	}
+	if p4.Change != "" {
+		t.Errorf("unrelated place change time was set")
+	}
+}
+
+func TestMergePlacesErrors(t *testing.T) {
+	testCases := []struct {
+		name                string
+		survivor, duplicate string
+	}{
+		{name: "same", survivor: "_p1", duplicate: "_p1"},
+		{name: "missing survivor", survivor: "_px", duplicate: "_p1"},
+		{name: "missing duplicate", survivor: "_p1", duplicate: "_px"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := placeDupSample()
+			if err := MergePlaces(db, tc.survivor, tc.duplicate); err == nil {
+				t.Errorf("expected error")
+			}
+			if len(db.Places.Place) != 6 {
+				t.Errorf("places were removed")
+			}
+		})
+	}
+}
@@ -0,0 +1,710 @@
+package grampsxml
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Query selects objects of one type from a database. Queries are written as
+// the type of object followed by an optional condition, for example
+//
+//	person where surname ~ "Garn" and birth.date < 1850 and has tag "ToDo"
+//
+// A condition compares the values found at a field path with a literal,
+// using = and != for equality, ~ and !~ for containment, and <, <=, > and >=
+// for ordering. Conditions may be combined with and, or, not and
+// parentheses. "has path" is true if the path leads to any non-empty value
+// and "has tag name" is true if the object has the named tag.
+//
+// A field path is a sequence of names separated by dots, each naming a field
+// by its Go, XML or JSON name. Lists are searched element by element, and a
+// condition holds if any value found satisfies it, except for != and !~,
+// which hold if none of the values is equal to or contains the literal. A
+// reference, such as the father of a family, is followed to the object it
+// refers to when the next name is not one of its own fields, so that
+// "family.father.name.first" finds the given names of the fathers of a
+// person's families. A reference used as a value compares as the ID of the
+// object it refers to.
+//
+// Some names are provided in addition to fields: "date" is the date of an
+// event, name or other dated record, and an event type such as "birth" is
+// the first event of that type in which a person or family has the primary
+// role. Shorter names such as "surname", "given", "family", "parents",
+// "children", "events", "notes", "tag" and "title" stand for the fields
+// Gramps uses for them. Dates are compared using their bounds, so a date is
+// less than another if it must fall before it, equal to it if the two may
+// fall on the same day, and less than or equal to it if either is true.
+// Other values are compared as numbers if both are numbers and otherwise as
+// text, ignoring case.
+type Query struct {
+	// Type is the type of object selected, named as in [ChangedObject].
+	Type string
+
+	where queryExpr
+}
+
+// ParseQuery parses a query.
+func ParseQuery(s string) (*Query, error) {
+	p := &queryParser{tokens: lexQuery(s)}
+	q, err := p.query()
+	if err != nil {
+		return nil, fmt.Errorf("grampsxml: parse query: %w", err)
+	}
+	return q, nil
+}
+
+// Run returns the objects in db selected by q, in the order they appear in
+// db. An error is returned if the query names a field that does not exist.
+func (q *Query) Run(db *Database) ([]ObjectRef, error) {
+	c := &queryContext{ix: NewIndex(db), objects: make(map[string]primaryObject), targets: make(map[*string]string)}
+	var candidates []primaryObject
+	walkObjects(db, func(o primaryObject) {
+		c.objects[o.objType+"\x00"+o.handle] = o
+		if o.objType == q.Type {
+			candidates = append(candidates, o)
+		}
+	})
+	walkReferences(db, func(r reference) { c.targets[r.hlink] = r.target })
+
+	var refs []ObjectRef
+	for _, o := range candidates {
+		if q.where != nil {
+			ok, err := q.where.eval(c, reflect.ValueOf(o.value))
+			if err != nil {
+				return nil, fmt.Errorf("grampsxml: query: %w", err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		refs = append(refs, objectRef(o))
+	}
+	return refs, nil
+}
+
+// queryContext holds the state of the evaluation of a query.
+type queryContext struct {
+	ix      *Index
+	objects map[string]primaryObject // by type and handle
+	targets map[*string]string       // type of object referred to, by hlink
+}
+
+// object returns the object referred to by the Hlink field h.
+func (c *queryContext) object(h reflect.Value) (primaryObject, bool) {
+	if !h.CanAddr() {
+		return primaryObject{}, false
+	}
+	target, ok := c.targets[h.Addr().Interface().(*string)]
+	if !ok {
+		return primaryObject{}, false
+	}
+	o, ok := c.objects[target+"\x00"+h.String()]
+	return o, ok
+}
+
+type queryExpr interface {
+	eval(c *queryContext, v reflect.Value) (bool, error)
+}
+
+type queryAnd struct{ l, r queryExpr }
+
+func (e queryAnd) eval(c *queryContext, v reflect.Value) (bool, error) {
+	ok, err := e.l.eval(c, v)
+	if err != nil || !ok {
+		return false, err
+	}
+	return e.r.eval(c, v)
+}
+
+type queryOr struct{ l, r queryExpr }
+
+func (e queryOr) eval(c *queryContext, v reflect.Value) (bool, error) {
+	ok, err := e.l.eval(c, v)
+	if err != nil || ok {
+		return ok, err
+	}
+	return e.r.eval(c, v)
+}
+
+type queryNot struct{ e queryExpr }
+
+func (e queryNot) eval(c *queryContext, v reflect.Value) (bool, error) {
+	ok, err := e.e.eval(c, v)
+	return !ok, err
+}
+
+// queryHas is true if the path leads to any non-empty value.
+type queryHas struct{ path []string }
+
+func (e queryHas) eval(c *queryContext, v reflect.Value) (bool, error) {
+	vals, err := c.values(v, e.path)
+	if err != nil {
+		return false, err
+	}
+	for _, qv := range vals {
+		if qv.text != "" || !qv.date.IsZero() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// queryCompare compares the values found at a path with a literal.
+type queryCompare struct {
+	path  []string
+	op    string
+	value string
+}
+
+func (e queryCompare) eval(c *queryContext, v reflect.Value) (bool, error) {
+	vals, err := c.values(v, e.path)
+	if err != nil {
+		return false, err
+	}
+	op, negate := e.op, false
+	switch op {
+	case "!=":
+		op, negate = "=", true
+	case "!~":
+		op, negate = "~", true
+	}
+	for _, qv := range vals {
+		if qv.compare(op, e.value) {
+			return !negate, nil
+		}
+	}
+	return negate, nil
+}
+
+// queryValue is a value found by a field path, which is a date if isDate is
+// set and text otherwise.
+type queryValue struct {
+	text   string
+	date   Date
+	isDate bool
+}
+
+func (qv queryValue) compare(op, lit string) bool {
+	if qv.isDate {
+		if op == "~" {
+			return containsFold(qv.date.String(), lit)
+		}
+		lo1, hi1, ok1 := qv.date.bounds()
+		lo2, hi2, ok2 := ParseDate(lit).bounds()
+		if !ok1 || !ok2 {
+			return false
+		}
+		switch op {
+		case "=":
+			return lo1 <= hi2 && lo2 <= hi1
+		case "<":
+			return hi1 < lo2
+		case "<=":
+			return lo1 <= hi2
+		case ">":
+			return lo1 > hi2
+		case ">=":
+			return hi1 >= lo2
+		}
+		return false
+	}
+
+	switch op {
+	case "=":
+		return strings.EqualFold(qv.text, lit)
+	case "~":
+		return containsFold(qv.text, lit)
+	}
+	var n int
+	x, err1 := strconv.ParseFloat(qv.text, 64)
+	y, err2 := strconv.ParseFloat(lit, 64)
+	if err1 == nil && err2 == nil {
+		switch {
+		case x < y:
+			n = -1
+		case x > y:
+			n = 1
+		}
+	} else {
+		n = strings.Compare(strings.ToLower(qv.text), strings.ToLower(lit))
+	}
+	switch op {
+	case "<":
+		return n < 0
+	case "<=":
+		return n <= 0
+	case ">":
+		return n > 0
+	case ">=":
+		return n >= 0
+	}
+	return false
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// queryAliases are the paths that names not found as fields stand for. The
+// first path whose first name is a field of the record is used.
+var queryAliases = map[string][]string{
+	"surname":      {"name.surname"},
+	"given":        {"name.first", "first"},
+	"name":         {"pname"},
+	"family":       {"parentin"},
+	"families":     {"parentin"},
+	"parents":      {"childof"},
+	"child":        {"childref"},
+	"children":     {"childref"},
+	"event":        {"eventref"},
+	"events":       {"eventref"},
+	"note":         {"noteref"},
+	"notes":        {"noteref"},
+	"citation":     {"citationref"},
+	"citations":    {"citationref"},
+	"source":       {"sourceref"},
+	"tag":          {"tagref"},
+	"tags":         {"tagref"},
+	"media":        {"objref"},
+	"repository":   {"reporef"},
+	"repositories": {"reporef"},
+	"enclosed_by":  {"placeref"},
+	"title":        {"stitle", "ptitle"},
+	"author":       {"sauthor"},
+	"pubinfo":      {"spubinfo"},
+	"abbrev":       {"sabbrev"},
+}
+
+// queryTextFields are the fields that give the value of a record that is
+// compared as a whole, in order of preference.
+var queryTextFields = []string{"Surname", "Value", "Val", "Text", "Type", "Name"}
+
+type dated interface{ Date() Date }
+
+var (
+	dateType  = reflect.TypeFor[Date]()
+	datedType = reflect.TypeFor[dated]()
+)
+
+// values returns the values found by following path from v.
+func (c *queryContext) values(v reflect.Value, path []string) ([]queryValue, error) {
+	vals, err := c.follow(v, path)
+	if err != nil {
+		return nil, err
+	}
+	var qvs []queryValue
+	for _, v := range vals {
+		qv, err := c.leaves(v)
+		if err != nil {
+			return nil, err
+		}
+		qvs = append(qvs, qv...)
+	}
+	return qvs, nil
+}
+
+// follow returns the records and values found by following path from v.
+func (c *queryContext) follow(v reflect.Value, path []string) ([]reflect.Value, error) {
+	vals := []reflect.Value{v}
+	for _, name := range path {
+		var next []reflect.Value
+		for _, v := range vals {
+			fv, err := c.field(v, name)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, fv...)
+		}
+		vals = next
+	}
+	return vals, nil
+}
+
+// field returns the values of the named field of v, or of each element of v
+// if it is a list.
+func (c *queryContext) field(v reflect.Value, name string) ([]reflect.Value, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		var vals []reflect.Value
+		for i := range v.Len() {
+			fv, err := c.field(v.Index(i), name)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, fv...)
+		}
+		return vals, nil
+	case reflect.Struct:
+	default:
+		return nil, fmt.Errorf("%s has no field %q", v.Type(), name)
+	}
+
+	if f, ok := structField(v.Type(), name); ok {
+		return []reflect.Value{v.FieldByIndex(f.Index)}, nil
+	}
+
+	if strings.EqualFold(name, "date") {
+		if d, ok := dateOf(v); ok {
+			return []reflect.Value{reflect.ValueOf(d)}, nil
+		}
+	}
+
+	if t, ok := ParseEventType(name); ok {
+		switch x := v.Addr().Interface().(type) {
+		case *Person:
+			if ev := personEvent(c.ix, x, string(t)); ev != nil {
+				return []reflect.Value{reflect.ValueOf(ev)}, nil
+			}
+			return nil, nil
+		case *Family:
+			if ev := familyEvent(c.ix, x, string(t)); ev != nil {
+				return []reflect.Value{reflect.ValueOf(ev)}, nil
+			}
+			return nil, nil
+		}
+	}
+
+	for _, alias := range queryAliases[strings.ToLower(name)] {
+		path := strings.Split(alias, ".")
+		if _, ok := structField(v.Type(), path[0]); !ok {
+			continue
+		}
+		return c.follow(v, path)
+	}
+
+	if h := v.FieldByName("Hlink"); h.IsValid() {
+		o, ok := c.object(h)
+		if !ok {
+			return nil, nil
+		}
+		return c.field(reflect.ValueOf(o.value), name)
+	}
+
+	return nil, fmt.Errorf("%s has no field %q", v.Type(), name)
+}
+
+// leaves returns the values v holds for comparison.
+func (c *queryContext) leaves(v reflect.Value) ([]queryValue, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return []queryValue{{text: v.String()}}, nil
+	case reflect.Bool:
+		return []queryValue{{text: strconv.FormatBool(v.Bool())}}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return []queryValue{{text: strconv.FormatInt(v.Int(), 10)}}, nil
+	case reflect.Float32, reflect.Float64:
+		return []queryValue{{text: strconv.FormatFloat(v.Float(), 'f', -1, 64)}}, nil
+	case reflect.Slice:
+		var qvs []queryValue
+		for i := range v.Len() {
+			qv, err := c.leaves(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			qvs = append(qvs, qv...)
+		}
+		return qvs, nil
+	case reflect.Struct:
+	default:
+		return nil, fmt.Errorf("%s cannot be compared", v.Type())
+	}
+
+	if v.Type() == dateType {
+		return []queryValue{{date: v.Interface().(Date), isDate: true}}, nil
+	}
+	if h := v.FieldByName("Hlink"); h.IsValid() {
+		o, ok := c.object(h)
+		if !ok {
+			return nil, nil
+		}
+		return c.leaves(reflect.ValueOf(o.value))
+	}
+	if h := v.FieldByName("Handle"); h.IsValid() {
+		if id := v.FieldByName("ID"); id.IsValid() && !id.IsNil() {
+			return []queryValue{{text: id.Elem().String()}}, nil
+		}
+		return []queryValue{{text: h.String()}}, nil
+	}
+	for _, name := range queryTextFields {
+		if f := v.FieldByName(name); f.IsValid() {
+			return c.leaves(f)
+		}
+	}
+	if d, ok := dateOf(v); ok {
+		return []queryValue{{date: d, isDate: true}}, nil
+	}
+	return nil, fmt.Errorf("%s cannot be compared", v.Type())
+}
+
+// structField returns the field of t with the given Go, XML or JSON name,
+// ignoring case.
+func structField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		xmlName, _, _ := strings.Cut(f.Tag.Get("xml"), ",")
+		jsonName, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if strings.EqualFold(f.Name, name) || strings.EqualFold(xmlName, name) || strings.EqualFold(jsonName, name) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// dateOf returns the date of v, if it is a record that has one.
+func dateOf(v reflect.Value) (Date, bool) {
+	if v.CanAddr() && v.Addr().Type().Implements(datedType) {
+		return v.Addr().Interface().(dated).Date(), true
+	}
+	if v.Type().Implements(datedType) {
+		return v.Interface().(dated).Date(), true
+	}
+	return Date{}, false
+}
+
+// queryToken is a lexical token of a query: a word, a quoted string, an
+// operator or parenthesis, or the empty token at the end.
+type queryToken struct {
+	kind byte // 'w' word, 's' string, 'o' operator, 0 end
+	text string
+	pos  int
+}
+
+// lexQuery splits a query into tokens. Malformed input produces an operator
+// token holding the offending text, which the parser then rejects.
+func lexQuery(s string) []queryToken {
+	var tokens []queryToken
+	isWord := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-:?", r)
+	}
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				tokens = append(tokens, queryToken{kind: 'o', text: s[i:], pos: i})
+				i = len(s)
+				continue
+			}
+			text, err := strconv.Unquote(s[i : j+1])
+			if err != nil {
+				tokens = append(tokens, queryToken{kind: 'o', text: s[i : j+1], pos: i})
+			} else {
+				tokens = append(tokens, queryToken{kind: 's', text: text, pos: i})
+			}
+			i = j + 1
+		case isWord(r):
+			j := i
+			for j < len(s) {
+				r, size := utf8.DecodeRuneInString(s[j:])
+				if !isWord(r) {
+					break
+				}
+				j += size
+			}
+			tokens = append(tokens, queryToken{kind: 'w', text: s[i:j], pos: i})
+			i = j
+		default:
+			n := size
+			for _, op := range []string{"!=", "!~", "<=", ">="} {
+				if strings.HasPrefix(s[i:], op) {
+					n = 2
+				}
+			}
+			tokens = append(tokens, queryToken{kind: 'o', text: s[i : i+n], pos: i})
+			i += n
+		}
+	}
+	return append(tokens, queryToken{pos: len(s)})
+}
+
+// queryParser parses a query by recursive descent.
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken { return p.tokens[p.pos] }
+
+func (p *queryParser) next() queryToken {
+	t := p.tokens[p.pos]
+	if t.kind != 0 {
+		p.pos++
+	}
+	return t
+}
+
+// keyword consumes the next token if it is the given keyword.
+func (p *queryParser) keyword(kw string) bool {
+	if t := p.peek(); t.kind == 'w' && strings.EqualFold(t.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) errorf(t queryToken, format string, args ...any) error {
+	if t.kind == 0 {
+		return fmt.Errorf("%s at end of query", fmt.Sprintf(format, args...))
+	}
+	return fmt.Errorf("%s at %q (offset %d)", fmt.Sprintf(format, args...), t.text, t.pos)
+}
+
+func (p *queryParser) query() (*Query, error) {
+	t := p.next()
+	if t.kind != 'w' {
+		return nil, p.errorf(t, "expected type of object")
+	}
+	q := &Query{}
+	for objType, label := range objectTypeLabels {
+		if strings.EqualFold(t.text, objType) || strings.EqualFold(t.text, label) {
+			q.Type = objType
+		}
+	}
+	if q.Type == "" {
+		return nil, p.errorf(t, "unknown type of object")
+	}
+	if p.keyword("where") {
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		q.where = e
+	}
+	if t := p.peek(); t.kind != 0 {
+		return nil, p.errorf(t, "unexpected text")
+	}
+	return q, nil
+}
+
+func (p *queryParser) or() (queryExpr, error) {
+	e, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		r, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		e = queryOr{e, r}
+	}
+	return e, nil
+}
+
+func (p *queryParser) and() (queryExpr, error) {
+	e, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		r, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		e = queryAnd{e, r}
+	}
+	return e, nil
+}
+
+func (p *queryParser) unary() (queryExpr, error) {
+	switch t := p.peek(); {
+	case p.keyword("not"):
+		e, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return queryNot{e}, nil
+	case t.kind == 'o' && t.text == "(":
+		p.pos++
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != 'o' || t.text != ")" {
+			return nil, p.errorf(t, "expected )")
+		}
+		return e, nil
+	case p.keyword("has"):
+		if p.keyword("tag") {
+			v, err := p.literal()
+			if err != nil {
+				return nil, err
+			}
+			return queryCompare{path: []string{"tagref", "name"}, op: "=", value: v}, nil
+		}
+		path, err := p.path()
+		if err != nil {
+			return nil, err
+		}
+		return queryHas{path}, nil
+	}
+
+	path, err := p.path()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	switch t.text {
+	case "=", "!=", "~", "!~", "<", "<=", ">", ">=":
+		if t.kind != 'o' {
+			break
+		}
+		p.pos++
+		v, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		return queryCompare{path: path, op: t.text, value: v}, nil
+	}
+	return queryHas{path}, nil
+}
+
+func (p *queryParser) path() ([]string, error) {
+	t := p.next()
+	if t.kind != 'w' {
+		return nil, p.errorf(t, "expected field")
+	}
+	path := strings.Split(t.text, ".")
+	for _, name := range path {
+		if r, _ := utf8.DecodeRuneInString(name); !unicode.IsLetter(r) {
+			return nil, p.errorf(t, "invalid field")
+		}
+	}
+	return path, nil
+}
+
+func (p *queryParser) literal() (string, error) {
+	t := p.next()
+	if t.kind != 'w' && t.kind != 's' {
+		return "", p.errorf(t, "expected value")
+	}
+	return t.text, nil
+}
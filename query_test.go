@@ -0,0 +1,185 @@
+package grampsxml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestQueryRun(t *testing.T) {
+	var db Database
+	if err := ReadCSV(strings.NewReader(csvSample), &db); err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	db.Tags = &Tags{Tag: []Tag{{Handle: "_t1", Name: "ToDo"}}}
+	db.People.Person[2].Tagref = []Tagref{{Hlink: "_t1"}}
+
+	testCases := []struct {
+		query string
+		want  []string
+	}{
+		{query: "person", want: []string{"I0000", "I0001", "I0002"}},
+		{query: "Person where gender = f", want: []string{"I0001", "I0002"}},
+		{query: `person where surname ~ "garn"`, want: []string{"I0000", "I0002"}},
+		{query: `person where surname !~ "garn"`, want: []string{"I0001"}},
+		{query: `person where name.first = "Anna"`, want: []string{"I0001"}},
+		{query: `person where name.first_name = "Anna"`, want: []string{"I0001"}},
+		{query: "person where birth.date < 1857", want: []string{"I0000"}},
+		{query: "person where birth.date >= 1858", want: []string{"I0001", "I0002"}},
+		{query: "person where birth.date = 1859", want: []string{"I0001"}},
+		{query: "person where birth.date <= 1858", want: []string{"I0000", "I0001"}},
+		{query: "person where birth.date <= 1855-06-20", want: []string{}},
+		{query: "person where birth.date > 1858", want: []string{"I0002"}},
+		{query: "person where birth.date >= 1860", want: []string{"I0001", "I0002"}},
+		{query: `person where birth.date = "between 1850 and 1856"`, want: []string{"I0000"}},
+		{query: "person where birth.place.title ~ greenfield", want: []string{"I0000", "I0002"}},
+		{query: "person where birth.citation.source.title ~ register", want: []string{"I0000"}},
+		{query: `person where surname ~ "Garn" and birth.date < 1900 and has tag "ToDo"`, want: []string{"I0002"}},
+		{query: `person where has tag todo or gender = m`, want: []string{"I0000", "I0002"}},
+		{query: "person where not (has death or has tag ToDo)", want: []string{"I0001"}},
+		{query: "person where has death", want: []string{"I0000"}},
+		{query: "person where family.mother.name.first = Anna", want: []string{"I0000", "I0001"}},
+		{query: "person where parents.father = I0000", want: []string{"I0002"}},
+		{query: "person where notes.text ~ miller", want: []string{"I0000"}},
+		{query: "family where father.given = Lewis and marriage.date = 1879-04", want: []string{"F0000"}},
+		{query: "family where children = I0002", want: []string{"F0000"}},
+		{query: "family where rel = married", want: []string{"F0000"}},
+		{query: "event where type = birth and place = P0000", want: []string{"E0000", "E0003"}},
+		{query: "place where enclosed_by.name = Yorkshire", want: []string{"P0000"}},
+		{query: "place where coord.lat > 50", want: []string{"P0000"}},
+		{query: "tag where name = todo", want: []string{"ToDo"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			q, err := ParseQuery(tc.query)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			refs, err := q.Run(&db)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := []string{}
+			for _, r := range refs {
+				if r.ID == "" {
+					got = append(got, db.Tags.Tag[0].Name)
+					continue
+				}
+				got = append(got, r.ID)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestQueryDateOperators(t *testing.T) {
+	testCases := []struct {
+		date string
+		want map[string]bool // by operator
+	}{
+		{date: "between 1849 and 1851", want: map[string]bool{"=": true, "<": false, "<=": true, ">": false, ">=": true}},
+		{date: "1850-06-01", want: map[string]bool{"=": true, "<": false, "<=": true, ">": false, ">=": true}},
+		{date: "1849", want: map[string]bool{"=": false, "<": true, "<=": true, ">": false, ">=": false}},
+		{date: "1851", want: map[string]bool{"=": false, "<": false, "<=": false, ">": true, ">=": true}},
+		{date: "before 1850", want: map[string]bool{"=": true, "<": false, "<=": true, ">": false, ">=": true}},
+		{date: "after 1850", want: map[string]bool{"=": true, "<": false, "<=": true, ">": false, ">=": true}},
+		{date: "Christmas", want: map[string]bool{"=": false, "<": false, "<=": false, ">": false, ">=": false}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.date, func(t *testing.T) {
+			qv := queryValue{date: ParseDate(tc.date), isDate: true}
+			got := make(map[string]bool)
+			for op := range tc.want {
+				got[op] = qv.compare(op, "1850")
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("%s compared with 1850 (-want +got):\n%s", tc.date, diff)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	testCases := []struct {
+		query string
+		want  string
+	}{
+		{query: "", want: "expected type of object at end of query"},
+		{query: "spaceship", want: "unknown type of object"},
+		{query: "person where", want: "expected field at end of query"},
+		{query: "person where gender =", want: "expected value at end of query"},
+		{query: "person where (gender = m", want: "expected ) at end of query"},
+		{query: `person where name ~ "Garn`, want: "expected value"},
+		{query: "person where gender = m m", want: `unexpected text at "m" (offset 24)`},
+		{query: "person where .name = x", want: "invalid field"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			_, err := ParseQuery(tc.query)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got error %v, wanted one containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestQueryRunUnknownField(t *testing.T) {
+	var db Database
+	if err := ReadCSV(strings.NewReader(csvSample), &db); err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	q, err := ParseQuery("person where name.colour = red")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	_, err = q.Run(&db)
+	if err == nil || !strings.Contains(err.Error(), `grampsxml.Name has no field "colour"`) {
+		t.Errorf("got error %v, wanted unknown field", err)
+	}
+}
+
+func TestQueryRunSharedHandle(t *testing.T) {
+	// Handles are only unique within a type, so a reference must find the
+	// object of the type it refers to.
+	db := &Database{
+		People: &People{Person: []Person{{Handle: "_a1", ID: new("I0001"), Name: []Name{{First: new("Lewis")}}}}},
+		Families: &Families{Family: []Family{
+			{Handle: "_a1", ID: new("F0001"), Father: &Father{Hlink: "_a1"}},
+		}},
+		Notes: &Notes{Note: []Note{{Handle: "_a1", ID: new("N0001"), Text: "Lewis"}}},
+	}
+
+	testCases := []struct {
+		query string
+		want  []string
+	}{
+		{query: "family where father = I0001", want: []string{"F0001"}},
+		{query: "family where father.given = Lewis", want: []string{"F0001"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			q, err := ParseQuery(tc.query)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			refs, err := q.Run(db)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, r := range refs {
+				got = append(got, r.ID)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
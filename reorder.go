@@ -0,0 +1,123 @@
+package grampsxml
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+)
+
+// ReorderOptions controls [ReorderIDs].
+type ReorderOptions struct {
+	// IDFormats holds the printf style patterns of the new IDs, as in
+	// [ImportOptions].
+	IDFormats map[string]string
+
+	// Types lists the types of object, named as in [ChangedObject], whose
+	// IDs are changed. Nil means every type that has IDs.
+	Types []string
+
+	// Keep leaves IDs that already match the pattern for their type
+	// unchanged, so that only the remaining objects are renumbered, using
+	// the lowest numbers not already in use.
+	Keep bool
+}
+
+// IDChange records the change of an object's Gramps ID by [ReorderIDs].
+type IDChange struct {
+	Type   string `json:"type"`
+	Handle string `json:"handle"`
+	OldID  string `json:"old_id,omitempty"`
+	NewID  string `json:"new_id"`
+}
+
+// ReorderIDs renumbers the Gramps IDs of the objects in db, as the Gramps
+// Reorder Gramps IDs tool does, and returns the IDs that were changed.
+// Objects of each type are numbered from zero in the order of their current
+// IDs: first those that match the pattern for the type, by number, then the
+// others, by ID, and then the objects without IDs in the order they appear.
+// An ID used by more than one object is kept by the first of them only.
+// Handles are not changed, so references between objects are unaffected.
+// The change time of every object whose ID changed is set to the current
+// time. An error is returned, and db is left unchanged, if any of the
+// patterns in opts is invalid.
+func ReorderIDs(db *Database, opts ReorderOptions) ([]IDChange, error) {
+	if opts.Types == nil {
+		for t := range defaultIDFormats {
+			opts.Types = append(opts.Types, t)
+		}
+	}
+	formats := make(map[string]string)
+	for _, t := range opts.Types {
+		if f := cmp.Or(opts.IDFormats[t], defaultIDFormats[t]); f != "" {
+			formats[t] = f
+		}
+	}
+	if err := checkIDFormats(formats); err != nil {
+		return nil, err
+	}
+
+	type entry struct {
+		obj    primaryObject
+		id     string
+		number int // or math.MaxInt if the ID does not match the pattern
+	}
+	byType := make(map[string][]entry)
+	var types []string
+	walkObjects(db, func(o primaryObject) {
+		format, ok := formats[o.objType]
+		if !ok || o.id == nil {
+			return
+		}
+		if _, ok := byType[o.objType]; !ok {
+			types = append(types, o.objType)
+		}
+		e := entry{obj: o, id: strval(*o.id), number: math.MaxInt}
+		if n, ok := parseID(format, e.id); ok {
+			e.number = n
+		}
+		byType[o.objType] = append(byType[o.objType], e)
+	})
+
+	var changes []IDChange
+	stamp := changeStamp(time.Now())
+	for _, t := range types {
+		entries := byType[t]
+		missing := func(e entry) int {
+			if e.id == "" {
+				return 1
+			}
+			return 0
+		}
+		slices.SortStableFunc(entries, func(a, b entry) int {
+			return cmp.Or(
+				cmp.Compare(missing(a), missing(b)),
+				cmp.Compare(a.number, b.number),
+				cmp.Compare(a.id, b.id),
+			)
+		})
+		s := newIDSequence(formats[t])
+		kept := make([]bool, len(entries))
+		if opts.Keep {
+			for i, e := range entries {
+				if e.number != math.MaxInt && !s.used[e.id] {
+					s.reserve(e.id)
+					kept[i] = true
+				}
+			}
+		}
+		for i, e := range entries {
+			if kept[i] {
+				continue
+			}
+			id := s.allocate()
+			if id == e.id {
+				continue
+			}
+			changes = append(changes, IDChange{Type: t, Handle: e.obj.handle, OldID: e.id, NewID: id})
+			*e.obj.id = new(id)
+			*e.obj.change = stamp
+		}
+	}
+	return changes, nil
+}
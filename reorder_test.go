@@ -0,0 +1,120 @@
+package grampsxml
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func reorderSample() *Database {
+	return &Database{
+		People: &People{Person: []Person{
+			{Handle: "_i1", ID: new("I0007")},
+			{Handle: "_i2", ID: new("I0002")},
+			{Handle: "_i3", ID: new("X12")},
+			{Handle: "_i4"},
+			{Handle: "_i5", ID: new("I0002")},
+		}},
+		Families: &Families{Family: []Family{{Handle: "_f1", ID: new("F0003")}}},
+		Tags:     &Tags{Tag: []Tag{{Handle: "_t1", Name: "ToDo"}}},
+	}
+}
+
+func TestReorderIDs(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts ReorderOptions
+		want []IDChange
+	}{
+		{
+			name: "default",
+			want: []IDChange{
+				{Type: "person", Handle: "_i2", OldID: "I0002", NewID: "I0000"},
+				{Type: "person", Handle: "_i5", OldID: "I0002", NewID: "I0001"},
+				{Type: "person", Handle: "_i1", OldID: "I0007", NewID: "I0002"},
+				{Type: "person", Handle: "_i3", OldID: "X12", NewID: "I0003"},
+				{Type: "person", Handle: "_i4", NewID: "I0004"},
+				{Type: "family", Handle: "_f1", OldID: "F0003", NewID: "F0000"},
+			},
+		},
+		{
+			name: "keep",
+			opts: ReorderOptions{Keep: true},
+			want: []IDChange{
+				{Type: "person", Handle: "_i5", OldID: "I0002", NewID: "I0000"},
+				{Type: "person", Handle: "_i3", OldID: "X12", NewID: "I0001"},
+				{Type: "person", Handle: "_i4", NewID: "I0003"},
+			},
+		},
+		{
+			name: "formats and types",
+			opts: ReorderOptions{IDFormats: map[string]string{"person": "X%d"}, Types: []string{"person"}, Keep: true},
+			want: []IDChange{
+				{Type: "person", Handle: "_i2", OldID: "I0002", NewID: "X0"},
+				{Type: "person", Handle: "_i5", OldID: "I0002", NewID: "X1"},
+				{Type: "person", Handle: "_i1", OldID: "I0007", NewID: "X2"},
+				{Type: "person", Handle: "_i4", NewID: "X3"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := reorderSample()
+			got, err := ReorderIDs(db, tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+
+			ids := make(map[string]string)
+			walkObjects(db, func(o primaryObject) {
+				if o.id != nil {
+					ids[o.handle] = strval(*o.id)
+				}
+			})
+			for _, c := range got {
+				if ids[c.Handle] != c.NewID {
+					t.Errorf("%s: got ID %s, want %s", c.Handle, ids[c.Handle], c.NewID)
+				}
+			}
+			for _, p := range db.People.Person {
+				changed := slices.ContainsFunc(got, func(c IDChange) bool { return c.Handle == p.Handle })
+				if changed != (p.Change != "") {
+					t.Errorf("%s: change time %q, changed %v", p.Handle, p.Change, changed)
+				}
+			}
+		})
+	}
+}
+
+func TestReorderIDsInvalidFormat(t *testing.T) {
+	testCases := []struct {
+		format  string
+		wantErr bool
+	}{
+		{format: "I%04d"},
+		{format: "I-%d-x"},
+		{format: "%05x"},
+		{format: "I", wantErr: true},
+		{format: "I%d%d", wantErr: true},
+		{format: "I%s", wantErr: true},
+		{format: "I%04.1f", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			db := reorderSample()
+			_, err := ReorderIDs(db, ReorderOptions{IDFormats: map[string]string{"person": tc.format}})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tc.wantErr)
+			}
+			if err != nil {
+				if diff := cmp.Diff(reorderSample(), db); diff != "" {
+					t.Errorf("database was modified (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
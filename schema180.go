@@ -53,120 +53,144 @@ type Database180 struct {
 }
 
 type DNATests struct {
-	DNATest []DNATest `xml:"dnatest,omitempty"`
+	DNATest []DNATest `xml:"dnatest,omitempty" json:"dnatests,omitempty"`
 }
 
 type DNAMatches struct {
-	DNAMatch []DNAMatch `xml:"dnamatch,omitempty"`
+	DNAMatch []DNAMatch `xml:"dnamatch,omitempty" json:"dnamatches,omitempty"`
+}
+
+func (c *DNATests) list() []DNATest {
+	if c == nil {
+		return nil
+	}
+	return c.DNATest
+}
+
+func (c *DNAMatches) list() []DNAMatch {
+	if c == nil {
+		return nil
+	}
+	return c.DNAMatch
 }
 
 // PersonLink is a reference element carrying only an hlink attribute,
 // used wherever a child element links to a person (e.g. in dnatest and shared_ancestor).
 type PersonLink struct {
-	Hlink string `xml:"hlink,attr"`
+	Hlink string `xml:"hlink,attr" json:"ref"`
 }
 
 // SubjectTest is the <subject_test hlink="..."/> element in a dnamatch.
 type SubjectTest struct {
-	Hlink string `xml:"hlink,attr"`
+	Hlink string `xml:"hlink,attr" json:"ref"`
 }
 
 // MatchTest is the <match_test hlink="..."/> element in a dnamatch.
 type MatchTest struct {
-	Hlink string `xml:"hlink,attr"`
+	Hlink string `xml:"hlink,attr" json:"ref"`
 }
 
 // DNATest represents one DNA kit for one person at one provider.
 type DNATest struct {
-	ID           *string       `xml:"id,attr,omitempty"`
-	Handle       string        `xml:"handle,attr"`
-	Priv         *bool         `xml:"priv,attr,omitempty"`
-	Change       string        `xml:"change,attr"`
-	Person       *PersonLink   `xml:"person,omitempty"`
-	AccountName  *string       `xml:"account_name,omitempty"`
-	Provider     *string       `xml:"provider,omitempty"`
-	KitID        *string       `xml:"kit_id,omitempty"`
-	TestType     *string       `xml:"test_type,omitempty"`
-	GenomeBuild  *string       `xml:"genome_build,omitempty"`
-	Daterange    *Daterange    `xml:"daterange,omitempty"`
-	Datespan     *Datespan     `xml:"datespan,omitempty"`
-	Dateval      *Dateval      `xml:"dateval,omitempty"`
-	Datestr      *Datestr      `xml:"datestr,omitempty"`
-	YHaplogroup  *string       `xml:"y_haplogroup,omitempty"`
-	MtHaplogroup *string       `xml:"mt_haplogroup,omitempty"`
-	Attribute    []Attribute   `xml:"attribute,omitempty"`
-	Objref       []Objref      `xml:"objref,omitempty"`
-	Noteref      []Noteref     `xml:"noteref,omitempty"`
-	Citationref  []Citationref `xml:"citationref,omitempty"`
-	Tagref       []Tagref      `xml:"tagref,omitempty"`
+	ID           *string       `xml:"id,attr,omitempty" json:"id,omitempty"`
+	Handle       string        `xml:"handle,attr" json:"handle"`
+	Priv         *bool         `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Change       string        `xml:"change,attr" json:"change"`
+	Person       *PersonLink   `xml:"person,omitempty" json:"person,omitempty"`
+	AccountName  *string       `xml:"account_name,omitempty" json:"account_name,omitempty"`
+	Provider     *string       `xml:"provider,omitempty" json:"provider,omitempty"`
+	KitID        *string       `xml:"kit_id,omitempty" json:"kit_id,omitempty"`
+	TestType     *string       `xml:"test_type,omitempty" json:"test_type,omitempty"`
+	GenomeBuild  *string       `xml:"genome_build,omitempty" json:"genome_build,omitempty"`
+	Daterange    *Daterange    `xml:"daterange,omitempty" json:"-"`
+	Datespan     *Datespan     `xml:"datespan,omitempty" json:"-"`
+	Dateval      *Dateval      `xml:"dateval,omitempty" json:"-"`
+	Datestr      *Datestr      `xml:"datestr,omitempty" json:"-"`
+	YHaplogroup  *string       `xml:"y_haplogroup,omitempty" json:"y_haplogroup,omitempty"`
+	MtHaplogroup *string       `xml:"mt_haplogroup,omitempty" json:"mt_haplogroup,omitempty"`
+	Attribute    []Attribute   `xml:"attribute,omitempty" json:"attributes,omitempty"`
+	Objref       []Objref      `xml:"objref,omitempty" json:"media_refs,omitempty"`
+	Noteref      []Noteref     `xml:"noteref,omitempty" json:"note_refs,omitempty"`
+	Citationref  []Citationref `xml:"citationref,omitempty" json:"citation_refs,omitempty"`
+	Tagref       []Tagref      `xml:"tagref,omitempty" json:"tag_refs,omitempty"`
+}
+
+// Date returns the date of the DNA test.
+func (t *DNATest) Date() Date {
+	return Date{Daterange: t.Daterange, Datespan: t.Datespan, Dateval: t.Dateval, Datestr: t.Datestr}
+}
+
+// SetDate replaces the date of the DNA test.
+func (t *DNATest) SetDate(d Date) {
+	t.Daterange, t.Datespan, t.Dateval, t.Datestr = d.Daterange, d.Datespan, d.Dateval, d.Datestr
 }
 
 // DNAMatch represents a pairwise DNA match between two kits.
 type DNAMatch struct {
-	ID                    *string               `xml:"id,attr,omitempty"`
-	Handle                string                `xml:"handle,attr"`
-	Priv                  *bool                 `xml:"priv,attr,omitempty"`
-	Change                string                `xml:"change,attr"`
-	SubjectTest           *SubjectTest          `xml:"subject_test,omitempty"`
-	MatchTest             *MatchTest            `xml:"match_test,omitempty"`
-	SharedCM              *SharedCM             `xml:"shared_cm,omitempty"`
-	PercentShared         *PercentShared        `xml:"percent_shared,omitempty"`
-	SegmentCount          *SegmentCount         `xml:"segment_count,omitempty"`
-	LargestSegmentCM      *LargestSegmentCM     `xml:"largest_segment_cm,omitempty"`
-	PredictedRelationship *string               `xml:"predicted_relationship,omitempty"`
-	PredictedGenerations  *PredictedGenerations `xml:"predicted_generations,omitempty"`
-	SharedAncestor        []SharedAncestor      `xml:"shared_ancestor,omitempty"`
-	DNASegment            []DNASegment          `xml:"dna_segment,omitempty"`
-	Attribute             []Attribute           `xml:"attribute,omitempty"`
-	Objref                []Objref              `xml:"objref,omitempty"`
-	Noteref               []Noteref             `xml:"noteref,omitempty"`
-	Citationref           []Citationref         `xml:"citationref,omitempty"`
-	Tagref                []Tagref              `xml:"tagref,omitempty"`
+	ID                    *string               `xml:"id,attr,omitempty" json:"id,omitempty"`
+	Handle                string                `xml:"handle,attr" json:"handle"`
+	Priv                  *bool                 `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Change                string                `xml:"change,attr" json:"change"`
+	SubjectTest           *SubjectTest          `xml:"subject_test,omitempty" json:"subject_test,omitempty"`
+	MatchTest             *MatchTest            `xml:"match_test,omitempty" json:"match_test,omitempty"`
+	SharedCM              *SharedCM             `xml:"shared_cm,omitempty" json:"shared_cm,omitempty"`
+	PercentShared         *PercentShared        `xml:"percent_shared,omitempty" json:"percent_shared,omitempty"`
+	SegmentCount          *SegmentCount         `xml:"segment_count,omitempty" json:"segment_count,omitempty"`
+	LargestSegmentCM      *LargestSegmentCM     `xml:"largest_segment_cm,omitempty" json:"largest_segment_cm,omitempty"`
+	PredictedRelationship *string               `xml:"predicted_relationship,omitempty" json:"predicted_relationship,omitempty"`
+	PredictedGenerations  *PredictedGenerations `xml:"predicted_generations,omitempty" json:"predicted_generations,omitempty"`
+	SharedAncestor        []SharedAncestor      `xml:"shared_ancestor,omitempty" json:"shared_ancestors,omitempty"`
+	DNASegment            []DNASegment          `xml:"dna_segment,omitempty" json:"segments,omitempty"`
+	Attribute             []Attribute           `xml:"attribute,omitempty" json:"attributes,omitempty"`
+	Objref                []Objref              `xml:"objref,omitempty" json:"media_refs,omitempty"`
+	Noteref               []Noteref             `xml:"noteref,omitempty" json:"note_refs,omitempty"`
+	Citationref           []Citationref         `xml:"citationref,omitempty" json:"citation_refs,omitempty"`
+	Tagref                []Tagref              `xml:"tagref,omitempty" json:"tag_refs,omitempty"`
 }
 
 // SharedCM holds the total shared centimorgans as a val attribute.
 type SharedCM struct {
-	Val float64 `xml:"val,attr"`
+	Val float64 `xml:"val,attr" json:"val"`
 }
 
 // PercentShared holds the percentage of genome shared as a val attribute.
 type PercentShared struct {
-	Val float64 `xml:"val,attr"`
+	Val float64 `xml:"val,attr" json:"val"`
 }
 
 // SegmentCount holds the number of shared segments as a val attribute.
 type SegmentCount struct {
-	Val int `xml:"val,attr"`
+	Val int `xml:"val,attr" json:"val"`
 }
 
 // LargestSegmentCM holds the largest segment size in cM as a val attribute.
 type LargestSegmentCM struct {
-	Val float64 `xml:"val,attr"`
+	Val float64 `xml:"val,attr" json:"val"`
 }
 
 // PredictedGenerations holds the estimated generations to MRCA as a val attribute.
 type PredictedGenerations struct {
-	Val float64 `xml:"val,attr"`
+	Val float64 `xml:"val,attr" json:"val"`
 }
 
 // SharedAncestor records a proposed or confirmed MRCA for a DNAMatch.
 type SharedAncestor struct {
-	Confidence  string        `xml:"confidence,attr"`
-	Description *string       `xml:"description,omitempty"`
-	Person      *PersonLink   `xml:"person,omitempty"`
-	Noteref     []Noteref     `xml:"noteref,omitempty"`
-	Citationref []Citationref `xml:"citationref,omitempty"`
+	Confidence  string        `xml:"confidence,attr" json:"confidence"`
+	Description *string       `xml:"description,omitempty" json:"description,omitempty"`
+	Person      *PersonLink   `xml:"person,omitempty" json:"person,omitempty"`
+	Noteref     []Noteref     `xml:"noteref,omitempty" json:"note_refs,omitempty"`
+	Citationref []Citationref `xml:"citationref,omitempty" json:"citation_refs,omitempty"`
 }
 
 // DNASegment is a single shared chromosomal segment within a DNAMatch.
 type DNASegment struct {
-	Chromosome string  `xml:"chromosome,attr"`
-	StartBP    int     `xml:"start_bp,attr"`
-	EndBP      int     `xml:"end_bp,attr"`
-	SharedCM   float64 `xml:"shared_cm,attr"`
-	SNPCount   int     `xml:"snp_count,attr"`
-	Phase      int     `xml:"phase,attr"`
-	IBDState   *int    `xml:"ibd_state,attr,omitempty"`
-	StartRSID  *string `xml:"start_rsid,attr,omitempty"`
-	EndRSID    *string `xml:"end_rsid,attr,omitempty"`
+	Chromosome string  `xml:"chromosome,attr" json:"chromosome"`
+	StartBP    int     `xml:"start_bp,attr" json:"start_bp"`
+	EndBP      int     `xml:"end_bp,attr" json:"end_bp"`
+	SharedCM   float64 `xml:"shared_cm,attr" json:"shared_cm"`
+	SNPCount   int     `xml:"snp_count,attr" json:"snp_count"`
+	Phase      int     `xml:"phase,attr" json:"phase"`
+	IBDState   *int    `xml:"ibd_state,attr,omitempty" json:"ibd_state,omitempty"`
+	StartRSID  *string `xml:"start_rsid,attr,omitempty" json:"start_rsid,omitempty"`
+	EndRSID    *string `xml:"end_rsid,attr,omitempty" json:"end_rsid,omitempty"`
 }
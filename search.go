@@ -0,0 +1,376 @@
+package grampsxml
+
+import (
+	"cmp"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchIndex is an in-memory full-text index over the text in a database:
+// note text, the parts of people's names, place names, source titles and
+// authors, citation pages, event descriptions, attribute values and URL
+// descriptions. Words are matched ignoring case and diacritics, so that
+// "zielinski" finds "Zieliński". The index does not change when the database
+// does.
+type SearchIndex struct {
+	fields   []searchField
+	postings map[string][]searchPosting // by term
+	terms    []string                   // sorted
+	avgLen   float64
+}
+
+// searchField is a piece of text in an object.
+type searchField struct {
+	object ObjectRef
+	field  string
+	text   string
+	tokens []searchToken
+}
+
+// searchPosting records the positions of a term in a field.
+type searchPosting struct {
+	field     int
+	positions []int
+}
+
+// SearchResult is an object found by a search.
+type SearchResult struct {
+	Object ObjectRef
+
+	// Score is higher for better matches.
+	Score float64
+
+	// Matches lists the fields of the object that matched, in the order they
+	// were indexed.
+	Matches []SearchMatch
+}
+
+// SearchMatch is a field that matched a search.
+type SearchMatch struct {
+	// Field names the field, such as "Note.Text" or "Name.First".
+	Field string
+
+	// Snippet is the part of the text of the field around the first match.
+	Snippet string
+
+	// Spans are the byte offsets in Snippet of the start and end of each
+	// matching word.
+	Spans [][2]int
+}
+
+// NewSearchIndex indexes the text in db.
+func NewSearchIndex(db *Database) *SearchIndex {
+	si := &SearchIndex{postings: make(map[string][]searchPosting)}
+	walkObjects(db, func(o primaryObject) {
+		ref := objectRef(o)
+		add := func(field string, text *string) {
+			if text != nil && *text != "" {
+				si.add(ref, field, *text)
+			}
+		}
+
+		switch v := o.value.(type) {
+		case *Person:
+			for _, n := range v.Name {
+				add("Name.First", n.First)
+				for _, s := range n.Surname {
+					add("Surname.Surname", &s.Surname)
+				}
+				add("Name.Call", n.Call)
+				add("Name.Nick", n.Nick)
+				add("Name.Familynick", n.Familynick)
+				add("Name.Title", n.Title)
+				add("Name.Suffix", n.Suffix)
+			}
+		case *Placeobj:
+			for _, n := range v.Pname {
+				add("Pname.Value", &n.Value)
+			}
+		case *Source:
+			add("Source.Stitle", v.Stitle)
+			add("Source.Sauthor", v.Sauthor)
+		case *Citation:
+			add("Citation.Page", v.Page)
+		case *Event:
+			add("Event.Description", v.Description)
+		case *Note:
+			add("Note.Text", &v.Text)
+		}
+
+		rv := reflect.ValueOf(o.value).Elem()
+		if f := rv.FieldByName("Attribute"); f.IsValid() {
+			for _, at := range f.Interface().([]Attribute) {
+				add("Attribute.Value", &at.Value)
+			}
+		}
+		if f := rv.FieldByName("Url"); f.IsValid() {
+			for _, u := range f.Interface().([]Url) {
+				add("Url.Description", u.Description)
+			}
+		}
+	})
+
+	for term := range si.postings {
+		si.terms = append(si.terms, term)
+	}
+	slices.Sort(si.terms)
+	total := 0
+	for _, f := range si.fields {
+		total += len(f.tokens)
+	}
+	if len(si.fields) > 0 {
+		si.avgLen = float64(total) / float64(len(si.fields))
+	}
+	return si
+}
+
+func (si *SearchIndex) add(ref ObjectRef, field, text string) {
+	tokens := searchTokens(text)
+	if len(tokens) == 0 {
+		return
+	}
+	n := len(si.fields)
+	si.fields = append(si.fields, searchField{object: ref, field: field, text: text, tokens: tokens})
+	for i, t := range tokens {
+		ps := si.postings[t.term]
+		if len(ps) == 0 || ps[len(ps)-1].field != n {
+			ps = append(ps, searchPosting{field: n})
+		}
+		ps[len(ps)-1].positions = append(ps[len(ps)-1].positions, i)
+		si.postings[t.term] = ps
+	}
+}
+
+// Search returns the objects whose text contains every word in the query,
+// best match first, up to limit results if limit is positive. Words in
+// double quotes must appear together as a phrase, and a word ending in "*"
+// matches any word that begins with it.
+func (si *SearchIndex) Search(query string, limit int) []SearchResult {
+	clauses := parseSearchQuery(query)
+	if len(clauses) == 0 {
+		return nil
+	}
+
+	type hit struct {
+		clauses int // number matched
+		score   float64
+		spans   map[int][][2]int // token spans, by field
+	}
+	hits := make(map[string]*hit) // by object type and handle
+	var order []string
+	for _, cl := range clauses {
+		matches := si.match(cl)
+		if len(matches) == 0 {
+			return nil
+		}
+		idf := math.Log(1 + (float64(len(si.fields))-float64(len(matches))+0.5)/(float64(len(matches))+0.5))
+		seen := make(map[string]bool)
+		for field, spans := range matches {
+			f := &si.fields[field]
+			key := f.object.Type + "\x00" + f.object.Handle
+			h := hits[key]
+			if h == nil {
+				h = &hit{spans: make(map[int][][2]int)}
+				hits[key] = h
+				order = append(order, key)
+			}
+			if !seen[key] {
+				seen[key] = true
+				h.clauses++
+			}
+			h.spans[field] = append(h.spans[field], spans...)
+
+			// BM25 with the usual parameters, treating each field as a
+			// document.
+			const k1, b = 1.2, 0.75
+			tf := float64(len(spans))
+			norm := 1 - b + b*float64(len(f.tokens))/si.avgLen
+			h.score += idf * tf * (k1 + 1) / (tf + k1*norm)
+		}
+	}
+
+	var results []SearchResult
+	first := make(map[string]int)
+	for _, key := range order {
+		h := hits[key]
+		if h.clauses != len(clauses) {
+			continue
+		}
+		fields := make([]int, 0, len(h.spans))
+		for field := range h.spans {
+			fields = append(fields, field)
+		}
+		slices.Sort(fields)
+		r := SearchResult{Object: si.fields[fields[0]].object, Score: h.score}
+		for _, field := range fields {
+			r.Matches = append(r.Matches, si.fields[field].snippet(h.spans[field]))
+		}
+		first[key] = fields[0]
+		results = append(results, r)
+	}
+	slices.SortStableFunc(results, func(a, b SearchResult) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(first[a.Object.Type+"\x00"+a.Object.Handle], first[b.Object.Type+"\x00"+b.Object.Handle]),
+		)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// searchClause is a word or phrase to search for. If prefix is set the last
+// word matches any word that begins with it.
+type searchClause struct {
+	terms  []string
+	prefix bool
+}
+
+// parseSearchQuery splits a query into words and quoted phrases.
+func parseSearchQuery(query string) []searchClause {
+	var clauses []searchClause
+	addClause := func(s string, phrase bool) {
+		var words []string
+		if phrase {
+			words = []string{s}
+		} else {
+			words = strings.Fields(s)
+		}
+		for _, w := range words {
+			var cl searchClause
+			for _, t := range searchTokens(w) {
+				cl.terms = append(cl.terms, t.term)
+			}
+			if len(cl.terms) == 0 {
+				continue
+			}
+			cl.prefix = !phrase && strings.HasSuffix(w, "*")
+			clauses = append(clauses, cl)
+		}
+	}
+	for i, part := range strings.Split(query, `"`) {
+		addClause(part, i%2 == 1)
+	}
+	return clauses
+}
+
+// match returns the token spans at which cl appears in each field that
+// contains it.
+func (si *SearchIndex) match(cl searchClause) map[int][][2]int {
+	last := len(cl.terms) - 1
+	lastTerms := []string{cl.terms[last]}
+	if cl.prefix {
+		lastTerms = nil
+		i, _ := slices.BinarySearch(si.terms, cl.terms[last])
+		for ; i < len(si.terms) && strings.HasPrefix(si.terms[i], cl.terms[last]); i++ {
+			lastTerms = append(lastTerms, si.terms[i])
+		}
+	}
+
+	matches := make(map[int][][2]int)
+	for _, term := range lastTerms {
+		for _, p := range si.postings[term] {
+			tokens := si.fields[p.field].tokens
+			for _, end := range p.positions {
+				start := end - last
+				if start < 0 {
+					continue
+				}
+				ok := true
+				for j := range last {
+					if tokens[start+j].term != cl.terms[j] {
+						ok = false
+						break
+					}
+				}
+				if ok {
+					matches[p.field] = append(matches[p.field], [2]int{start, end})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// searchSnippetWords is the number of words shown either side of a match in
+// a snippet.
+const searchSnippetWords = 8
+
+// snippet returns the text of f around the first of the given token spans.
+func (f *searchField) snippet(spans [][2]int) SearchMatch {
+	slices.SortFunc(spans, func(a, b [2]int) int { return cmp.Compare(a[0], b[0]) })
+	first := max(spans[0][0]-searchSnippetWords, 0)
+	last := min(spans[0][1]+searchSnippetWords, len(f.tokens)-1)
+	start, end := f.tokens[first].start, f.tokens[last].end
+	if first == 0 {
+		start = 0
+	}
+	if last == len(f.tokens)-1 {
+		end = len(f.text)
+	}
+
+	var prefix, suffix string
+	if start > 0 {
+		prefix = "…"
+	}
+	if end < len(f.text) {
+		suffix = "…"
+	}
+	m := SearchMatch{Field: f.field, Snippet: prefix + strings.TrimSpace(f.text[start:end]) + suffix}
+	offset := len(prefix) - start - (len(f.text[start:end]) - len(strings.TrimLeftFunc(f.text[start:end], unicode.IsSpace)))
+	for _, s := range spans {
+		if s[0] < first || s[1] > last {
+			continue
+		}
+		m.Spans = append(m.Spans, [2]int{f.tokens[s[0]].start + offset, f.tokens[s[1]].end + offset})
+	}
+	return m
+}
+
+// searchToken is a word in a piece of text and its folded form.
+type searchToken struct {
+	term       string
+	start, end int // byte offsets
+}
+
+// searchTokens splits text into words, which are runs of letters, digits
+// and combining marks. Each Han, Hiragana or Katakana character is a word on
+// its own, since those scripts do not separate words with spaces. Words are
+// folded to lower case without diacritics.
+func searchTokens(text string) []searchToken {
+	var tokens []searchToken
+	start := -1
+	var b strings.Builder
+	flush := func(end int) {
+		if start >= 0 && b.Len() > 0 {
+			tokens = append(tokens, searchToken{term: b.String(), start: start, end: end})
+		}
+		start = -1
+		b.Reset()
+	}
+	for i, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			flush(i)
+			start = i
+			b.WriteRune(r)
+			flush(i + utf8.RuneLen(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if start < 0 {
+				start = i
+			}
+			b.WriteString(foldRune(r))
+		case unicode.Is(unicode.Mn, r) && start >= 0:
+			// Combining marks are part of the word but are dropped.
+		default:
+			flush(i)
+		}
+	}
+	flush(len(text))
+	return tokens
+}
@@ -0,0 +1,149 @@
+package grampsxml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestSearchTokens(t *testing.T) {
+	testCases := []struct {
+		text string
+		want []string
+	}{
+		{text: "Miller at Greenfield.", want: []string{"miller", "at", "greenfield"}},
+		{text: "ZIELIŃSKI, Anna", want: []string{"zielinski", "anna"}},
+		{text: "Große Straße", want: []string{"grosse", "strasse"}},
+		{text: "Ærøskøbing", want: []string{"aeroskobing"}},
+		{text: "Ǐ Ðorđe Ĳssel", want: []string{"i", "dorde", "ijssel"}},
+		{text: "Zieliński", want: []string{"zielinski"}},
+		{text: "O'Brien 1855-06-21", want: []string{"o", "brien", "1855", "06", "21"}},
+		{text: "東京 Tokyo", want: []string{"東", "京", "tokyo"}},
+		{text: "Потылицин", want: []string{"потылицин"}},
+		{text: " -- ", want: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			var got []string
+			for _, tok := range searchTokens(tc.text) {
+				got = append(got, tok.term)
+				if tok.start < 0 || tok.end > len(tc.text) || tok.start >= tok.end {
+					t.Errorf("token %q has invalid offsets %d-%d", tok.term, tok.start, tok.end)
+				}
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSearchIndexSearch(t *testing.T) {
+	var db Database
+	if err := ReadCSV(strings.NewReader(csvSample), &db); err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	db.Notes.Note = append(db.Notes.Note, Note{
+		Handle: "_n1",
+		ID:     new("N0100"),
+		Text:   "In 1871 the family lived beside the mill at Greenfield, where Lewis worked as a miller for his uncle until the mill closed.",
+	})
+	db.Events.Event[1].Description = new("Drowned in the mill race")
+	si := NewSearchIndex(&db)
+
+	ids := func(rs []SearchResult) []string {
+		var got []string
+		for _, r := range rs {
+			got = append(got, r.Object.ID)
+		}
+		return got
+	}
+
+	testCases := []struct {
+		query string
+		want  []string
+	}{
+		{query: "greenfield", want: []string{"P0000", "N0000", "N0100"}},
+		{query: "GREENFIELD mill", want: []string{"N0100"}},
+		{query: "the mill at Greenfield", want: []string{"N0100"}},
+		{query: `"mill at greenfield"`, want: []string{"N0100"}},
+		{query: `"greenfield mill"`, want: nil},
+		{query: "mill", want: []string{"E0001", "N0100"}},
+		{query: "mill*", want: []string{"N0000", "E0001", "N0100"}},
+		{query: "zielinski", want: []string{"I0001"}},
+		{query: "garn*", want: []string{"I0000", "I0002"}},
+		{query: "parish register", want: []string{"S0000"}},
+		{query: "nowhere", want: nil},
+		{query: "", want: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			got := ids(si.Search(tc.query, 0))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if got := ids(si.Search("greenfield", 2)); len(got) != 2 {
+		t.Errorf("got %d results with limit 2", len(got))
+	}
+}
+
+func TestSearchIndexSnippets(t *testing.T) {
+	var db Database
+	if err := ReadCSV(strings.NewReader(csvSample), &db); err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	db.Notes.Note = []Note{{
+		Handle: "_n1",
+		ID:     new("N0100"),
+		Text:   "In 1871 the family lived beside the mill at Greenfield, where Lewis worked as a miller for his uncle until the mill closed in 1890.",
+	}}
+	db.People.Person[0].Attribute = []Attribute{{Type: "Occupation", Value: "Miller"}}
+	si := NewSearchIndex(&db)
+
+	got := si.Search("mill*", 0)
+	want := []SearchResult{
+		{
+			Object: ObjectRef{Type: targetPerson, ID: "I0000"},
+			Matches: []SearchMatch{
+				{Field: "Attribute.Value", Snippet: "Miller", Spans: [][2]int{{0, 6}}},
+			},
+		},
+		{
+			Object: ObjectRef{Type: targetNote, ID: "N0100"},
+			Matches: []SearchMatch{
+				{
+					Field:   "Note.Text",
+					Snippet: "In 1871 the family lived beside the mill at Greenfield, where Lewis worked as a miller…",
+					Spans:   [][2]int{{36, 40}, {80, 86}},
+				},
+			},
+		},
+	}
+	opts := cmp.Options{
+		cmpopts.IgnoreFields(SearchResult{}, "Score"),
+		cmpopts.IgnoreFields(ObjectRef{}, "Handle"),
+	}
+	if diff := cmp.Diff(want, got, opts); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if got := si.Search("1890", 0); len(got) != 1 || got[0].Matches[0].Snippet != "…for his uncle until the mill closed in 1890." {
+		t.Errorf("got %+v, wanted snippet ending the note", got)
+	}
+
+	for _, r := range got {
+		for _, m := range r.Matches {
+			for _, s := range m.Spans {
+				if w := strings.ToLower(m.Snippet[s[0]:s[1]]); !strings.HasPrefix(w, "mill") {
+					t.Errorf("span %v of %q is %q", s, m.Snippet, w)
+				}
+			}
+		}
+	}
+}
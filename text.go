@@ -0,0 +1,147 @@
+package grampsxml
+
+import (
+	"strings"
+	"unicode"
+)
+
+// foldLetters maps accented and ligature letters to the unaccented Latin
+// letters they are compared as.
+var foldLetters = func() map[rune]string {
+	m := make(map[rune]string)
+	for base, letters := range map[string]string{
+		"a": "àáâãäåāăąǎ",
+		"c": "çćĉċč",
+		"d": "ďđð",
+		"e": "èéêëēĕėęě",
+		"g": "ĝğġģ",
+		"h": "ĥħ",
+		"i": "ìíîïĩīĭįıǐ",
+		"j": "ĵ",
+		"k": "ķ",
+		"l": "ĺļľŀł",
+		"n": "ñńņňŉ",
+		"o": "òóôõöøōŏőǒ",
+		"r": "ŕŗř",
+		"s": "śŝşšș",
+		"t": "ţťŧț",
+		"u": "ùúûüũūŭůűųǔ",
+		"w": "ŵ",
+		"y": "ýÿŷ",
+		"z": "źżž",
+	} {
+		for _, r := range letters {
+			m[r] = base
+		}
+	}
+	m['ß'] = "ss"
+	m['æ'] = "ae"
+	m['œ'] = "oe"
+	m['þ'] = "th"
+	m['ĳ'] = "ij"
+	return m
+}()
+
+// foldText returns s in lower case with diacritics removed and ligatures
+// expanded, so that "Łódź" and "lodz" fold to the same text.
+func foldText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteString(foldRune(r))
+	}
+	return b.String()
+}
+
+// foldRune returns r in lower case with diacritics removed, or the letters
+// it expands to if it is a ligature.
+func foldRune(r rune) string {
+	r = unicode.ToLower(r)
+	if f, ok := foldLetters[r]; ok {
+		return f
+	}
+	return string(r)
+}
+
+// normalizeText folds s with [foldText] and replaces each run of characters
+// other than letters and digits with a single space.
+func normalizeText(s string) string {
+	return strings.Join(strings.FieldsFunc(foldText(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// similarity returns a measure of how alike a and b are, between 0 for
+// entirely different strings and 1 for equal ones, based on the edit
+// distance between them.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	n := max(len(ra), len(rb))
+	if n == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(ra, rb))/float64(n)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range a {
+		cur[0] = i + 1
+		for j := range b {
+			cost := 1
+			if a[i] == b[j] {
+				cost = 0
+			}
+			cur[j+1] = min(prev[j+1]+1, cur[j]+1, prev[j]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// soundex returns the American Soundex code of s, such as "R163" for both
+// "Robert" and "Rupert", after folding it with [foldText]. Characters other
+// than the letters a to z are ignored, and the code of a string with no
+// such letters is empty.
+func soundex(s string) string {
+	const codes = "01230120022455012623010202" // for a to z
+	var b []byte
+	var last byte
+	for _, r := range foldText(s) {
+		if r < 'a' || r > 'z' {
+			continue
+		}
+		c := codes[r-'a']
+		if len(b) == 0 {
+			b = append(b, byte(r)-'a'+'A')
+			last = c
+			continue
+		}
+		switch {
+		case r == 'h' || r == 'w':
+			// h and w do not separate letters with the same code.
+		case c == '0':
+			last = 0
+		case c != last:
+			b = append(b, c)
+			last = c
+		}
+		if len(b) == 4 {
+			break
+		}
+	}
+	if len(b) == 0 {
+		return ""
+	}
+	for len(b) < 4 {
+		b = append(b, '0')
+	}
+	return string(b)
+}
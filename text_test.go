@@ -0,0 +1,66 @@
+package grampsxml
+
+import "testing"
+
+func TestNormalizeText(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want string
+	}{
+		{in: "Łódź", want: "lodz"},
+		{in: "Saint-Étienne", want: "saint etienne"},
+		{in: "  Großbritannien ", want: "grossbritannien"},
+		{in: "Ærøskøbing", want: "aeroskobing"},
+		{in: "Café (old)", want: "cafe old"},
+		{in: "Москва", want: "москва"},
+		{in: "IJsselmeer Ĳssel", want: "ijsselmeer ijssel"},
+		{in: "Ǐ Ðorđe", want: "i dorde"},
+	}
+	for _, tc := range testCases {
+		if got := normalizeText(tc.in); got != tc.want {
+			t.Errorf("normalizeText(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	testCases := []struct {
+		a, b string
+		want float64
+	}{
+		{a: "", b: "", want: 1},
+		{a: "paris", b: "paris", want: 1},
+		{a: "paris", b: "pariss", want: 1 - 1.0/6},
+		{a: "abc", b: "xyz", want: 0},
+		{a: "kitten", b: "sitting", want: 1 - 3.0/7},
+	}
+	for _, tc := range testCases {
+		if got := similarity(tc.a, tc.b); got != tc.want {
+			t.Errorf("similarity(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestSoundex(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want string
+	}{
+		{in: "Robert", want: "R163"},
+		{in: "Rupert", want: "R163"},
+		{in: "Rubin", want: "R150"},
+		{in: "Ashcraft", want: "A261"},
+		{in: "Ashcroft", want: "A261"},
+		{in: "Tymczak", want: "T522"},
+		{in: "Pfister", want: "P236"},
+		{in: "Honeyman", want: "H555"},
+		{in: "Müller", want: "M460"},
+		{in: "Lee", want: "L000"},
+		{in: "", want: ""},
+	}
+	for _, tc := range testCases {
+		if got := soundex(tc.in); got != tc.want {
+			t.Errorf("soundex(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package grampsxml
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// MergeConflict is a change made differently in both databases given to
+// [ThreeWayMerge].
+type MergeConflict struct {
+	// Type, Handle and ID identify the object, as in [ObjectDiff].
+	Type   string `json:"type"`
+	Handle string `json:"handle"`
+	ID     string `json:"id,omitempty"`
+
+	// Path names the conflicting field, as in [FieldChange]. It is empty if
+	// one side removed an object that the other modified.
+	Path string `json:"path,omitempty"`
+
+	// Base, Ours and Theirs hold the values of the field in each database,
+	// or copies of the whole objects if Path is empty. A value is nil if it
+	// is absent from that database.
+	Base   any `json:"base"`
+	Ours   any `json:"ours"`
+	Theirs any `json:"theirs"`
+}
+
+// String describes the conflict as text, such as
+//
+//	Person I0012: Name[0].First base "Jon", ours "John", theirs "Johnny"
+func (c MergeConflict) String() string {
+	name := objectTypeLabels[c.Type]
+	if name == "" {
+		name = c.Type
+	}
+	name += " " + cmp.Or(c.ID, c.Handle)
+	if c.Path == "" {
+		if c.Ours == nil {
+			return name + ": removed in ours, modified in theirs"
+		}
+		return name + ": modified in ours, removed in theirs"
+	}
+	return fmt.Sprintf("%s: %s base %s, ours %s, theirs %s", name, c.Path,
+		formatDiffValue(c.Base), formatDiffValue(c.Ours), formatDiffValue(c.Theirs))
+}
+
+// ThreeWayMerge merges the changes made to base in ours and in theirs,
+// returning a new database and the conflicts found. None of the databases
+// given are modified.
+//
+// Objects are matched by handle. Objects added on either side are added,
+// and objects removed on one side and unchanged on the other are removed.
+// For objects changed on both sides, each field takes the changed value
+// from whichever side changed it, and the latest of the two change times is
+// kept. Lists are merged element by element when neither side changed their
+// length, and are otherwise treated as a single value. A field changed to
+// different values on each side is a conflict and keeps the value from
+// ours, as does an object modified on one side and removed on the other: it
+// is kept if ours modified it and left out if ours removed it.
+//
+// The header, bookmarks and other data that are not primary objects are
+// taken from ours. References are not checked, so an object removed on one
+// side may still be referred to by an object added on the other.
+func ThreeWayMerge(base, ours, theirs *Database) (*Database, []MergeConflict) {
+	index := func(db *Database) map[string]primaryObject {
+		m := make(map[string]primaryObject)
+		walkObjects(db, func(o primaryObject) { m[o.objType+"\x00"+o.handle] = o })
+		return m
+	}
+	baseObjs, theirObjs := index(base), index(theirs)
+
+	result := clone(*ours)
+	m := &threeWayMerger{}
+	removed := make(map[string]bool)
+	ourObjs := make(map[string]bool)
+	walkObjects(&result, func(o primaryObject) {
+		key := o.objType + "\x00" + o.handle
+		ourObjs[key] = true
+		b, inBase := baseObjs[key]
+		t, inTheirs := theirObjs[key]
+		switch {
+		case inTheirs:
+			m.obj = o
+			var bv reflect.Value
+			if inBase {
+				bv = reflect.ValueOf(b.value).Elem()
+			} else {
+				bv = reflect.Zero(reflect.TypeOf(o.value).Elem())
+			}
+			m.merge("", bv, reflect.ValueOf(o.value).Elem(), reflect.ValueOf(t.value).Elem())
+		case inBase && reflect.DeepEqual(reflect.ValueOf(b.value).Elem().Interface(), reflect.ValueOf(o.value).Elem().Interface()):
+			removed[key] = true
+		case inBase:
+			m.conflicts = append(m.conflicts, newMergeConflict(o, "", clone(b.value), clone(o.value), nil))
+		}
+	})
+	removeObjects(&result, func(objType, handle string) bool { return removed[objType+"\x00"+handle] })
+
+	walkObjects(theirs, func(t primaryObject) {
+		key := t.objType + "\x00" + t.handle
+		if ourObjs[key] {
+			return
+		}
+		b, inBase := baseObjs[key]
+		switch {
+		case !inBase:
+			addObject(&result, clone(t.value))
+		case !reflect.DeepEqual(reflect.ValueOf(b.value).Elem().Interface(), reflect.ValueOf(t.value).Elem().Interface()):
+			m.conflicts = append(m.conflicts, newMergeConflict(t, "", clone(b.value), nil, clone(t.value)))
+		}
+	})
+	return &result, m.conflicts
+}
+
+func newMergeConflict(o primaryObject, path string, base, ours, theirs any) MergeConflict {
+	c := MergeConflict{Type: o.objType, Handle: o.handle, Path: path, Base: base, Ours: ours, Theirs: theirs}
+	if o.id != nil {
+		c.ID = strval(*o.id)
+	}
+	return c
+}
+
+// threeWayMerger merges the fields of a single object.
+type threeWayMerger struct {
+	obj       primaryObject
+	conflicts []MergeConflict
+}
+
+// merge merges the changes made to base in ours and theirs into ours, which
+// must be settable.
+func (m *threeWayMerger) merge(path string, base, ours, theirs reflect.Value) {
+	switch {
+	case reflect.DeepEqual(ours.Interface(), theirs.Interface()),
+		reflect.DeepEqual(base.Interface(), theirs.Interface()):
+		return
+	case reflect.DeepEqual(base.Interface(), ours.Interface()):
+		ours.Set(cloneValue(theirs))
+		return
+	}
+
+	switch ours.Kind() {
+	case reflect.Pointer:
+		if !base.IsNil() && !ours.IsNil() && !theirs.IsNil() {
+			m.merge(path, base.Elem(), ours.Elem(), theirs.Elem())
+			return
+		}
+	case reflect.Struct:
+		for i := range ours.NumField() {
+			name := ours.Type().Field(i).Name
+			if path != "" {
+				name = path + "." + name
+			}
+			m.merge(name, base.Field(i), ours.Field(i), theirs.Field(i))
+		}
+		return
+	case reflect.Slice:
+		if base.Len() == ours.Len() && ours.Len() == theirs.Len() {
+			for i := range ours.Len() {
+				m.merge(path+"["+strconv.Itoa(i)+"]", base.Index(i), ours.Index(i), theirs.Index(i))
+			}
+			return
+		}
+	case reflect.String:
+		if path == "Change" {
+			if parseChange(theirs.String()).After(parseChange(ours.String())) {
+				ours.SetString(theirs.String())
+			}
+			return
+		}
+	}
+	m.conflicts = append(m.conflicts, newMergeConflict(m.obj, path,
+		leafValue(derefValue(base)), leafValue(derefValue(ours)), leafValue(derefValue(theirs))))
+}
@@ -0,0 +1,168 @@
+package grampsxml
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func threeWaySample() *Database {
+	return &Database{
+		People: &People{
+			Person: []Person{
+				{
+					Handle: "_i1", ID: new("I0001"), Change: "100", Gender: "M",
+					Name: []Name{{First: new("Jon"), Surname: []Surname{{Surname: "Smith"}}}},
+				},
+				{Handle: "_i2", ID: new("I0002"), Change: "100", Gender: "F"},
+				{Handle: "_i3", ID: new("I0003"), Change: "100", Gender: "U"},
+				{Handle: "_i4", ID: new("I0004"), Change: "100", Gender: "U"},
+			},
+		},
+		Notes: &Notes{
+			Note: []Note{{Handle: "_n1", ID: new("N0001"), Change: "100", Type: "General", Text: "base"}},
+		},
+	}
+}
+
+func TestThreeWayMerge(t *testing.T) {
+	base := threeWaySample()
+
+	ours := threeWaySample()
+	ours.People.Person[0].Change = "200"
+	ours.People.Person[0].Name[0].First = new("John")
+	ours.People.Person[1].Change = "200"
+	ours.People.Person[1].Gender = "M"
+	ours.People.Person = append(ours.People.Person[:2], ours.People.Person[3]) // remove _i3
+	ours.Notes.Note[0].Text = "ours"
+
+	theirs := threeWaySample()
+	theirs.People.Person[0].Change = "300"
+	theirs.People.Person[0].Name[0].Surname[0].Surname = "Smyth"
+	theirs.People.Person[1].Change = "300"
+	theirs.People.Person[1].Gender = "X"
+	theirs.People.Person[3].Change = "300"
+	theirs.People.Person[3].Gender = "F"
+	theirs.Notes.Note[0].Text = "theirs"
+	theirs.Events = &Events{Event: []Event{{Handle: "_e1", ID: new("E0001"), Type: new("Birth")}}}
+
+	got, conflicts := ThreeWayMerge(base, ours, theirs)
+
+	want := &Database{
+		People: &People{
+			Person: []Person{
+				{
+					Handle: "_i1", ID: new("I0001"), Change: "300", Gender: "M",
+					Name: []Name{{First: new("John"), Surname: []Surname{{Surname: "Smyth"}}}},
+				},
+				{Handle: "_i2", ID: new("I0002"), Change: "300", Gender: "M"},
+				{Handle: "_i4", ID: new("I0004"), Change: "300", Gender: "F"},
+			},
+		},
+		Notes: &Notes{
+			Note: []Note{{Handle: "_n1", ID: new("N0001"), Change: "100", Type: "General", Text: "ours"}},
+		},
+		Events: &Events{Event: []Event{{Handle: "_e1", ID: new("E0001"), Type: new("Birth")}}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("database mismatch (-want +got):\n%s", diff)
+	}
+
+	var text []string
+	for _, c := range conflicts {
+		text = append(text, c.String())
+	}
+	wantText := []string{
+		`Person I0002: Gender base "F", ours "M", theirs "X"`,
+		`Note N0001: Text base "base", ours "ours", theirs "theirs"`,
+	}
+	if diff := cmp.Diff(wantText, text); diff != "" {
+		t.Errorf("conflicts mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(threeWaySample(), base); diff != "" {
+		t.Errorf("base was modified (-want +got):\n%s", diff)
+	}
+}
+
+func TestThreeWayMergeRemoved(t *testing.T) {
+	base := threeWaySample()
+
+	ours := threeWaySample()
+	ours.People.Person[2].Gender = "F"
+
+	theirs := threeWaySample()
+	theirs.People.Person = theirs.People.Person[:2]
+	theirs.Notes = nil
+
+	got, conflicts := ThreeWayMerge(base, ours, theirs)
+
+	handles := []string{}
+	for _, p := range got.People.Person {
+		handles = append(handles, p.Handle)
+	}
+	if diff := cmp.Diff([]string{"_i1", "_i2", "_i3"}, handles); diff != "" {
+		t.Errorf("people mismatch (-want +got):\n%s", diff)
+	}
+	if len(got.Notes.Note) != 0 {
+		t.Errorf("note was not removed")
+	}
+	if len(conflicts) != 1 || conflicts[0].String() != "Person I0003: modified in ours, removed in theirs" {
+		t.Errorf("unexpected conflicts: %v", conflicts)
+	}
+}
+
+func TestThreeWayMergeRemovedInOurs(t *testing.T) {
+	base := threeWaySample()
+
+	ours := threeWaySample()
+	ours.People.Person = ours.People.Person[:2]
+
+	theirs := threeWaySample()
+	theirs.People.Person[2].Gender = "F"
+
+	got, conflicts := ThreeWayMerge(base, ours, theirs)
+
+	handles := []string{}
+	for _, p := range got.People.Person {
+		handles = append(handles, p.Handle)
+	}
+	if diff := cmp.Diff([]string{"_i1", "_i2"}, handles); diff != "" {
+		t.Errorf("people mismatch (-want +got):\n%s", diff)
+	}
+	if len(conflicts) != 1 || conflicts[0].String() != "Person I0003: removed in ours, modified in theirs" {
+		t.Errorf("unexpected conflicts: %v", conflicts)
+	}
+}
+
+func TestThreeWayMergeConflictsAreCopies(t *testing.T) {
+	base := threeWaySample()
+
+	ours := threeWaySample()
+	ours.People.Person[2].Gender = "F"
+	ours.People.Person = ours.People.Person[1:]
+
+	theirs := threeWaySample()
+	theirs.People.Person[0].Gender = "F"
+	theirs.People.Person = theirs.People.Person[:2]
+
+	_, conflicts := ThreeWayMerge(base, ours, theirs)
+	if len(conflicts) != 2 {
+		t.Fatalf("got %d conflicts, wanted 2: %v", len(conflicts), conflicts)
+	}
+	for _, c := range conflicts {
+		for _, v := range []any{c.Base, c.Ours, c.Theirs} {
+			if p, ok := v.(*Person); ok {
+				p.Gender = "X"
+			}
+		}
+	}
+
+	for name, db := range map[string]*Database{"base": base, "ours": ours, "theirs": theirs} {
+		for _, p := range db.People.Person {
+			if p.Gender == "X" {
+				t.Errorf("changing a conflict changed %s person %s", name, p.Handle)
+			}
+		}
+	}
+}
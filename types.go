@@ -1,509 +1,513 @@
 package grampsxml
 
 type Header struct {
-	Created    Created     `xml:"created"`
-	Researcher *Researcher `xml:"researcher,omitempty"`
-	Mediapath  *string     `xml:"mediapath,omitempty"`
+	Created    Created     `xml:"created" json:"created"`
+	Researcher *Researcher `xml:"researcher,omitempty" json:"researcher,omitempty"`
+	Mediapath  *string     `xml:"mediapath,omitempty" json:"mediapath,omitempty"`
 }
 
 type Created struct {
-	Date    string `xml:"date,attr"`
-	Version string `xml:"version,attr"`
+	Date    string `xml:"date,attr" json:"date"`
+	Version string `xml:"version,attr" json:"version"`
 }
 
 type Researcher struct {
-	Resname     *string `xml:"resname,omitempty"`
-	Resaddr     *string `xml:"resaddr,omitempty"`
-	Reslocality *string `xml:"reslocality,omitempty"`
-	Rescity     *string `xml:"rescity,omitempty"`
-	Resstate    *string `xml:"resstate,omitempty"`
-	Rescountry  *string `xml:"rescountry,omitempty"`
-	Respostal   *string `xml:"respostal,omitempty"`
-	Resphone    *string `xml:"resphone,omitempty"`
-	Resemail    *string `xml:"resemail,omitempty"`
+	Resname     *string `xml:"resname,omitempty" json:"name,omitempty"`
+	Resaddr     *string `xml:"resaddr,omitempty" json:"address,omitempty"`
+	Reslocality *string `xml:"reslocality,omitempty" json:"locality,omitempty"`
+	Rescity     *string `xml:"rescity,omitempty" json:"city,omitempty"`
+	Resstate    *string `xml:"resstate,omitempty" json:"state,omitempty"`
+	Rescountry  *string `xml:"rescountry,omitempty" json:"country,omitempty"`
+	Respostal   *string `xml:"respostal,omitempty" json:"postal,omitempty"`
+	Resphone    *string `xml:"resphone,omitempty" json:"phone,omitempty"`
+	Resemail    *string `xml:"resemail,omitempty" json:"email,omitempty"`
 }
 
 type People struct {
-	Default *string  `xml:"default,omitempty"`
-	Home    *string  `xml:"home,omitempty"`
-	Person  []Person `xml:"person,omitempty"`
+	Default *string  `xml:"default,omitempty" json:"default,omitempty"`
+	Home    *string  `xml:"home,omitempty" json:"home,omitempty"`
+	Person  []Person `xml:"person,omitempty" json:"people,omitempty"`
 }
 
 type Person struct {
-	ID          *string       `xml:"id,attr,omitempty"`
-	Handle      string        `xml:"handle,attr"`
-	Priv        *bool         `xml:"priv,attr,omitempty"`
-	Change      string        `xml:"change,attr"`
-	Gender      string        `xml:"gender"`
-	Name        []Name        `xml:"name,omitempty"`
-	Eventref    []Eventref    `xml:"eventref,omitempty"`
-	LdsOrd      []LdsOrd      `xml:"lds_ord,omitempty"`
-	Objref      []Objref      `xml:"objref,omitempty"`
-	Address     []Address     `xml:"address,omitempty"`
-	Attribute   []Attribute   `xml:"attribute,omitempty"`
-	Url         []Url         `xml:"url,omitempty"`
-	Childof     []Childof     `xml:"childof,omitempty"`
-	Parentin    []Parentin    `xml:"parentin,omitempty"`
-	Personref   []Personref   `xml:"personref,omitempty"`
-	Noteref     []Noteref     `xml:"noteref,omitempty"`
-	Citationref []Citationref `xml:"citationref,omitempty"`
-	Tagref      []Tagref      `xml:"tagref,omitempty"`
+	ID          *string       `xml:"id,attr,omitempty" json:"id,omitempty"`
+	Handle      string        `xml:"handle,attr" json:"handle"`
+	Priv        *bool         `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Change      string        `xml:"change,attr" json:"change"`
+	Gender      string        `xml:"gender" json:"gender"`
+	Name        []Name        `xml:"name,omitempty" json:"names,omitempty"`
+	Eventref    []Eventref    `xml:"eventref,omitempty" json:"event_refs,omitempty"`
+	LdsOrd      []LdsOrd      `xml:"lds_ord,omitempty" json:"lds_ords,omitempty"`
+	Objref      []Objref      `xml:"objref,omitempty" json:"media_refs,omitempty"`
+	Address     []Address     `xml:"address,omitempty" json:"addresses,omitempty"`
+	Attribute   []Attribute   `xml:"attribute,omitempty" json:"attributes,omitempty"`
+	Url         []Url         `xml:"url,omitempty" json:"urls,omitempty"`
+	Childof     []Childof     `xml:"childof,omitempty" json:"child_of,omitempty"`
+	Parentin    []Parentin    `xml:"parentin,omitempty" json:"parent_in,omitempty"`
+	Personref   []Personref   `xml:"personref,omitempty" json:"person_refs,omitempty"`
+	Noteref     []Noteref     `xml:"noteref,omitempty" json:"note_refs,omitempty"`
+	Citationref []Citationref `xml:"citationref,omitempty" json:"citation_refs,omitempty"`
+	Tagref      []Tagref      `xml:"tagref,omitempty" json:"tag_refs,omitempty"`
 }
 
 type Name struct {
-	Alt         *bool         `xml:"alt,attr,omitempty"`
-	Type        *string       `xml:"type,attr,omitempty"`
-	Priv        *bool         `xml:"priv,attr,omitempty"`
-	Sort        *string       `xml:"sort,attr,omitempty"`
-	Display     *string       `xml:"display,attr,omitempty"`
-	First       *string       `xml:"first,omitempty"`
-	Call        *string       `xml:"call,omitempty"`
-	Suffix      *string       `xml:"suffix,omitempty"`
-	Title       *string       `xml:"title,omitempty"`
-	Nick        *string       `xml:"nick,omitempty"`
-	Familynick  *string       `xml:"familynick,omitempty"`
-	Group       *string       `xml:"group,omitempty"`
-	Surname     []Surname     `xml:"surname"`
-	Daterange   *Daterange    `xml:"daterange,omitempty"`
-	Datespan    *Datespan     `xml:"datespan,omitempty"`
-	Dateval     *Dateval      `xml:"dateval,omitempty"`
-	Datestr     *Datestr      `xml:"datestr,omitempty"`
-	Noteref     []Noteref     `xml:"noteref,omitempty"`
-	Citationref []Citationref `xml:"citationref,omitempty"`
+	Alt         *bool         `xml:"alt,attr,omitempty" json:"alt,omitempty"`
+	Type        *string       `xml:"type,attr,omitempty" json:"type,omitempty"`
+	Priv        *bool         `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Sort        *string       `xml:"sort,attr,omitempty" json:"sort_as,omitempty"`
+	Display     *string       `xml:"display,attr,omitempty" json:"display_as,omitempty"`
+	First       *string       `xml:"first,omitempty" json:"first_name,omitempty"`
+	Call        *string       `xml:"call,omitempty" json:"call,omitempty"`
+	Suffix      *string       `xml:"suffix,omitempty" json:"suffix,omitempty"`
+	Title       *string       `xml:"title,omitempty" json:"title,omitempty"`
+	Nick        *string       `xml:"nick,omitempty" json:"nick,omitempty"`
+	Familynick  *string       `xml:"familynick,omitempty" json:"family_nick,omitempty"`
+	Group       *string       `xml:"group,omitempty" json:"group_as,omitempty"`
+	Surname     []Surname     `xml:"surname" json:"surnames,omitempty"`
+	Daterange   *Daterange    `xml:"daterange,omitempty" json:"-"`
+	Datespan    *Datespan     `xml:"datespan,omitempty" json:"-"`
+	Dateval     *Dateval      `xml:"dateval,omitempty" json:"-"`
+	Datestr     *Datestr      `xml:"datestr,omitempty" json:"-"`
+	Noteref     []Noteref     `xml:"noteref,omitempty" json:"note_refs,omitempty"`
+	Citationref []Citationref `xml:"citationref,omitempty" json:"citation_refs,omitempty"`
 }
 
 type Surname struct {
-	Prefix     *string `xml:"prefix,attr,omitempty"`
-	Prim       *bool   `xml:"prim,attr,omitempty"`
-	Derivation *string `xml:"derivation,attr,omitempty"`
-	Connector  *string `xml:"connector,attr,omitempty"`
-	Surname    string  `xml:",chardata"`
+	Prefix     *string `xml:"prefix,attr,omitempty" json:"prefix,omitempty"`
+	Prim       *bool   `xml:"prim,attr,omitempty" json:"primary,omitempty"`
+	Derivation *string `xml:"derivation,attr,omitempty" json:"derivation,omitempty"`
+	Connector  *string `xml:"connector,attr,omitempty" json:"connector,omitempty"`
+	Surname    string  `xml:",chardata" json:"surname"`
 }
 
 type Childof struct {
-	Hlink string `xml:"hlink,attr"`
+	Hlink string `xml:"hlink,attr" json:"ref"`
 }
 
 type Parentin struct {
-	Hlink string `xml:"hlink,attr"`
+	Hlink string `xml:"hlink,attr" json:"ref"`
 }
 
 type Personref struct {
-	Hlink       string        `xml:"hlink,attr"`
-	Priv        *bool         `xml:"priv,attr,omitempty"`
-	Rel         string        `xml:"rel,attr"`
-	Citationref []Citationref `xml:"citationref,omitempty"`
-	Noteref     []Noteref     `xml:"noteref,omitempty"`
+	Hlink       string        `xml:"hlink,attr" json:"ref"`
+	Priv        *bool         `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Rel         string        `xml:"rel,attr" json:"rel"`
+	Citationref []Citationref `xml:"citationref,omitempty" json:"citation_refs,omitempty"`
+	Noteref     []Noteref     `xml:"noteref,omitempty" json:"note_refs,omitempty"`
 }
 
 type Address struct {
-	Daterange   *Daterange    `xml:"daterange,omitempty"`
-	Datespan    *Datespan     `xml:"datespan,omitempty"`
-	Dateval     *Dateval      `xml:"dateval,omitempty"`
-	Datestr     *Datestr      `xml:"datestr,omitempty"`
-	Street      *string       `xml:"street,omitempty"`
-	Locality    *string       `xml:"locality,omitempty"`
-	City        *string       `xml:"city,omitempty"`
-	County      *string       `xml:"county,omitempty"`
-	State       *string       `xml:"state,omitempty"`
-	Country     *string       `xml:"country,omitempty"`
-	Postal      *string       `xml:"postal,omitempty"`
-	Phone       *string       `xml:"phone,omitempty"`
-	Noteref     []Noteref     `xml:"noteref,omitempty"`
-	Citationref []Citationref `xml:"citationref,omitempty"`
+	Daterange   *Daterange    `xml:"daterange,omitempty" json:"-"`
+	Datespan    *Datespan     `xml:"datespan,omitempty" json:"-"`
+	Dateval     *Dateval      `xml:"dateval,omitempty" json:"-"`
+	Datestr     *Datestr      `xml:"datestr,omitempty" json:"-"`
+	Street      *string       `xml:"street,omitempty" json:"street,omitempty"`
+	Locality    *string       `xml:"locality,omitempty" json:"locality,omitempty"`
+	City        *string       `xml:"city,omitempty" json:"city,omitempty"`
+	County      *string       `xml:"county,omitempty" json:"county,omitempty"`
+	State       *string       `xml:"state,omitempty" json:"state,omitempty"`
+	Country     *string       `xml:"country,omitempty" json:"country,omitempty"`
+	Postal      *string       `xml:"postal,omitempty" json:"postal,omitempty"`
+	Phone       *string       `xml:"phone,omitempty" json:"phone,omitempty"`
+	Noteref     []Noteref     `xml:"noteref,omitempty" json:"note_refs,omitempty"`
+	Citationref []Citationref `xml:"citationref,omitempty" json:"citation_refs,omitempty"`
 }
 
 type Families struct {
-	Family []Family `xml:"family,omitempty"`
+	Family []Family `xml:"family,omitempty" json:"families,omitempty"`
 }
 
 type Family struct {
-	ID          *string       `xml:"id,attr,omitempty"`
-	Handle      string        `xml:"handle,attr"`
-	Priv        *bool         `xml:"priv,attr,omitempty"`
-	Change      string        `xml:"change,attr"`
-	Rel         *Rel          `xml:"rel,omitempty"`
-	Father      *Father       `xml:"father,omitempty"`
-	Mother      *Mother       `xml:"mother,omitempty"`
-	Eventref    []Eventref    `xml:"eventref,omitempty"`
-	LdsOrd      []LdsOrd      `xml:"lds_ord,omitempty"`
-	Objref      []Objref      `xml:"objref,omitempty"`
-	Childref    []Childref    `xml:"childref,omitempty"`
-	Attribute   []Attribute   `xml:"attribute,omitempty"`
-	Noteref     []Noteref     `xml:"noteref,omitempty"`
-	Citationref []Citationref `xml:"citationref,omitempty"`
-	Tagref      []Tagref      `xml:"tagref,omitempty"`
+	ID          *string       `xml:"id,attr,omitempty" json:"id,omitempty"`
+	Handle      string        `xml:"handle,attr" json:"handle"`
+	Priv        *bool         `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Change      string        `xml:"change,attr" json:"change"`
+	Rel         *Rel          `xml:"rel,omitempty" json:"rel,omitempty"`
+	Father      *Father       `xml:"father,omitempty" json:"father,omitempty"`
+	Mother      *Mother       `xml:"mother,omitempty" json:"mother,omitempty"`
+	Eventref    []Eventref    `xml:"eventref,omitempty" json:"event_refs,omitempty"`
+	LdsOrd      []LdsOrd      `xml:"lds_ord,omitempty" json:"lds_ords,omitempty"`
+	Objref      []Objref      `xml:"objref,omitempty" json:"media_refs,omitempty"`
+	Childref    []Childref    `xml:"childref,omitempty" json:"child_refs,omitempty"`
+	Attribute   []Attribute   `xml:"attribute,omitempty" json:"attributes,omitempty"`
+	Noteref     []Noteref     `xml:"noteref,omitempty" json:"note_refs,omitempty"`
+	Citationref []Citationref `xml:"citationref,omitempty" json:"citation_refs,omitempty"`
+	Tagref      []Tagref      `xml:"tagref,omitempty" json:"tag_refs,omitempty"`
 }
 
 type Father struct {
-	Hlink string `xml:"hlink,attr"`
+	Hlink string `xml:"hlink,attr" json:"ref"`
 }
 
 type Mother struct {
-	Hlink string `xml:"hlink,attr"`
+	Hlink string `xml:"hlink,attr" json:"ref"`
 }
 
 type Childref struct {
-	Hlink string  `xml:"hlink,attr"`
-	Priv  *bool   `xml:"priv,attr,omitempty"`
-	Mrel  *string `xml:"mrel,attr,omitempty"`
-	Frel  *string `xml:"frel,attr,omitempty"`
+	Hlink string  `xml:"hlink,attr" json:"ref"`
+	Priv  *bool   `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Mrel  *string `xml:"mrel,attr,omitempty" json:"mother_rel,omitempty"`
+	Frel  *string `xml:"frel,attr,omitempty" json:"father_rel,omitempty"`
 }
 
 type Rel struct {
-	Type string `xml:"type,attr"`
+	Type string `xml:"type,attr" json:"type"`
 }
 
 type Events struct {
-	Event []Event `xml:"event,omitempty"`
+	Event []Event `xml:"event,omitempty" json:"events,omitempty"`
 }
 
 type Event struct {
-	ID          *string       `xml:"id,attr,omitempty"`
-	Handle      string        `xml:"handle,attr"`
-	Priv        *bool         `xml:"priv,attr,omitempty"`
-	Change      string        `xml:"change,attr"`
-	Type        *string       `xml:"type"`
-	Daterange   *Daterange    `xml:"daterange,omitempty"`
-	Datespan    *Datespan     `xml:"datespan,omitempty"`
-	Dateval     *Dateval      `xml:"dateval,omitempty"`
-	Datestr     *Datestr      `xml:"datestr,omitempty"`
-	Place       *Place        `xml:"place,omitempty"`
-	Cause       *string       `xml:"cause,omitempty"`
-	Description *string       `xml:"description,omitempty"`
-	Attribute   []Attribute   `xml:"attribute,omitempty"`
-	Noteref     []Noteref     `xml:"noteref,omitempty"`
-	Citationref []Citationref `xml:"citationref,omitempty"`
-	Objref      []Objref      `xml:"objref,omitempty"`
-	Tagref      []Tagref      `xml:"tagref,omitempty"`
+	ID          *string       `xml:"id,attr,omitempty" json:"id,omitempty"`
+	Handle      string        `xml:"handle,attr" json:"handle"`
+	Priv        *bool         `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Change      string        `xml:"change,attr" json:"change"`
+	Type        *string       `xml:"type" json:"type,omitempty"`
+	Daterange   *Daterange    `xml:"daterange,omitempty" json:"-"`
+	Datespan    *Datespan     `xml:"datespan,omitempty" json:"-"`
+	Dateval     *Dateval      `xml:"dateval,omitempty" json:"-"`
+	Datestr     *Datestr      `xml:"datestr,omitempty" json:"-"`
+	Place       *Place        `xml:"place,omitempty" json:"place,omitempty"`
+	Cause       *string       `xml:"cause,omitempty" json:"cause,omitempty"`
+	Description *string       `xml:"description,omitempty" json:"description,omitempty"`
+	Attribute   []Attribute   `xml:"attribute,omitempty" json:"attributes,omitempty"`
+	Noteref     []Noteref     `xml:"noteref,omitempty" json:"note_refs,omitempty"`
+	Citationref []Citationref `xml:"citationref,omitempty" json:"citation_refs,omitempty"`
+	Objref      []Objref      `xml:"objref,omitempty" json:"media_refs,omitempty"`
+	Tagref      []Tagref      `xml:"tagref,omitempty" json:"tag_refs,omitempty"`
 }
 
 type Sources struct {
-	Source []Source `xml:"source,omitempty"`
+	Source []Source `xml:"source,omitempty" json:"sources,omitempty"`
 }
 
 type Source struct {
-	ID           *string        `xml:"id,attr,omitempty"`
-	Handle       string         `xml:"handle,attr"`
-	Priv         *bool          `xml:"priv,attr,omitempty"`
-	Change       string         `xml:"change,attr"`
-	Stitle       *string        `xml:"stitle,omitempty"`
-	Sauthor      *string        `xml:"sauthor,omitempty"`
-	Spubinfo     *string        `xml:"spubinfo,omitempty"`
-	Sabbrev      *string        `xml:"sabbrev,omitempty"`
-	Noteref      []Noteref      `xml:"noteref,omitempty"`
-	Objref       []Objref       `xml:"objref,omitempty"`
-	Srcattribute []Srcattribute `xml:"srcattribute,omitempty"`
-	Reporef      []Reporef      `xml:"reporef,omitempty"`
-	Tagref       []Tagref       `xml:"tagref,omitempty"`
+	ID           *string        `xml:"id,attr,omitempty" json:"id,omitempty"`
+	Handle       string         `xml:"handle,attr" json:"handle"`
+	Priv         *bool          `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Change       string         `xml:"change,attr" json:"change"`
+	Stitle       *string        `xml:"stitle,omitempty" json:"title,omitempty"`
+	Sauthor      *string        `xml:"sauthor,omitempty" json:"author,omitempty"`
+	Spubinfo     *string        `xml:"spubinfo,omitempty" json:"pubinfo,omitempty"`
+	Sabbrev      *string        `xml:"sabbrev,omitempty" json:"abbrev,omitempty"`
+	Noteref      []Noteref      `xml:"noteref,omitempty" json:"note_refs,omitempty"`
+	Objref       []Objref       `xml:"objref,omitempty" json:"media_refs,omitempty"`
+	Srcattribute []Srcattribute `xml:"srcattribute,omitempty" json:"attributes,omitempty"`
+	Reporef      []Reporef      `xml:"reporef,omitempty" json:"repo_refs,omitempty"`
+	Tagref       []Tagref       `xml:"tagref,omitempty" json:"tag_refs,omitempty"`
 }
 
 type Places struct {
-	Place []Placeobj `xml:"placeobj,omitempty"`
+	Place []Placeobj `xml:"placeobj,omitempty" json:"places,omitempty"`
 }
 
 type Placeobj struct {
-	ID          *string       `xml:"id,attr,omitempty"`
-	Handle      string        `xml:"handle,attr"`
-	Priv        *bool         `xml:"priv,attr,omitempty"`
-	Change      string        `xml:"change,attr"`
-	Type        string        `xml:"type,attr"`
-	Ptitle      *string       `xml:"ptitle,omitempty"`
-	Pname       []Pname       `xml:"pname"`
-	Code        *string       `xml:"code,omitempty"`
-	Coord       *Coord        `xml:"coord,omitempty"`
-	Placeref    []Placeref    `xml:"placeref,omitempty"`
-	Location    []Location    `xml:"location,omitempty"`
-	Url         []Url         `xml:"url,omitempty"`
-	Objref      []Objref      `xml:"objref,omitempty"`
-	Noteref     []Noteref     `xml:"noteref,omitempty"`
-	Citationref []Citationref `xml:"citationref,omitempty"`
-	Tagref      []Tagref      `xml:"tagref,omitempty"`
+	ID          *string       `xml:"id,attr,omitempty" json:"id,omitempty"`
+	Handle      string        `xml:"handle,attr" json:"handle"`
+	Priv        *bool         `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Change      string        `xml:"change,attr" json:"change"`
+	Type        string        `xml:"type,attr" json:"type"`
+	Ptitle      *string       `xml:"ptitle,omitempty" json:"title,omitempty"`
+	Pname       []Pname       `xml:"pname" json:"names,omitempty"`
+	Code        *string       `xml:"code,omitempty" json:"code,omitempty"`
+	Coord       *Coord        `xml:"coord,omitempty" json:"coord,omitempty"`
+	Placeref    []Placeref    `xml:"placeref,omitempty" json:"place_refs,omitempty"`
+	Location    []Location    `xml:"location,omitempty" json:"locations,omitempty"`
+	Url         []Url         `xml:"url,omitempty" json:"urls,omitempty"`
+	Objref      []Objref      `xml:"objref,omitempty" json:"media_refs,omitempty"`
+	Noteref     []Noteref     `xml:"noteref,omitempty" json:"note_refs,omitempty"`
+	Citationref []Citationref `xml:"citationref,omitempty" json:"citation_refs,omitempty"`
+	Tagref      []Tagref      `xml:"tagref,omitempty" json:"tag_refs,omitempty"`
 }
 
 type Pname struct {
-	Lang      *string    `xml:"lang,attr,omitempty"`
-	Value     string     `xml:"value,attr"`
-	Daterange *Daterange `xml:"daterange,omitempty"`
-	Datespan  *Datespan  `xml:"datespan,omitempty"`
-	Dateval   *Dateval   `xml:"dateval,omitempty"`
-	Datestr   *Datestr   `xml:"datestr,omitempty"`
+	Lang      *string    `xml:"lang,attr,omitempty" json:"lang,omitempty"`
+	Value     string     `xml:"value,attr" json:"value"`
+	Daterange *Daterange `xml:"daterange,omitempty" json:"-"`
+	Datespan  *Datespan  `xml:"datespan,omitempty" json:"-"`
+	Dateval   *Dateval   `xml:"dateval,omitempty" json:"-"`
+	Datestr   *Datestr   `xml:"datestr,omitempty" json:"-"`
 }
 
 type Coord struct {
-	Long string `xml:"long,attr"`
-	Lat  string `xml:"lat,attr"`
+	Long string `xml:"long,attr" json:"long"`
+	Lat  string `xml:"lat,attr" json:"lat"`
 }
 
 type Location struct {
-	Street   *string `xml:"street,attr,omitempty"`
-	Locality *string `xml:"locality,attr,omitempty"`
-	City     *string `xml:"city,attr,omitempty"`
-	Parish   *string `xml:"parish,attr,omitempty"`
-	County   *string `xml:"county,attr,omitempty"`
-	State    *string `xml:"state,attr,omitempty"`
-	Country  *string `xml:"country,attr,omitempty"`
-	Postal   *string `xml:"postal,attr,omitempty"`
-	Phone    *string `xml:"phone,attr,omitempty"`
+	Street   *string `xml:"street,attr,omitempty" json:"street,omitempty"`
+	Locality *string `xml:"locality,attr,omitempty" json:"locality,omitempty"`
+	City     *string `xml:"city,attr,omitempty" json:"city,omitempty"`
+	Parish   *string `xml:"parish,attr,omitempty" json:"parish,omitempty"`
+	County   *string `xml:"county,attr,omitempty" json:"county,omitempty"`
+	State    *string `xml:"state,attr,omitempty" json:"state,omitempty"`
+	Country  *string `xml:"country,attr,omitempty" json:"country,omitempty"`
+	Postal   *string `xml:"postal,attr,omitempty" json:"postal,omitempty"`
+	Phone    *string `xml:"phone,attr,omitempty" json:"phone,omitempty"`
 }
 
 type Objects struct {
-	Object []Object `xml:"object,omitempty"`
+	Object []Object `xml:"object,omitempty" json:"objects,omitempty"`
 }
 
 type Object struct {
-	ID          *string       `xml:"id,attr,omitempty"`
-	Handle      string        `xml:"handle,attr"`
-	Priv        *bool         `xml:"priv,attr,omitempty"`
-	Change      string        `xml:"change,attr"`
-	File        File          `xml:"file"`
-	Attribute   []Attribute   `xml:"attribute,omitempty"`
-	Noteref     []Noteref     `xml:"noteref,omitempty"`
-	Daterange   *Daterange    `xml:"daterange,omitempty"`
-	Datespan    *Datespan     `xml:"datespan,omitempty"`
-	Dateval     *Dateval      `xml:"dateval,omitempty"`
-	Datestr     *Datestr      `xml:"datestr,omitempty"`
-	Citationref []Citationref `xml:"citationref,omitempty"`
-	Tagref      []Tagref      `xml:"tagref,omitempty"`
+	ID          *string       `xml:"id,attr,omitempty" json:"id,omitempty"`
+	Handle      string        `xml:"handle,attr" json:"handle"`
+	Priv        *bool         `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Change      string        `xml:"change,attr" json:"change"`
+	File        File          `xml:"file" json:"file"`
+	Attribute   []Attribute   `xml:"attribute,omitempty" json:"attributes,omitempty"`
+	Noteref     []Noteref     `xml:"noteref,omitempty" json:"note_refs,omitempty"`
+	Daterange   *Daterange    `xml:"daterange,omitempty" json:"-"`
+	Datespan    *Datespan     `xml:"datespan,omitempty" json:"-"`
+	Dateval     *Dateval      `xml:"dateval,omitempty" json:"-"`
+	Datestr     *Datestr      `xml:"datestr,omitempty" json:"-"`
+	Citationref []Citationref `xml:"citationref,omitempty" json:"citation_refs,omitempty"`
+	Tagref      []Tagref      `xml:"tagref,omitempty" json:"tag_refs,omitempty"`
 }
 
 type File struct {
-	Src         string  `xml:"src,attr"`
-	Mime        string  `xml:"mime,attr"`
-	Checksum    *string `xml:"checksum,attr,omitempty"`
-	Description string  `xml:"description,attr"`
+	Src         string  `xml:"src,attr" json:"src"`
+	Mime        string  `xml:"mime,attr" json:"mime"`
+	Checksum    *string `xml:"checksum,attr,omitempty" json:"checksum,omitempty"`
+	Description string  `xml:"description,attr" json:"description"`
 }
 
 type Repositories struct {
-	Repository []Repository `xml:"repository,omitempty"`
+	Repository []Repository `xml:"repository,omitempty" json:"repositories,omitempty"`
 }
 
 type Repository struct {
-	ID      *string   `xml:"id,attr,omitempty"`
-	Handle  string    `xml:"handle,attr"`
-	Priv    *bool     `xml:"priv,attr,omitempty"`
-	Change  string    `xml:"change,attr"`
-	Rname   string    `xml:"rname"`
-	Type    string    `xml:"type"`
-	Address []Address `xml:"address,omitempty"`
-	Url     []Url     `xml:"url,omitempty"`
-	Noteref []Noteref `xml:"noteref,omitempty"`
-	Tagref  []Tagref  `xml:"tagref,omitempty"`
+	ID      *string   `xml:"id,attr,omitempty" json:"id,omitempty"`
+	Handle  string    `xml:"handle,attr" json:"handle"`
+	Priv    *bool     `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Change  string    `xml:"change,attr" json:"change"`
+	Rname   string    `xml:"rname" json:"name"`
+	Type    string    `xml:"type" json:"type"`
+	Address []Address `xml:"address,omitempty" json:"addresses,omitempty"`
+	Url     []Url     `xml:"url,omitempty" json:"urls,omitempty"`
+	Noteref []Noteref `xml:"noteref,omitempty" json:"note_refs,omitempty"`
+	Tagref  []Tagref  `xml:"tagref,omitempty" json:"tag_refs,omitempty"`
 }
 
 type Notes struct {
-	Note []Note `xml:"note,omitempty"`
+	Note []Note `xml:"note,omitempty" json:"notes,omitempty"`
 }
 
 type Note struct {
-	ID     *string  `xml:"id,attr,omitempty"`
-	Handle string   `xml:"handle,attr"`
-	Priv   *bool    `xml:"priv,attr,omitempty"`
-	Change string   `xml:"change,attr"`
-	Format *bool    `xml:"format,attr,omitempty"`
-	Type   string   `xml:"type,attr"`
-	Text   string   `xml:"text"`
-	Style  []Style  `xml:"style,omitempty"`
-	Tagref []Tagref `xml:"tagref,omitempty"`
+	ID     *string  `xml:"id,attr,omitempty" json:"id,omitempty"`
+	Handle string   `xml:"handle,attr" json:"handle"`
+	Priv   *bool    `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Change string   `xml:"change,attr" json:"change"`
+	Format *bool    `xml:"format,attr,omitempty" json:"format,omitempty"`
+	Type   string   `xml:"type,attr" json:"type"`
+	Text   string   `xml:"text" json:"text"`
+	Style  []Style  `xml:"style,omitempty" json:"styles,omitempty"`
+	Tagref []Tagref `xml:"tagref,omitempty" json:"tag_refs,omitempty"`
 }
 
 type Style struct {
-	Name  string  `xml:"name,attr"`
-	Value *string `xml:"value,attr,omitempty"`
-	Range []Range `xml:"range"`
+	Name  string  `xml:"name,attr" json:"name"`
+	Value *string `xml:"value,attr,omitempty" json:"value,omitempty"`
+	Range []Range `xml:"range" json:"ranges,omitempty"`
 }
 
 type Range struct {
-	Start int `xml:"start,attr"`
-	End   int `xml:"end,attr"`
+	Start int `xml:"start,attr" json:"start"`
+	End   int `xml:"end,attr" json:"end"`
 }
 
 type Tags struct {
-	Tag []Tag `xml:"tag,omitempty"`
+	Tag []Tag `xml:"tag,omitempty" json:"tags,omitempty"`
 }
 
 type Tag struct {
-	Handle   string `xml:"handle,attr"`
-	Name     string `xml:"name,attr"`
-	Color    string `xml:"color,attr"`
-	Priority string `xml:"priority,attr"`
-	Change   string `xml:"change,attr"`
+	Handle   string `xml:"handle,attr" json:"handle"`
+	Name     string `xml:"name,attr" json:"name"`
+	Color    string `xml:"color,attr" json:"color"`
+	Priority string `xml:"priority,attr" json:"priority"`
+	Change   string `xml:"change,attr" json:"change"`
 }
 
 type Citations struct {
-	Citation []Citation `xml:"citation,omitempty"`
+	Citation []Citation `xml:"citation,omitempty" json:"citations,omitempty"`
 }
 
 type Citation struct {
-	ID           *string        `xml:"id,attr,omitempty"`
-	Handle       string         `xml:"handle,attr"`
-	Priv         *bool          `xml:"priv,attr,omitempty"`
-	Change       string         `xml:"change,attr"`
-	Daterange    *Daterange     `xml:"daterange,omitempty"`
-	Datespan     *Datespan      `xml:"datespan,omitempty"`
-	Dateval      *Dateval       `xml:"dateval,omitempty"`
-	Datestr      *Datestr       `xml:"datestr,omitempty"`
-	Page         *string        `xml:"page,omitempty"`
-	Confidence   string         `xml:"confidence"`
-	Noteref      []Noteref      `xml:"noteref,omitempty"`
-	Objref       []Objref       `xml:"objref,omitempty"`
-	Srcattribute []Srcattribute `xml:"srcattribute,omitempty"`
-	Sourceref    *Sourceref     `xml:"sourceref,omitempty"`
-	Tagref       []Tagref       `xml:"tagref,omitempty"`
+	ID           *string        `xml:"id,attr,omitempty" json:"id,omitempty"`
+	Handle       string         `xml:"handle,attr" json:"handle"`
+	Priv         *bool          `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Change       string         `xml:"change,attr" json:"change"`
+	Daterange    *Daterange     `xml:"daterange,omitempty" json:"-"`
+	Datespan     *Datespan      `xml:"datespan,omitempty" json:"-"`
+	Dateval      *Dateval       `xml:"dateval,omitempty" json:"-"`
+	Datestr      *Datestr       `xml:"datestr,omitempty" json:"-"`
+	Page         *string        `xml:"page,omitempty" json:"page,omitempty"`
+	Confidence   string         `xml:"confidence" json:"confidence"`
+	Noteref      []Noteref      `xml:"noteref,omitempty" json:"note_refs,omitempty"`
+	Objref       []Objref       `xml:"objref,omitempty" json:"media_refs,omitempty"`
+	Srcattribute []Srcattribute `xml:"srcattribute,omitempty" json:"attributes,omitempty"`
+	Sourceref    *Sourceref     `xml:"sourceref,omitempty" json:"source,omitempty"`
+	Tagref       []Tagref       `xml:"tagref,omitempty" json:"tag_refs,omitempty"`
 }
 
 type Bookmarks struct {
-	Bookmark []Bookmark `xml:"bookmark,omitempty"`
+	Bookmark []Bookmark `xml:"bookmark,omitempty" json:"bookmarks,omitempty"`
 }
 
 type Bookmark struct {
-	Target string `xml:"target,attr"`
-	Hlink  string `xml:"hlink,attr"`
+	Target string `xml:"target,attr" json:"target"`
+	Hlink  string `xml:"hlink,attr" json:"ref"`
 }
 
 type Namemaps struct {
-	Map []Map `xml:"map,omitempty"`
+	Map []Map `xml:"map,omitempty" json:"maps,omitempty"`
 }
 
 type Map struct {
-	Type  string `xml:"type,attr"`
-	Key   string `xml:"key,attr"`
-	Value string `xml:"value,attr"`
+	Type  string `xml:"type,attr" json:"type"`
+	Key   string `xml:"key,attr" json:"key"`
+	Value string `xml:"value,attr" json:"value"`
 }
 
 type NameFormat struct {
-	Number string `xml:"number,attr"`
-	Name   string `xml:"name,attr"`
-	Fmtstr string `xml:"fmt_str,attr"`
-	Active *bool  `xml:"active,attr,omitempty"`
+	Number string `xml:"number,attr" json:"number"`
+	Name   string `xml:"name,attr" json:"name"`
+	Fmtstr string `xml:"fmt_str,attr" json:"format"`
+	Active *bool  `xml:"active,attr,omitempty" json:"active,omitempty"`
 }
 
 type Daterange struct {
-	Start     string  `xml:"start,attr"`
-	Stop      string  `xml:"stop,attr"`
-	Quality   *string `xml:"quality,attr,omitempty"`
-	Cformat   *string `xml:"cformat,attr,omitempty"`
-	Dualdated *bool   `xml:"dualdated,attr,omitempty"`
-	Newyear   *string `xml:"newyear,attr,omitempty"`
+	Start     string  `xml:"start,attr" json:"start"`
+	Stop      string  `xml:"stop,attr" json:"stop"`
+	Quality   *string `xml:"quality,attr,omitempty" json:"quality,omitempty"`
+	Cformat   *string `xml:"cformat,attr,omitempty" json:"cformat,omitempty"`
+	Dualdated *bool   `xml:"dualdated,attr,omitempty" json:"dualdated,omitempty"`
+	Newyear   *string `xml:"newyear,attr,omitempty" json:"newyear,omitempty"`
 }
 
 type Datespan struct {
-	Start     string  `xml:"start,attr"`
-	Stop      string  `xml:"stop,attr"`
-	Quality   *string `xml:"quality,attr,omitempty"`
-	Cformat   *string `xml:"cformat,attr,omitempty"`
-	Dualdated *bool   `xml:"dualdated,attr,omitempty"`
-	Newyear   *string `xml:"newyear,attr,omitempty"`
+	Start     string  `xml:"start,attr" json:"start"`
+	Stop      string  `xml:"stop,attr" json:"stop"`
+	Quality   *string `xml:"quality,attr,omitempty" json:"quality,omitempty"`
+	Cformat   *string `xml:"cformat,attr,omitempty" json:"cformat,omitempty"`
+	Dualdated *bool   `xml:"dualdated,attr,omitempty" json:"dualdated,omitempty"`
+	Newyear   *string `xml:"newyear,attr,omitempty" json:"newyear,omitempty"`
 }
 
 type Dateval struct {
-	Val       string  `xml:"val,attr"`
-	Type      *string `xml:"type,attr,omitempty"`
-	Quality   *string `xml:"quality,attr,omitempty"`
-	Cformat   *string `xml:"cformat,attr,omitempty"`
-	Dualdated *bool   `xml:"dualdated,attr,omitempty"`
-	Newyear   *string `xml:"newyear,attr,omitempty"`
+	Val       string  `xml:"val,attr" json:"val"`
+	Type      *string `xml:"type,attr,omitempty" json:"type,omitempty"`
+	Quality   *string `xml:"quality,attr,omitempty" json:"quality,omitempty"`
+	Cformat   *string `xml:"cformat,attr,omitempty" json:"cformat,omitempty"`
+	Dualdated *bool   `xml:"dualdated,attr,omitempty" json:"dualdated,omitempty"`
+	Newyear   *string `xml:"newyear,attr,omitempty" json:"newyear,omitempty"`
 }
 
 type Datestr struct {
-	Val string `xml:"val,attr"`
+	Val string `xml:"val,attr" json:"val"`
 }
 
 type Citationref struct {
-	Hlink string `xml:"hlink,attr"`
+	Hlink string `xml:"hlink,attr" json:"ref"`
 }
 
 type Sourceref struct {
-	Hlink string `xml:"hlink,attr"`
+	Hlink string `xml:"hlink,attr" json:"ref"`
 }
 
 type Eventref struct {
-	Hlink     string      `xml:"hlink,attr"`
-	Priv      *bool       `xml:"priv,attr,omitempty"`
-	Role      *string     `xml:"role,attr,omitempty"`
-	Attribute []Attribute `xml:"attribute,omitempty"`
-	Noteref   []Noteref   `xml:"noteref,omitempty"`
+	Hlink     string      `xml:"hlink,attr" json:"ref"`
+	Priv      *bool       `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Role      *string     `xml:"role,attr,omitempty" json:"role,omitempty"`
+	Attribute []Attribute `xml:"attribute,omitempty" json:"attributes,omitempty"`
+	Noteref   []Noteref   `xml:"noteref,omitempty" json:"note_refs,omitempty"`
 }
 
 type Reporef struct {
-	Hlink  string  `xml:"hlink,attr"`
-	Priv   *bool   `xml:"priv,attr,omitempty"`
-	Callno *string `xml:"callno,attr,omitempty"`
-	Medium *string `xml:"medium,attr,omitempty"`
+	Hlink  string  `xml:"hlink,attr" json:"ref"`
+	Priv   *bool   `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Callno *string `xml:"callno,attr,omitempty" json:"call_number,omitempty"`
+	Medium *string `xml:"medium,attr,omitempty" json:"medium,omitempty"`
 }
 
 type Noteref struct {
-	Hlink string `xml:"hlink,attr"`
+	Hlink string `xml:"hlink,attr" json:"ref"`
 }
 
 type Tagref struct {
-	Hlink string `xml:"hlink,attr"`
+	Hlink string `xml:"hlink,attr" json:"ref"`
 }
 
 type Attribute struct {
-	Priv        *bool         `xml:"priv,attr,omitempty"`
-	Type        string        `xml:"type,attr"`
-	Value       string        `xml:"value,attr"`
-	Citationref []Citationref `xml:"citationref,omitempty"`
+	Priv        *bool         `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Type        string        `xml:"type,attr" json:"type"`
+	Value       string        `xml:"value,attr" json:"value"`
+	Citationref []Citationref `xml:"citationref,omitempty" json:"citation_refs,omitempty"`
 }
 
 type Srcattribute struct {
-	Priv  *bool  `xml:"priv,attr,omitempty"`
-	Type  string `xml:"type,attr"`
-	Value string `xml:"value,attr"`
+	Priv  *bool  `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Type  string `xml:"type,attr" json:"type"`
+	Value string `xml:"value,attr" json:"value"`
 }
 
 type Place struct {
-	Hlink string `xml:"hlink,attr"`
+	Hlink string `xml:"hlink,attr" json:"ref"`
 }
 
 type Url struct {
-	Priv        *bool   `xml:"priv,attr,omitempty"`
-	Type        *string `xml:"type,attr,omitempty"`
-	Href        string  `xml:"href,attr"`
-	Description *string `xml:"description,attr,omitempty"`
+	Priv        *bool   `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Type        *string `xml:"type,attr,omitempty" json:"type,omitempty"`
+	Href        string  `xml:"href,attr" json:"href"`
+	Description *string `xml:"description,attr,omitempty" json:"description,omitempty"`
 }
 
 type Objref struct {
-	Hlink  string  `xml:"hlink,attr"`
-	Priv   *bool   `xml:"priv,attr,omitempty"`
-	Region *Region `xml:"region,omitempty"`
+	Hlink  string  `xml:"hlink,attr" json:"ref"`
+	Priv   *bool   `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Region *Region `xml:"region,omitempty" json:"region,omitempty"`
 }
 
 type Region struct {
-	Corner1x *int `xml:"corner1_x,attr,omitempty"`
-	Corner1y *int `xml:"corner1_y,attr,omitempty"`
-	Corner2x *int `xml:"corner2_x,attr,omitempty"`
-	Corner2y *int `xml:"corner2_y,attr,omitempty"`
+	Corner1x *int `xml:"corner1_x,attr,omitempty" json:"corner1_x,omitempty"`
+	Corner1y *int `xml:"corner1_y,attr,omitempty" json:"corner1_y,omitempty"`
+	Corner2x *int `xml:"corner2_x,attr,omitempty" json:"corner2_x,omitempty"`
+	Corner2y *int `xml:"corner2_y,attr,omitempty" json:"corner2_y,omitempty"`
 }
 
 type Placeref struct {
-	Hlink string `xml:"hlink,attr"`
+	Hlink     string     `xml:"hlink,attr" json:"ref"`
+	Daterange *Daterange `xml:"daterange,omitempty" json:"-"`
+	Datespan  *Datespan  `xml:"datespan,omitempty" json:"-"`
+	Dateval   *Dateval   `xml:"dateval,omitempty" json:"-"`
+	Datestr   *Datestr   `xml:"datestr,omitempty" json:"-"`
 }
 
 type LdsOrd struct {
-	Priv        *bool         `xml:"priv,attr,omitempty"`
-	Type        string        `xml:"type,attr"`
-	Daterange   *Daterange    `xml:"daterange,omitempty"`
-	Datespan    *Datespan     `xml:"datespan,omitempty"`
-	Dateval     *Dateval      `xml:"dateval,omitempty"`
-	Datestr     *Datestr      `xml:"datestr,omitempty"`
-	Temple      *Temple       `xml:"temple,omitempty"`
-	Place       *Place        `xml:"place,omitempty"`
-	Status      *Status       `xml:"status,omitempty"`
-	SealedTo    *SealedTo     `xml:"sealed_to,omitempty"`
-	Noteref     []Noteref     `xml:"noteref,omitempty"`
-	Citationref []Citationref `xml:"citationref,omitempty"`
+	Priv        *bool         `xml:"priv,attr,omitempty" json:"private,omitempty"`
+	Type        string        `xml:"type,attr" json:"type"`
+	Daterange   *Daterange    `xml:"daterange,omitempty" json:"-"`
+	Datespan    *Datespan     `xml:"datespan,omitempty" json:"-"`
+	Dateval     *Dateval      `xml:"dateval,omitempty" json:"-"`
+	Datestr     *Datestr      `xml:"datestr,omitempty" json:"-"`
+	Temple      *Temple       `xml:"temple,omitempty" json:"temple,omitempty"`
+	Place       *Place        `xml:"place,omitempty" json:"place,omitempty"`
+	Status      *Status       `xml:"status,omitempty" json:"status,omitempty"`
+	SealedTo    *SealedTo     `xml:"sealed_to,omitempty" json:"sealed_to,omitempty"`
+	Noteref     []Noteref     `xml:"noteref,omitempty" json:"note_refs,omitempty"`
+	Citationref []Citationref `xml:"citationref,omitempty" json:"citation_refs,omitempty"`
 }
 
 type Temple struct {
-	Val string `xml:"val,attr"`
+	Val string `xml:"val,attr" json:"val"`
 }
 
 type Status struct {
-	Val string `xml:"val,attr"`
+	Val string `xml:"val,attr" json:"val"`
 }
 
 type SealedTo struct {
-	Hlink string `xml:"hlink,attr"`
+	Hlink string `xml:"hlink,attr" json:"ref"`
 }
@@ -407,6 +407,41 @@ var wellFormedCases = []wellFormedCase{
 		},
 	},
 
+	{
+		name: "dated placerefs",
+		input: `<?xml version="1.0" encoding="UTF-8"?>
+<database xmlns="http://gramps-project.org/xml/1.7.1/">
+  <places>
+    <placeobj handle="_c965872ad3f4b1a1e43" change="1700000000" id="P0010" type="Town">
+      <pname value="Lviv"/>
+      <placeref hlink="_c965872b0f6d1a2c4ff">
+        <datespan start="1772" stop="1918"/>
+      </placeref>
+      <placeref hlink="_c965872b4a1f0b6a2d1">
+        <dateval val="1991" type="after"/>
+      </placeref>
+    </placeobj>
+  </places>
+</database>`,
+		want: &Database{
+			Places: &Places{
+				Place: []Placeobj{
+					{
+						Handle: "_c965872ad3f4b1a1e43",
+						Change: "1700000000",
+						ID:     new("P0010"),
+						Type:   "Town",
+						Pname:  []Pname{{Value: "Lviv"}},
+						Placeref: []Placeref{
+							{Hlink: "_c965872b0f6d1a2c4ff", Datespan: &Datespan{Start: "1772", Stop: "1918"}},
+							{Hlink: "_c965872b4a1f0b6a2d1", Dateval: &Dateval{Val: "1991", Type: new("after")}},
+						},
+					},
+				},
+			},
+		},
+	},
+
 	{
 		name: "objects",
 		input: `<?xml version="1.0" encoding="UTF-8"?>
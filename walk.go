@@ -0,0 +1,458 @@
+package grampsxml
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Types of object that may be referred to, named as in the target attribute
+// of a bookmark.
+const (
+	targetPerson     = "person"
+	targetFamily     = "family"
+	targetEvent      = "event"
+	targetPlace      = "place"
+	targetSource     = "source"
+	targetCitation   = "citation"
+	targetMedia      = "media"
+	targetRepository = "repository"
+	targetNote       = "note"
+	targetTag        = "tag"
+)
+
+// reference is a link from one object in a database to another.
+type reference struct {
+	// ownerType and owner identify the primary object holding the
+	// reference. They are empty for bookmarks and for the home and default
+	// people.
+	ownerType string
+	owner     string
+
+	// target is the type of the object referred to and hlink points at its
+	// handle, which may be rewritten.
+	target string
+	hlink  *string
+}
+
+// refVisitor is called with the type and handle of each object referred to
+// by an object.
+type refVisitor func(target string, hlink *string)
+
+// walkCommonReferences calls fn with every reference in db held by the
+// objects common to all schema versions.
+func walkCommonReferences(db *Database, fn func(reference)) {
+	owned := func(ownerType, owner string) refVisitor {
+		return func(target string, hlink *string) {
+			fn(reference{ownerType: ownerType, owner: owner, target: target, hlink: hlink})
+		}
+	}
+
+	people := db.People.list()
+	for i := range people {
+		people[i].refs(owned(targetPerson, people[i].Handle))
+	}
+	families := db.Families.list()
+	for i := range families {
+		families[i].refs(owned(targetFamily, families[i].Handle))
+	}
+	events := db.Events.list()
+	for i := range events {
+		events[i].refs(owned(targetEvent, events[i].Handle))
+	}
+	places := db.Places.list()
+	for i := range places {
+		places[i].refs(owned(targetPlace, places[i].Handle))
+	}
+	sources := db.Sources.list()
+	for i := range sources {
+		sources[i].refs(owned(targetSource, sources[i].Handle))
+	}
+	citations := db.Citations.list()
+	for i := range citations {
+		citations[i].refs(owned(targetCitation, citations[i].Handle))
+	}
+	objects := db.Objects.list()
+	for i := range objects {
+		objects[i].refs(owned(targetMedia, objects[i].Handle))
+	}
+	repositories := db.Repositories.list()
+	for i := range repositories {
+		repositories[i].refs(owned(targetRepository, repositories[i].Handle))
+	}
+	notes := db.Notes.list()
+	for i := range notes {
+		tagRefs(notes[i].Tagref, owned(targetNote, notes[i].Handle))
+	}
+
+	if db.People != nil {
+		if db.People.Default != nil {
+			fn(reference{target: targetPerson, hlink: db.People.Default})
+		}
+		if db.People.Home != nil {
+			fn(reference{target: targetPerson, hlink: db.People.Home})
+		}
+	}
+	bookmarks := db.Bookmarks.list()
+	for i := range bookmarks {
+		fn(reference{target: bookmarks[i].Target, hlink: &bookmarks[i].Hlink})
+	}
+}
+
+func (p *Person) refs(visit refVisitor) {
+	for i := range p.Name {
+		n := &p.Name[i]
+		noteRefs(n.Noteref, visit)
+		citationRefs(n.Citationref, visit)
+	}
+	eventRefs(p.Eventref, visit)
+	ldsOrdRefs(p.LdsOrd, visit)
+	objRefs(p.Objref, visit)
+	addressRefs(p.Address, visit)
+	attributeRefs(p.Attribute, visit)
+	for i := range p.Childof {
+		visit(targetFamily, &p.Childof[i].Hlink)
+	}
+	for i := range p.Parentin {
+		visit(targetFamily, &p.Parentin[i].Hlink)
+	}
+	for i := range p.Personref {
+		pr := &p.Personref[i]
+		visit(targetPerson, &pr.Hlink)
+		citationRefs(pr.Citationref, visit)
+		noteRefs(pr.Noteref, visit)
+	}
+	noteRefs(p.Noteref, visit)
+	citationRefs(p.Citationref, visit)
+	tagRefs(p.Tagref, visit)
+}
+
+func (f *Family) refs(visit refVisitor) {
+	if f.Father != nil {
+		visit(targetPerson, &f.Father.Hlink)
+	}
+	if f.Mother != nil {
+		visit(targetPerson, &f.Mother.Hlink)
+	}
+	eventRefs(f.Eventref, visit)
+	ldsOrdRefs(f.LdsOrd, visit)
+	objRefs(f.Objref, visit)
+	for i := range f.Childref {
+		visit(targetPerson, &f.Childref[i].Hlink)
+	}
+	attributeRefs(f.Attribute, visit)
+	noteRefs(f.Noteref, visit)
+	citationRefs(f.Citationref, visit)
+	tagRefs(f.Tagref, visit)
+}
+
+func (e *Event) refs(visit refVisitor) {
+	if e.Place != nil {
+		visit(targetPlace, &e.Place.Hlink)
+	}
+	attributeRefs(e.Attribute, visit)
+	noteRefs(e.Noteref, visit)
+	citationRefs(e.Citationref, visit)
+	objRefs(e.Objref, visit)
+	tagRefs(e.Tagref, visit)
+}
+
+func (p *Placeobj) refs(visit refVisitor) {
+	for i := range p.Placeref {
+		visit(targetPlace, &p.Placeref[i].Hlink)
+	}
+	objRefs(p.Objref, visit)
+	noteRefs(p.Noteref, visit)
+	citationRefs(p.Citationref, visit)
+	tagRefs(p.Tagref, visit)
+}
+
+func (s *Source) refs(visit refVisitor) {
+	noteRefs(s.Noteref, visit)
+	objRefs(s.Objref, visit)
+	for i := range s.Reporef {
+		visit(targetRepository, &s.Reporef[i].Hlink)
+	}
+	tagRefs(s.Tagref, visit)
+}
+
+func (c *Citation) refs(visit refVisitor) {
+	noteRefs(c.Noteref, visit)
+	objRefs(c.Objref, visit)
+	if c.Sourceref != nil {
+		visit(targetSource, &c.Sourceref.Hlink)
+	}
+	tagRefs(c.Tagref, visit)
+}
+
+func (o *Object) refs(visit refVisitor) {
+	attributeRefs(o.Attribute, visit)
+	noteRefs(o.Noteref, visit)
+	citationRefs(o.Citationref, visit)
+	tagRefs(o.Tagref, visit)
+}
+
+func (r *Repository) refs(visit refVisitor) {
+	addressRefs(r.Address, visit)
+	noteRefs(r.Noteref, visit)
+	tagRefs(r.Tagref, visit)
+}
+
+func eventRefs(refs []Eventref, visit refVisitor) {
+	for i := range refs {
+		visit(targetEvent, &refs[i].Hlink)
+		attributeRefs(refs[i].Attribute, visit)
+		noteRefs(refs[i].Noteref, visit)
+	}
+}
+
+func ldsOrdRefs(ords []LdsOrd, visit refVisitor) {
+	for i := range ords {
+		o := &ords[i]
+		if o.Place != nil {
+			visit(targetPlace, &o.Place.Hlink)
+		}
+		if o.SealedTo != nil {
+			visit(targetFamily, &o.SealedTo.Hlink)
+		}
+		noteRefs(o.Noteref, visit)
+		citationRefs(o.Citationref, visit)
+	}
+}
+
+func addressRefs(addrs []Address, visit refVisitor) {
+	for i := range addrs {
+		noteRefs(addrs[i].Noteref, visit)
+		citationRefs(addrs[i].Citationref, visit)
+	}
+}
+
+func attributeRefs(attrs []Attribute, visit refVisitor) {
+	for i := range attrs {
+		citationRefs(attrs[i].Citationref, visit)
+	}
+}
+
+func objRefs(refs []Objref, visit refVisitor) {
+	for i := range refs {
+		visit(targetMedia, &refs[i].Hlink)
+	}
+}
+
+func noteRefs(refs []Noteref, visit refVisitor) {
+	for i := range refs {
+		visit(targetNote, &refs[i].Hlink)
+	}
+}
+
+func citationRefs(refs []Citationref, visit refVisitor) {
+	for i := range refs {
+		visit(targetCitation, &refs[i].Hlink)
+	}
+}
+
+func tagRefs(refs []Tagref, visit refVisitor) {
+	for i := range refs {
+		visit(targetTag, &refs[i].Hlink)
+	}
+}
+
+// setChange sets the change time of the primary object of the given type
+// and handle, if it is in the index.
+func (ix *Index) setChange(objType, handle, stamp string) {
+	switch objType {
+	case targetPerson:
+		if p := ix.Person(handle); p != nil {
+			p.Change = stamp
+		}
+	case targetFamily:
+		if f := ix.Family(handle); f != nil {
+			f.Change = stamp
+		}
+	case targetEvent:
+		if e := ix.Event(handle); e != nil {
+			e.Change = stamp
+		}
+	case targetPlace:
+		if p := ix.Place(handle); p != nil {
+			p.Change = stamp
+		}
+	case targetSource:
+		if s := ix.Source(handle); s != nil {
+			s.Change = stamp
+		}
+	case targetCitation:
+		if c := ix.Citation(handle); c != nil {
+			c.Change = stamp
+		}
+	case targetMedia:
+		if o := ix.Object(handle); o != nil {
+			o.Change = stamp
+		}
+	case targetRepository:
+		if r := ix.Repository(handle); r != nil {
+			r.Change = stamp
+		}
+	case targetNote:
+		if n := ix.Note(handle); n != nil {
+			n.Change = stamp
+		}
+	case targetTag:
+		if t := ix.Tag(handle); t != nil {
+			t.Change = stamp
+		}
+	}
+}
+
+// primaryObject gives access to the fields held by every primary object.
+type primaryObject struct {
+	objType string
+	handle  string
+	id      **string // nil for tags, which have no ID
+	change  *string
+	value   any // pointer to the object itself
+}
+
+// setHandle changes the handle of the object.
+func (o *primaryObject) setHandle(h string) {
+	reflect.ValueOf(o.value).Elem().FieldByName("Handle").SetString(h)
+	o.handle = h
+}
+
+// walkCommonObjects calls fn with each of the primary objects in db that
+// are common to all schema versions, in the order they are written to XML.
+func walkCommonObjects(db *Database, fn func(primaryObject)) {
+	tags := db.Tags.list()
+	for i := range tags {
+		fn(primaryObject{targetTag, tags[i].Handle, nil, &tags[i].Change, &tags[i]})
+	}
+	events := db.Events.list()
+	for i := range events {
+		fn(primaryObject{targetEvent, events[i].Handle, &events[i].ID, &events[i].Change, &events[i]})
+	}
+	people := db.People.list()
+	for i := range people {
+		fn(primaryObject{targetPerson, people[i].Handle, &people[i].ID, &people[i].Change, &people[i]})
+	}
+	families := db.Families.list()
+	for i := range families {
+		fn(primaryObject{targetFamily, families[i].Handle, &families[i].ID, &families[i].Change, &families[i]})
+	}
+	citations := db.Citations.list()
+	for i := range citations {
+		fn(primaryObject{targetCitation, citations[i].Handle, &citations[i].ID, &citations[i].Change, &citations[i]})
+	}
+	sources := db.Sources.list()
+	for i := range sources {
+		fn(primaryObject{targetSource, sources[i].Handle, &sources[i].ID, &sources[i].Change, &sources[i]})
+	}
+	places := db.Places.list()
+	for i := range places {
+		fn(primaryObject{targetPlace, places[i].Handle, &places[i].ID, &places[i].Change, &places[i]})
+	}
+	objects := db.Objects.list()
+	for i := range objects {
+		fn(primaryObject{targetMedia, objects[i].Handle, &objects[i].ID, &objects[i].Change, &objects[i]})
+	}
+	repositories := db.Repositories.list()
+	for i := range repositories {
+		fn(primaryObject{targetRepository, repositories[i].Handle, &repositories[i].ID, &repositories[i].Change, &repositories[i]})
+	}
+	notes := db.Notes.list()
+	for i := range notes {
+		fn(primaryObject{targetNote, notes[i].Handle, &notes[i].ID, &notes[i].Change, &notes[i]})
+	}
+}
+
+// addObject appends v, a pointer to a primary object, to the matching
+// container in db, creating the container if needed.
+func addObject(db *Database, v any) {
+	switch o := v.(type) {
+	case *Person:
+		if db.People == nil {
+			db.People = &People{}
+		}
+		db.People.Person = append(db.People.Person, *o)
+	case *Family:
+		if db.Families == nil {
+			db.Families = &Families{}
+		}
+		db.Families.Family = append(db.Families.Family, *o)
+	case *Event:
+		if db.Events == nil {
+			db.Events = &Events{}
+		}
+		db.Events.Event = append(db.Events.Event, *o)
+	case *Placeobj:
+		if db.Places == nil {
+			db.Places = &Places{}
+		}
+		db.Places.Place = append(db.Places.Place, *o)
+	case *Source:
+		if db.Sources == nil {
+			db.Sources = &Sources{}
+		}
+		db.Sources.Source = append(db.Sources.Source, *o)
+	case *Citation:
+		if db.Citations == nil {
+			db.Citations = &Citations{}
+		}
+		db.Citations.Citation = append(db.Citations.Citation, *o)
+	case *Object:
+		if db.Objects == nil {
+			db.Objects = &Objects{}
+		}
+		db.Objects.Object = append(db.Objects.Object, *o)
+	case *Repository:
+		if db.Repositories == nil {
+			db.Repositories = &Repositories{}
+		}
+		db.Repositories.Repository = append(db.Repositories.Repository, *o)
+	case *Note:
+		if db.Notes == nil {
+			db.Notes = &Notes{}
+		}
+		db.Notes.Note = append(db.Notes.Note, *o)
+	case *Tag:
+		if db.Tags == nil {
+			db.Tags = &Tags{}
+		}
+		db.Tags.Tag = append(db.Tags.Tag, *o)
+	default:
+		addSchemaObject(db, v)
+	}
+}
+
+// removeCommonObjects removes the primary objects common to all schema
+// versions for which remove returns true.
+func removeCommonObjects(db *Database, remove func(objType, handle string) bool) {
+	if db.Tags != nil {
+		db.Tags.Tag = slices.DeleteFunc(db.Tags.Tag, func(o Tag) bool { return remove(targetTag, o.Handle) })
+	}
+	if db.Events != nil {
+		db.Events.Event = slices.DeleteFunc(db.Events.Event, func(o Event) bool { return remove(targetEvent, o.Handle) })
+	}
+	if db.People != nil {
+		db.People.Person = slices.DeleteFunc(db.People.Person, func(o Person) bool { return remove(targetPerson, o.Handle) })
+	}
+	if db.Families != nil {
+		db.Families.Family = slices.DeleteFunc(db.Families.Family, func(o Family) bool { return remove(targetFamily, o.Handle) })
+	}
+	if db.Citations != nil {
+		db.Citations.Citation = slices.DeleteFunc(db.Citations.Citation, func(o Citation) bool { return remove(targetCitation, o.Handle) })
+	}
+	if db.Sources != nil {
+		db.Sources.Source = slices.DeleteFunc(db.Sources.Source, func(o Source) bool { return remove(targetSource, o.Handle) })
+	}
+	if db.Places != nil {
+		db.Places.Place = slices.DeleteFunc(db.Places.Place, func(o Placeobj) bool { return remove(targetPlace, o.Handle) })
+	}
+	if db.Objects != nil {
+		db.Objects.Object = slices.DeleteFunc(db.Objects.Object, func(o Object) bool { return remove(targetMedia, o.Handle) })
+	}
+	if db.Repositories != nil {
+		db.Repositories.Repository = slices.DeleteFunc(db.Repositories.Repository, func(o Repository) bool { return remove(targetRepository, o.Handle) })
+	}
+	if db.Notes != nil {
+		db.Notes.Note = slices.DeleteFunc(db.Notes.Note, func(o Note) bool { return remove(targetNote, o.Handle) })
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !gramps_schema180
+
+package grampsxml
+
+// walkReferences calls fn with every reference in db.
+func walkReferences(db *Database, fn func(reference)) {
+	walkCommonReferences(db, fn)
+}
+
+// walkObjects calls fn with each of the primary objects in db.
+func walkObjects(db *Database, fn func(primaryObject)) {
+	walkCommonObjects(db, fn)
+}
+
+// removeObjects removes the primary objects in db for which remove returns
+// true.
+func removeObjects(db *Database, remove func(objType, handle string) bool) {
+	removeCommonObjects(db, remove)
+}
+
+// addSchemaObject appends v to db if it is a primary object that only exists
+// in some schema versions.
+func addSchemaObject(db *Database, v any) {}
+
+// dependsOn reports whether objects of type ownerType cannot exist without
+// the objects of type target they refer to.
+func dependsOn(ownerType, target string) bool {
+	return ownerType == targetCitation && target == targetSource
+}
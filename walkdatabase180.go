@@ -0,0 +1,123 @@
+//go:build gramps_schema180
+
+package grampsxml
+
+import "slices"
+
+// targetDNATest is the type of a reference to a DNA test.
+const targetDNATest = "dnatest"
+
+// targetDNAMatch is the type of a DNA match, which is never referred to but
+// holds references to other objects.
+const targetDNAMatch = "dnamatch"
+
+// walkReferences calls fn with every reference in db, including those held
+// by DNA tests and matches.
+func walkReferences(db *Database, fn func(reference)) {
+	walkCommonReferences(db, fn)
+
+	owned := func(ownerType, owner string) refVisitor {
+		return func(target string, hlink *string) {
+			fn(reference{ownerType: ownerType, owner: owner, target: target, hlink: hlink})
+		}
+	}
+	if db.DNATests != nil {
+		tests := db.DNATests.DNATest
+		for i := range tests {
+			tests[i].refs(owned(targetDNATest, tests[i].Handle))
+		}
+	}
+	if db.DNAMatches != nil {
+		matches := db.DNAMatches.DNAMatch
+		for i := range matches {
+			matches[i].refs(owned(targetDNAMatch, matches[i].Handle))
+		}
+	}
+}
+
+// walkObjects calls fn with each of the primary objects in db, including DNA
+// tests and matches.
+func walkObjects(db *Database, fn func(primaryObject)) {
+	walkCommonObjects(db, fn)
+	if db.DNATests != nil {
+		tests := db.DNATests.DNATest
+		for i := range tests {
+			fn(primaryObject{targetDNATest, tests[i].Handle, &tests[i].ID, &tests[i].Change, &tests[i]})
+		}
+	}
+	if db.DNAMatches != nil {
+		matches := db.DNAMatches.DNAMatch
+		for i := range matches {
+			fn(primaryObject{targetDNAMatch, matches[i].Handle, &matches[i].ID, &matches[i].Change, &matches[i]})
+		}
+	}
+}
+
+// removeObjects removes the primary objects in db for which remove returns
+// true, including DNA tests and matches.
+func removeObjects(db *Database, remove func(objType, handle string) bool) {
+	removeCommonObjects(db, remove)
+	if db.DNATests != nil {
+		db.DNATests.DNATest = slices.DeleteFunc(db.DNATests.DNATest, func(o DNATest) bool { return remove(targetDNATest, o.Handle) })
+	}
+	if db.DNAMatches != nil {
+		db.DNAMatches.DNAMatch = slices.DeleteFunc(db.DNAMatches.DNAMatch, func(o DNAMatch) bool { return remove(targetDNAMatch, o.Handle) })
+	}
+}
+
+// addSchemaObject appends v to db if it is a DNA test or match.
+func addSchemaObject(db *Database, v any) {
+	switch o := v.(type) {
+	case *DNATest:
+		if db.DNATests == nil {
+			db.DNATests = &DNATests{}
+		}
+		db.DNATests.DNATest = append(db.DNATests.DNATest, *o)
+	case *DNAMatch:
+		if db.DNAMatches == nil {
+			db.DNAMatches = &DNAMatches{}
+		}
+		db.DNAMatches.DNAMatch = append(db.DNAMatches.DNAMatch, *o)
+	}
+}
+
+func (t *DNATest) refs(visit refVisitor) {
+	if t.Person != nil {
+		visit(targetPerson, &t.Person.Hlink)
+	}
+	attributeRefs(t.Attribute, visit)
+	objRefs(t.Objref, visit)
+	noteRefs(t.Noteref, visit)
+	citationRefs(t.Citationref, visit)
+	tagRefs(t.Tagref, visit)
+}
+
+func (m *DNAMatch) refs(visit refVisitor) {
+	if m.SubjectTest != nil {
+		visit(targetDNATest, &m.SubjectTest.Hlink)
+	}
+	if m.MatchTest != nil {
+		visit(targetDNATest, &m.MatchTest.Hlink)
+	}
+	for i := range m.SharedAncestor {
+		a := &m.SharedAncestor[i]
+		if a.Person != nil {
+			visit(targetPerson, &a.Person.Hlink)
+		}
+		noteRefs(a.Noteref, visit)
+		citationRefs(a.Citationref, visit)
+	}
+	attributeRefs(m.Attribute, visit)
+	objRefs(m.Objref, visit)
+	noteRefs(m.Noteref, visit)
+	citationRefs(m.Citationref, visit)
+	tagRefs(m.Tagref, visit)
+}
+
+// dependsOn reports whether objects of type ownerType cannot exist without
+// the objects of type target they refer to, as DNA matches depend on their
+// tests.
+func dependsOn(ownerType, target string) bool {
+	return ownerType == targetCitation && target == targetSource ||
+		ownerType == targetDNAMatch && target == targetDNATest
+}